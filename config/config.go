@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds application configuration
@@ -10,6 +12,16 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Storage  StorageConfig
+	Payment  PaymentConfig
+	Invoice  InvoiceConfig
+	Currency CurrencyConfig
+	Email    EmailConfig
+	Events   EventsConfig
+	Webhooks WebhooksConfig
+	Reminder ReminderConfig
+	Calendar CalendarConfig
+	Reviews  ReviewsConfig
+	Photos   PhotosConfig
 }
 
 // ServerConfig holds server configuration
@@ -37,6 +49,85 @@ type StorageConfig struct {
 	Region          string
 	UseSSL          bool
 	PublicURL       string
+	LocalPath       string // каталог с файлами для STORAGE_TYPE=local
+	LocalURL        string // адрес, по которому сервер отдает LocalPath
+	LocalPrivate    bool   // отдавать файлы только по подписанным ссылкам
+	SigningKey      string // ключ HMAC для подписанных ссылок local storage
+}
+
+// PaymentConfig holds payment provider configuration
+type PaymentConfig struct {
+	Provider      string // "fake"
+	WebhookSecret string
+}
+
+// InvoiceConfig holds invoice numbering, tax and seller details
+type InvoiceConfig struct {
+	NumberPrefix  string
+	TaxRate       float64 // percent, included in booking prices
+	TaxName       string
+	SellerName    string
+	SellerAddress string
+	SellerTaxID   string
+}
+
+// EmailConfig holds email transport and outbox settings
+type EmailConfig struct {
+	Transport        string // "log" or "smtp"
+	From             string
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	OutboxDir        string // каталог для .eml файлов при Transport = "log"
+	MaxAttempts      int
+	PollInterval     int // seconds
+	PasswordResetURL string
+	PasswordResetTTL int // minutes
+}
+
+// EventsConfig holds domain event outbox dispatcher settings
+type EventsConfig struct {
+	PollInterval int // seconds
+	MaxAttempts  int
+}
+
+// WebhooksConfig holds outgoing webhook delivery settings
+type WebhooksConfig struct {
+	Timeout      int // seconds
+	MaxAttempts  int
+	PollInterval int // seconds
+}
+
+// ReminderConfig holds booking reminder scheduler settings
+type ReminderConfig struct {
+	Offsets      []time.Duration // до начала бронирования
+	PollInterval int             // seconds
+}
+
+// CalendarConfig holds external calendar import settings
+type CalendarConfig struct {
+	SyncInterval int // minutes
+	FetchTimeout int // seconds
+}
+
+// ReviewsConfig holds review settings
+type ReviewsConfig struct {
+	AllowUnverified bool     // отзывы без завершенного бронирования
+	BlockedWords    []string // отзыв с этими словами уходит на модерацию
+	BlockedPattern  string   // регулярное выражение с тем же действием
+	MaxPhotos       int      // фотографий на отзыв
+}
+
+// PhotosConfig holds resource photo gallery settings
+type PhotosConfig struct {
+	MaxPerResource int // фотографий в галерее ресурса
+}
+
+// CurrencyConfig holds the base reporting currency and exchange rates into it
+type CurrencyConfig struct {
+	Base  string
+	Rates map[string]float64 // units of Base per one unit of the currency
 }
 
 // Load loads configuration from environment or defaults
@@ -62,6 +153,65 @@ func Load() *Config {
 			Region:          getEnv("STORAGE_REGION", "us-east-1"),
 			UseSSL:          getEnvAsBool("STORAGE_USE_SSL", false),
 			PublicURL:       getEnv("STORAGE_PUBLIC_URL", "http://localhost:9000"),
+			LocalPath:       getEnv("STORAGE_LOCAL_PATH", "/uploads"),
+			LocalURL:        getEnv("STORAGE_LOCAL_URL", "http://localhost:8080/uploads"),
+			LocalPrivate:    getEnvAsBool("STORAGE_LOCAL_PRIVATE", false),
+			SigningKey:      getEnv("STORAGE_SIGNING_KEY", "storage_dev_signing_key"),
+		},
+		Payment: PaymentConfig{
+			Provider:      getEnv("PAYMENT_PROVIDER", "fake"),
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", "whsec_dev_secret"),
+		},
+		Invoice: InvoiceConfig{
+			NumberPrefix:  getEnv("INVOICE_NUMBER_PREFIX", "INV"),
+			TaxRate:       getEnvAsFloat("INVOICE_TAX_RATE", 12),
+			TaxName:       getEnv("INVOICE_TAX_NAME", "VAT"),
+			SellerName:    getEnv("INVOICE_SELLER_NAME", "SmartBooking"),
+			SellerAddress: getEnv("INVOICE_SELLER_ADDRESS", ""),
+			SellerTaxID:   getEnv("INVOICE_SELLER_TAX_ID", ""),
+		},
+		Currency: CurrencyConfig{
+			Base:  getEnv("CURRENCY_BASE", "KZT"),
+			Rates: getEnvAsRates("CURRENCY_RATES", "USD:470.5,EUR:510,RUB:5.2"),
+		},
+		Email: EmailConfig{
+			Transport:        getEnv("EMAIL_TRANSPORT", "log"),
+			From:             getEnv("EMAIL_FROM", "SmartBooking <no-reply@smartbooking.local>"),
+			SMTPHost:         getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:         getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+			OutboxDir:        getEnv("EMAIL_OUTBOX_DIR", ""),
+			MaxAttempts:      getEnvAsInt("EMAIL_MAX_ATTEMPTS", 8),
+			PollInterval:     getEnvAsInt("EMAIL_POLL_INTERVAL", 10),
+			PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			PasswordResetTTL: getEnvAsInt("PASSWORD_RESET_TTL_MINUTES", 60),
+		},
+		Events: EventsConfig{
+			PollInterval: getEnvAsInt("EVENTS_POLL_INTERVAL", 2),
+			MaxAttempts:  getEnvAsInt("EVENTS_MAX_ATTEMPTS", 10),
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:      getEnvAsInt("WEBHOOK_TIMEOUT", 10),
+			MaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			PollInterval: getEnvAsInt("WEBHOOK_POLL_INTERVAL", 5),
+		},
+		Reminder: ReminderConfig{
+			Offsets:      getEnvAsDurations("REMINDER_OFFSETS", "24h,2h"),
+			PollInterval: getEnvAsInt("REMINDER_POLL_INTERVAL", 60),
+		},
+		Calendar: CalendarConfig{
+			SyncInterval: getEnvAsInt("CALENDAR_SYNC_INTERVAL", 30),
+			FetchTimeout: getEnvAsInt("CALENDAR_FETCH_TIMEOUT", 15),
+		},
+		Reviews: ReviewsConfig{
+			AllowUnverified: getEnvAsBool("REVIEWS_ALLOW_UNVERIFIED", false),
+			BlockedWords:    getEnvAsList("REVIEWS_BLOCKED_WORDS", ""),
+			BlockedPattern:  getEnv("REVIEWS_BLOCKED_PATTERN", ""),
+			MaxPhotos:       getEnvAsInt("REVIEWS_MAX_PHOTOS", 5),
+		},
+		Photos: PhotosConfig{
+			MaxPerResource: getEnvAsInt("PHOTOS_MAX_PER_RESOURCE", 30),
 		},
 	}
 }
@@ -88,6 +238,19 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvAsFloat получает дробное значение переменной окружения
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // getEnvAsBool получает булевое значение переменной окружения
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
@@ -100,3 +263,46 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return value
 }
+
+// getEnvAsList разбирает список значений через запятую, пустые элементы пропускаются
+func getEnvAsList(key string, defaultValue string) []string {
+	var values []string
+	for _, part := range strings.Split(getEnv(key, defaultValue), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// getEnvAsRates разбирает таблицу курсов вида "USD:470.5,EUR:510".
+// Некорректные элементы пропускаются.
+func getEnvAsRates(key string, defaultValue string) map[string]float64 {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(getEnv(key, defaultValue), ",") {
+		code, rateStr, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || rate <= 0 {
+			continue
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates
+}
+
+// getEnvAsDurations разбирает список длительностей вида "24h,2h,30m".
+// Некорректные значения и значения меньше минуты пропускаются.
+func getEnvAsDurations(key string, defaultValue string) []time.Duration {
+	var durations []time.Duration
+	for _, part := range strings.Split(getEnv(key, defaultValue), ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d < time.Minute {
+			continue
+		}
+		durations = append(durations, d)
+	}
+	return durations
+}
@@ -0,0 +1,108 @@
+// Package audit записывает изменения сущностей в audit_logs вместе с тем,
+// кто и откуда их выполнил
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"smartbooking/internal/models"
+)
+
+// Действия, как они хранятся в audit_logs.action
+const (
+	ActionCreate = "CREATE"
+	ActionUpdate = "UPDATE"
+	ActionDelete = "DELETE"
+)
+
+// Типы сущностей, как они хранятся в audit_logs.entity_type
+const (
+	EntityUser     = "user"
+	EntityResource = "resource"
+	EntityBooking  = "booking"
+	EntityReview   = "review"
+	EntityCategory = "category"
+	EntityPhoto    = "photo"
+)
+
+type actorKey struct{}
+
+// Actor тот, кто выполняет запрос. UserID == nil для анонимных и системных действий.
+type Actor struct {
+	UserID    *int64
+	IPAddress string
+	UserAgent string
+}
+
+// WithActor кладет исполнителя запроса в контекст
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom возвращает исполнителя из контекста; пустой Actor, если его нет
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// Store хранилище журнала; реализуется repository.AuditRepository
+type Store interface {
+	Append(ctx context.Context, entry *models.AuditLog) error
+}
+
+// Recorder записывает изменение сущности. before и after сериализуются в JSON,
+// nil означает отсутствие снимка (before при создании, after при удалении).
+// Вызванный внутри Transactor.WithinTx, Record попадает в ту же транзакцию.
+type Recorder interface {
+	Record(ctx context.Context, action, entityType string, entityID int64, before, after any) error
+}
+
+type recorder struct {
+	store Store
+}
+
+// NewRecorder creates a Recorder that appends entries to the store
+func NewRecorder(store Store) Recorder {
+	return &recorder{store: store}
+}
+
+func (r *recorder) Record(ctx context.Context, action, entityType string, entityID int64, before, after any) error {
+	oldValue, err := snapshot(before)
+	if err != nil {
+		return fmt.Errorf("audit %s %s: %w", action, entityType, err)
+	}
+	newValue, err := snapshot(after)
+	if err != nil {
+		return fmt.Errorf("audit %s %s: %w", action, entityType, err)
+	}
+
+	actor := ActorFrom(ctx)
+	return r.store.Append(ctx, &models.AuditLog{
+		UserID:     actor.UserID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   &entityID,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	})
+}
+
+// snapshot сериализует состояние сущности. Отсутствующее состояние, в том
+// числе nil-указатель вроде (*models.Booking)(nil), пишется как NULL, а не "null".
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+	return json.Marshal(v)
+}
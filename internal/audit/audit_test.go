@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"smartbooking/internal/models"
+)
+
+type memoryStore struct {
+	entries []*models.AuditLog
+}
+
+func (s *memoryStore) Append(ctx context.Context, entry *models.AuditLog) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestRecordMissingSnapshotIsNull(t *testing.T) {
+	booking := &models.Booking{ID: 7, Status: models.StatusPending}
+	var missing *models.Booking
+
+	tests := []struct {
+		name          string
+		action        string
+		before, after any
+		wantOld       bool
+		wantNew       bool
+	}{
+		{"create with untyped nil", ActionCreate, nil, booking, false, true},
+		{"create with typed nil", ActionCreate, missing, booking, false, true},
+		{"delete with typed nil", ActionDelete, booking, missing, true, false},
+		{"update", ActionUpdate, booking, booking, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{}
+			if err := NewRecorder(store).Record(context.Background(), tt.action, EntityBooking, 7, tt.before, tt.after); err != nil {
+				t.Fatalf("Record: %v", err)
+			}
+
+			entry := store.entries[0]
+			if got := entry.OldValue != nil; got != tt.wantOld {
+				t.Errorf("old value = %q, want present=%v", entry.OldValue, tt.wantOld)
+			}
+			if got := entry.NewValue != nil; got != tt.wantNew {
+				t.Errorf("new value = %q, want present=%v", entry.NewValue, tt.wantNew)
+			}
+		})
+	}
+}
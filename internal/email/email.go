@@ -0,0 +1,83 @@
+// Package email renders localized emails and delivers them over SMTP or to a log/file stand-in.
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// Message is a rendered email ready to be sent
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailSender delivers a single message. Implementations must be safe for concurrent use.
+type EmailSender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Build собирает RFC 5322 письмо: текстовая и HTML-версии в multipart/alternative,
+// тело в quoted-printable, тема в кодировке UTF-8.
+func Build(msg *Message, date time.Time) ([]byte, error) {
+	if msg.From == "" || msg.To == "" {
+		return nil, ErrInvalidMessage
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary())
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"smartbooking/internal/logger"
+)
+
+// LogSender заменяет SMTP при локальной разработке: пишет письмо в лог
+// и, если задан каталог, сохраняет его целиком в .eml файл.
+type LogSender struct {
+	dir string
+	seq atomic.Int64
+}
+
+// NewLogSender creates a sender that logs messages and stores them in dir (optional)
+func NewLogSender(dir string) *LogSender {
+	return &LogSender{dir: dir}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := Build(msg, now)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Email to %s: %s", msg.To, msg.Subject)
+
+	if s.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%03d.eml", now.Format("20060102-150405"), s.seq.Add(1)%1000)
+	return os.WriteFile(filepath.Join(s.dir, name), data, 0o644)
+}
@@ -0,0 +1,128 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Имена шаблонов писем
+const (
+	TemplateBookingConfirmation = "booking_confirmation"
+	TemplateBookingCancellation = "booking_cancellation"
+	TemplateBookingReminder     = "booking_reminder"
+	TemplatePasswordReset       = "password_reset"
+)
+
+// Поддерживаемые языки писем
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+
+	DefaultLocale = LocaleRU
+)
+
+var templateNames = []string{
+	TemplateBookingConfirmation,
+	TemplateBookingCancellation,
+	TemplateBookingReminder,
+	TemplatePasswordReset,
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+// BookingData данные для писем о бронировании. Даты и суммы уже отформатированы под язык.
+type BookingData struct {
+	Name         string
+	BookingID    int64
+	ResourceName string
+	Start        string
+	End          string
+	Total        string
+	Confirmed    bool
+}
+
+// PasswordResetData данные для письма о сбросе пароля
+type PasswordResetData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+// Renderer рендерит письма из встроенных шаблонов. Для каждого шаблона и языка есть
+// <name>.txt с блоками "subject" и "text" и <name>.html с HTML-версией.
+type Renderer struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// NewRenderer parses all embedded templates
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	for _, locale := range []string{LocaleRU, LocaleEN} {
+		for _, name := range templateNames {
+			key := locale + "/" + name
+
+			t, err := texttemplate.ParseFS(templateFS, "templates/"+key+".txt")
+			if err != nil {
+				return nil, fmt.Errorf("parse %s.txt: %w", key, err)
+			}
+			if t.Lookup("subject") == nil || t.Lookup("text") == nil {
+				return nil, fmt.Errorf("template %s.txt must define subject and text", key)
+			}
+			r.text[key] = t
+
+			h, err := htmltemplate.ParseFS(templateFS, "templates/"+key+".html")
+			if err != nil {
+				return nil, fmt.Errorf("parse %s.html: %w", key, err)
+			}
+			r.html[key] = h
+		}
+	}
+
+	return r, nil
+}
+
+// NormalizeLocale returns a supported locale, falling back to DefaultLocale
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == LocaleEN || strings.HasPrefix(locale, LocaleEN+"-") {
+		return LocaleEN
+	}
+	return DefaultLocale
+}
+
+// Render renders the subject, text and HTML bodies of a template in the given locale
+func (r *Renderer) Render(locale, name string, data any) (*Message, error) {
+	key := NormalizeLocale(locale) + "/" + name
+
+	t, ok := r.text[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := t.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+	if err := r.html[key].Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
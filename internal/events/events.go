@@ -0,0 +1,87 @@
+// Package events defines domain events, the transactional outbox publisher
+// and the dispatcher that delivers stored events to in-process subscribers.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"smartbooking/internal/models"
+)
+
+// Type identifies a domain event
+type Type string
+
+const (
+	BookingCreated   Type = "booking.created"
+	BookingConfirmed Type = "booking.confirmed"
+	BookingCancelled Type = "booking.cancelled"
+	BookingReminder  Type = "booking.reminder"
+
+	ReviewPosted  Type = "review.posted"
+	ReviewUpdated Type = "review.updated"
+	ReviewDeleted Type = "review.deleted"
+	ReviewReplied Type = "review.replied"
+
+	ResourceCreated Type = "resource.created"
+	ResourceUpdated Type = "resource.updated"
+	ResourceDeleted Type = "resource.deleted"
+)
+
+// Event событие предметной области. Заполняются только поля,
+// относящиеся к типу события. ID — номер записи в outbox, по нему
+// подписчики могут отсеивать повторные доставки.
+type Event struct {
+	ID         int64            `json:"id,omitempty"`
+	Type       Type             `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Booking    *models.Booking  `json:"booking,omitempty"`
+	Review     *models.Review   `json:"review,omitempty"`
+	Resource   *models.Resource `json:"resource,omitempty"`
+}
+
+// Handler обрабатывает событие подписчика. Доставка at-least-once,
+// поэтому обработчик должен переносить повторный вызов.
+type Handler func(ctx context.Context, event Event) error
+
+// Publisher публикует события; сервисы зависят только от этого интерфейса
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// Bus хранит подписчиков на события внутри процесса
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]subscription
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[Type][]subscription),
+	}
+}
+
+// Subscribe регистрирует обработчик для перечисленных типов событий.
+// name должно быть уникальным: по нему запоминается, кто уже обработал событие.
+func (b *Bus) Subscribe(name string, handler Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range types {
+		b.handlers[t] = append(b.handlers[t], subscription{name: name, handler: handler})
+	}
+}
+
+func (b *Bus) subscribers(t Type) []subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.handlers[t]
+}
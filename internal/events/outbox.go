@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"smartbooking/internal/logger"
+	"smartbooking/internal/models"
+	"smartbooking/internal/retry"
+)
+
+const (
+	dispatchBatchSize = 50
+	dispatchLease     = 2 * time.Minute
+)
+
+// dispatchBackoff задержка повтора события: 10с, 20с, 40с, ... но не больше часа
+var dispatchBackoff = retry.Backoff{Base: 10 * time.Second, Max: time.Hour}
+
+// Store хранилище outbox; реализуется repository.EventOutboxRepository
+type Store interface {
+	Append(ctx context.Context, event *models.OutboxEvent) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, completedHandlers []string, lastError string, nextAttemptAt *time.Time) error
+}
+
+// OutboxPublisher записывает события в outbox. Вызванный внутри
+// Transactor.WithinTx, Publish попадает в ту же транзакцию, что и изменение,
+// поэтому событие не теряется и не появляется без изменения.
+type OutboxPublisher struct {
+	store Store
+}
+
+// NewOutboxPublisher creates a publisher that appends events to the outbox
+func NewOutboxPublisher(store Store) *OutboxPublisher {
+	return &OutboxPublisher{store: store}
+}
+
+func (p *OutboxPublisher) Publish(ctx context.Context, event Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event %s: %w", event.Type, err)
+	}
+
+	return p.store.Append(ctx, &models.OutboxEvent{
+		Type:    string(event.Type),
+		Payload: payload,
+	})
+}
+
+// Dispatcher доставляет события из outbox подписчикам шины. Если подписчик
+// вернул ошибку, событие повторяется позже с экспоненциальной задержкой,
+// причем успешно отработавшие подписчики повторно не вызываются.
+type Dispatcher struct {
+	store       Store
+	bus         *Bus
+	maxAttempts int
+}
+
+// NewDispatcher creates a dispatcher. После maxAttempts неудачных попыток событие помечается failed.
+func NewDispatcher(store Store, bus *Bus, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		bus:         bus,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// DispatchPending доставляет очередную пачку событий и возвращает число полностью обработанных
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	batch, err := d.store.ClaimDue(ctx, dispatchBatchSize, dispatchLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// completed — подписчики текущего события, успешно отработавшие до ошибки
+	var completed []string
+	queue := &retry.Queue[*models.OutboxEvent]{
+		Backoff:     dispatchBackoff,
+		MaxAttempts: d.maxAttempts,
+		Attempts:    func(stored *models.OutboxEvent) int { return stored.Attempts },
+		Send: func(ctx context.Context, stored *models.OutboxEvent) error {
+			var err error
+			completed, err = d.dispatch(ctx, stored)
+			return err
+		},
+		Done: func(ctx context.Context, stored *models.OutboxEvent) error {
+			return d.store.MarkDispatched(ctx, stored.ID)
+		},
+		Failed: func(ctx context.Context, stored *models.OutboxEvent, attempt int, err error, next *time.Time) error {
+			logger.Error("Event %d (%s) failed (attempt %d/%d): %v", stored.ID, stored.Type, attempt, d.maxAttempts, err)
+			return d.store.MarkFailed(ctx, stored.ID, completed, err.Error(), next)
+		},
+	}
+
+	return queue.Process(ctx, batch)
+}
+
+// dispatch вызывает подписчиков, еще не обработавших событие, и возвращает
+// обновленный список успешно отработавших
+func (d *Dispatcher) dispatch(ctx context.Context, stored *models.OutboxEvent) ([]string, error) {
+	completed := slices.Clone(stored.CompletedHandlers)
+
+	var event Event
+	if err := json.Unmarshal(stored.Payload, &event); err != nil {
+		return completed, fmt.Errorf("decode event: %w", err)
+	}
+	event.ID = stored.ID
+
+	var errs []error
+	for _, sub := range d.bus.subscribers(event.Type) {
+		if slices.Contains(completed, sub.name) {
+			continue
+		}
+		if err := sub.handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+			continue
+		}
+		completed = append(completed, sub.name)
+	}
+
+	return completed, errors.Join(errs...)
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"smartbooking/internal/models"
 	"smartbooking/internal/service"
 )
 
@@ -130,3 +133,81 @@ func (h *AdminHandler) GetBookingsByDay(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
+
+// GetCouponUsage godoc
+// @Summary Get coupon usage
+// @Description Retrieves redemption counts and discount totals per promo code
+// @Tags admin
+// @Produce json
+// @Success 200 {array} repository.CouponUsage
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/coupons/usage [get]
+func (h *AdminHandler) GetCouponUsage(w http.ResponseWriter, r *http.Request) {
+	data, err := h.adminService.GetCouponUsage(r.Context())
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch coupon usage"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// GetAuditLogs godoc
+// @Summary Query audit log
+// @Description Retrieves create, update and delete records with before/after snapshots, newest first. All filters are optional.
+// @Tags admin
+// @Produce json
+// @Param entity_type query string false "Entity type (user, resource, booking, review, category, photo)"
+// @Param entity_id query int false "Entity ID"
+// @Param user_id query int false "Acting user ID"
+// @Param action query string false "Action (CREATE, UPDATE, DELETE)"
+// @Param from query string false "Start of time range (RFC3339), inclusive"
+// @Param to query string false "End of time range (RFC3339), exclusive"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.AuditLog
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/audit-logs [get]
+func (h *AdminHandler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditLogFilter{
+		EntityType: query.Get("entity_type"),
+		Action:     strings.ToUpper(query.Get("action")),
+	}
+
+	for param, target := range map[string]**int64{"entity_id": &filter.EntityID, "user_id": &filter.UserID} {
+		if value := query.Get(param); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				http.Error(w, `{"error": "Invalid `+param+`"}`, http.StatusBadRequest)
+				return
+			}
+			*target = &id
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, `{"error": "Invalid `+param+`, expected RFC3339 time"}`, http.StatusBadRequest)
+				return
+			}
+			*target = &t
+		}
+	}
+
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+	filter.Offset, _ = strconv.Atoi(query.Get("offset"))
+
+	logs, err := h.adminService.ListAuditLogs(r.Context(), filter)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch audit logs"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logs)
+}
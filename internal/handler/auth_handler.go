@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"smartbooking/internal/logger"
+	"smartbooking/internal/repository"
 	"smartbooking/internal/service"
 )
 
@@ -22,6 +24,7 @@ type RegisterRequest struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Locale   string `json:"locale,omitempty"` // ru (default) or en
 }
 
 type LoginRequest struct {
@@ -29,6 +32,15 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 // Register handles user registration
 // @Summary Register a new user
 // @Description Create a new user account with name, email, and password
@@ -50,7 +62,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	logger.Info("Register: Attempting to register user with email: %s", req.Email)
 
-	user, err := h.authService.Register(r.Context(), req.Name, req.Email, req.Password)
+	user, err := h.authService.Register(r.Context(), req.Name, req.Email, req.Password, req.Locale)
 	if err != nil {
 		logger.LogAuth("register", req.Email, false)
 		logger.Error("Register: Failed to register user %s - %v", req.Email, err)
@@ -101,3 +113,60 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
+
+// RequestPasswordReset handles password reset requests
+// @Summary Request password reset
+// @Description Email a one-time password reset link. Always succeeds so that registered emails cannot be discovered.
+// @Tags auth
+// @Accept json
+// @Param request body PasswordResetRequest true "Account email"
+// @Success 202
+// @Failure 400 {string} string "Invalid request body"
+// @Failure 500 {string} string "Internal server error"
+// @Router /auth/password-reset [post]
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		logger.Error("RequestPasswordReset: failed for %s - %v", req.Email, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword handles setting a new password by reset token
+// @Summary Reset password
+// @Description Set a new password using the token from the password reset email
+// @Tags auth
+// @Accept json
+// @Param request body PasswordResetConfirmRequest true "Reset token and new password"
+// @Success 204
+// @Failure 400 {string} string "Invalid or expired token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /auth/password-reset/confirm [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrResetTokenInvalid), errors.Is(err, service.ErrWeakPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Error("ResetPassword: failed - %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
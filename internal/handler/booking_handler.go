@@ -24,17 +24,18 @@ type CreateBookingRequest struct {
 	ResourceID int64  `json:"resource_id"`
 	StartTime  string `json:"start_time"`
 	EndTime    string `json:"end_time"`
+	PromoCode  string `json:"promo_code,omitempty"`
 }
 
 // Create handles POST /bookings
 // @Summary Create a new booking
-// @Description Create a new booking for a resource with conflict checking
+// @Description Create a new booking for a resource with conflict checking and optional promo code. The booking must fit the resource's opening hours; the price follows its pricing rules. Both are evaluated in the resource's time zone. Bookings of resources that require prepayment are created pending and confirmed by the payment webhook; all other bookings are confirmed immediately.
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param request body CreateBookingRequest true "Booking details (use RFC3339 format for times: 2024-01-15T10:00:00Z)"
 // @Success 201 {object} models.Booking
-// @Failure 400 {string} string "Invalid request or booking conflict"
+// @Failure 400 {string} string "Invalid request, booking conflict or outside opening hours"
 // @Router /bookings [post]
 func (h *BookingHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req CreateBookingRequest
@@ -55,7 +56,7 @@ func (h *BookingHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	booking, err := h.bookingService.Create(r.Context(), req.UserID, req.ResourceID, startTime, endTime)
+	booking, err := h.bookingService.Create(r.Context(), req.UserID, req.ResourceID, startTime, endTime, req.PromoCode)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -66,6 +67,45 @@ func (h *BookingHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(booking)
 }
 
+// Quote handles POST /bookings/quote
+// @Summary Quote a booking price
+// @Description Calculate the booking price for a time range and check an optional promo code without creating a booking. The price is broken down by the resource's pricing rules in its time zone.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param request body CreateBookingRequest true "Booking details (use RFC3339 format for times: 2024-01-15T10:00:00Z)"
+// @Success 200 {object} models.CouponQuote
+// @Failure 400 {string} string "Invalid request or promo code"
+// @Router /bookings/quote [post]
+func (h *BookingHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var req CreateBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		http.Error(w, "Invalid start_time format", http.StatusBadRequest)
+		return
+	}
+
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		http.Error(w, "Invalid end_time format", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.bookingService.Quote(r.Context(), req.UserID, req.ResourceID, startTime, endTime, req.PromoCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
 // GetByID handles GET /bookings/{id}
 // @Summary Get booking by ID
 // @Description Get details of a specific booking
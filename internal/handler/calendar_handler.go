@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"smartbooking/internal/ical"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+// CalendarHandler handles HTTP requests for iCalendar feeds
+type CalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+// NewCalendarHandler creates a new CalendarHandler
+func NewCalendarHandler(calendarService service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// CalendarFeedResponse contains the secret feed URL to paste into a calendar client
+type CalendarFeedResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// CalendarFeedRequest identifies the caller: the user for a user feed, the owner for a resource feed
+type CalendarFeedRequest struct {
+	UserID  int64 `json:"user_id"`
+	OwnerID int64 `json:"owner_id"`
+}
+
+// CreateUserFeed godoc
+// @Summary Create user calendar feed
+// @Description Issues a secret feed URL with the user's bookings for Google/Apple Calendar. Only the user can issue it; user_id must match. A previously issued URL stops working.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body CalendarFeedRequest true "Caller (user_id)"
+// @Success 201 {object} CalendarFeedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/{id}/calendar-feed [post]
+func (h *CalendarHandler) CreateUserFeed(w http.ResponseWriter, r *http.Request) {
+	h.createFeed(w, r, repository.CalendarFeedUser, "/api/users/%d/bookings.ics")
+}
+
+// CreateResourceFeed godoc
+// @Summary Create resource calendar feed
+// @Description Issues a secret feed URL with all bookings of a resource for its owner. Only the owner can issue it; owner_id must match. A previously issued URL stops working.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param request body CalendarFeedRequest true "Caller (owner_id)"
+// @Success 201 {object} CalendarFeedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/calendar-feed [post]
+func (h *CalendarHandler) CreateResourceFeed(w http.ResponseWriter, r *http.Request) {
+	h.createFeed(w, r, repository.CalendarFeedResource, "/api/resources/%d/calendar.ics")
+}
+
+func (h *CalendarHandler) createFeed(w http.ResponseWriter, r *http.Request, scope, pathFormat string) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req CalendarFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	callerID := req.UserID
+	if scope == repository.CalendarFeedResource {
+		callerID = req.OwnerID
+	}
+	if callerID <= 0 {
+		http.Error(w, `{"error": "Caller ID is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.calendarService.IssueFeedToken(r.Context(), scope, id, callerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) || errors.Is(err, repository.ErrResourceNotFound) {
+			http.Error(w, `{"error": "Not found"}`, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrFeedAccessDenied) {
+			http.Error(w, `{"error": "Forbidden"}`, http.StatusForbidden)
+			return
+		}
+		logger.Error("CreateCalendarFeed: %s %d - %v", scope, id, err)
+		http.Error(w, `{"error": "Failed to create calendar feed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	feedURL := url.URL{
+		Scheme:   requestScheme(r),
+		Host:     r.Host,
+		Path:     fmt.Sprintf(pathFormat, id),
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CalendarFeedResponse{Token: token, URL: feedURL.String()})
+}
+
+// UserFeed godoc
+// @Summary User bookings calendar
+// @Description iCalendar (RFC 5545) feed of the user's bookings. Cancelled bookings are kept with STATUS:CANCELLED.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "User ID"
+// @Param token query string true "Feed token"
+// @Success 200 {string} string "iCalendar data"
+// @Failure 400 {string} string "Invalid user ID"
+// @Failure 403 {string} string "Invalid feed token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/users/{id}/bookings.ics [get]
+func (h *CalendarHandler) UserFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	cal, err := h.calendarService.UserFeed(r.Context(), userID, r.URL.Query().Get("token"))
+	writeCalendar(w, cal, err, fmt.Sprintf("bookings-%d.ics", userID))
+}
+
+// ResourceFeed godoc
+// @Summary Resource bookings calendar
+// @Description iCalendar (RFC 5545) feed of all bookings of a resource. Cancelled bookings are kept with STATUS:CANCELLED.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Resource ID"
+// @Param token query string true "Feed token"
+// @Success 200 {string} string "iCalendar data"
+// @Failure 400 {string} string "Invalid resource ID"
+// @Failure 403 {string} string "Invalid feed token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/resources/{id}/calendar.ics [get]
+func (h *CalendarHandler) ResourceFeed(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid resource ID", http.StatusBadRequest)
+		return
+	}
+
+	cal, err := h.calendarService.ResourceFeed(r.Context(), resourceID, r.URL.Query().Get("token"))
+	writeCalendar(w, cal, err, fmt.Sprintf("resource-%d.ics", resourceID))
+}
+
+func writeCalendar(w http.ResponseWriter, cal *ical.Calendar, err error, fileName string) {
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFeedToken) {
+			http.Error(w, "Invalid feed token", http.StatusForbidden)
+			return
+		}
+		logger.Error("Calendar feed %s failed - %v", fileName, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, fileName))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if err := cal.Write(w); err != nil {
+		logger.Error("Calendar feed %s write failed - %v", fileName, err)
+	}
+}
+
+// requestScheme определяет схему исходного запроса с учетом обратного прокси
+func requestScheme(r *http.Request) string {
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"smartbooking/internal/ical"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+// CalendarImportHandler handles HTTP requests for external calendars that block resource availability
+type CalendarImportHandler struct {
+	importService service.CalendarImportService
+}
+
+// NewCalendarImportHandler creates a new CalendarImportHandler
+func NewCalendarImportHandler(importService service.CalendarImportService) *CalendarImportHandler {
+	return &CalendarImportHandler{
+		importService: importService,
+	}
+}
+
+// AddExternalCalendarRequest represents the request body for subscribing to an external calendar
+type AddExternalCalendarRequest struct {
+	OwnerID int64  `json:"owner_id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+}
+
+// ExternalCalendarResponse contains the calendar and the result of its first import
+type ExternalCalendarResponse struct {
+	Calendar *models.ExternalCalendar   `json:"calendar"`
+	Sync     *models.CalendarSyncResult `json:"sync"`
+}
+
+// AddURL godoc
+// @Summary Subscribe to external calendar
+// @Description Registers an iCal URL (Airbnb, Booking.com, Google Calendar, webcal://) for a resource. Its events block bookings; the calendar is re-fetched periodically. Only the resource owner can add calendars.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param request body AddExternalCalendarRequest true "Calendar URL"
+// @Success 201 {object} ExternalCalendarResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/external-calendars [post]
+func (h *CalendarImportHandler) AddURL(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid resource ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req AddExternalCalendarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if req.OwnerID <= 0 {
+		http.Error(w, `{"error": "Owner ID is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cal, result, err := h.importService.AddURL(r.Context(), resourceID, req.OwnerID, req.Name, req.URL)
+	if err != nil {
+		writeCalendarImportError(w, "AddExternalCalendar", resourceID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ExternalCalendarResponse{Calendar: cal, Sync: result})
+}
+
+// Upload godoc
+// @Summary Upload calendar file
+// @Description Imports an .ics file for a resource; its events block bookings. Upload a new file to the calendar's sync endpoint to update it. Only the resource owner can upload calendars.
+// @Tags calendar
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param file formData file true "iCalendar file"
+// @Param owner_id formData int true "Resource owner ID"
+// @Param name formData string false "Calendar name"
+// @Success 201 {object} ExternalCalendarResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/external-calendars/upload [post]
+func (h *CalendarImportHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid resource ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	file, err := calendarFile(w, r)
+	if err != nil || file == nil {
+		http.Error(w, `{"error": "Calendar file is required"}`, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	ownerID, err := strconv.ParseInt(r.FormValue("owner_id"), 10, 64)
+	if err != nil || ownerID <= 0 {
+		http.Error(w, `{"error": "Owner ID is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cal, result, err := h.importService.Upload(r.Context(), resourceID, ownerID, r.FormValue("name"), file)
+	if err != nil {
+		writeCalendarImportError(w, "UploadExternalCalendar", resourceID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ExternalCalendarResponse{Calendar: cal, Sync: result})
+}
+
+// List godoc
+// @Summary List external calendars
+// @Description Retrieves external calendars of a resource with their last sync status
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Success 200 {array} models.ExternalCalendar
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/external-calendars [get]
+func (h *CalendarImportHandler) List(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid resource ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	calendars, err := h.importService.List(r.Context(), resourceID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch external calendars"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendars)
+}
+
+// Sync godoc
+// @Summary Sync external calendar
+// @Description Re-imports an external calendar now: added events become blocks, changed ones are updated and removed ones are released. Uploaded calendars require a new file.
+// @Tags calendar
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param calendar_id path int true "External calendar ID"
+// @Param file formData file false "iCalendar file (uploaded calendars only)"
+// @Success 200 {object} models.CalendarSyncResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/external-calendars/{calendar_id}/sync [post]
+func (h *CalendarImportHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	resourceID, calendarID, ok := parseExternalCalendarPath(w, r)
+	if !ok {
+		return
+	}
+
+	file, err := calendarFile(w, r)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid calendar file"}`, http.StatusBadRequest)
+		return
+	}
+	var body io.Reader
+	if file != nil {
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.importService.Sync(r.Context(), resourceID, calendarID, body)
+	if err != nil {
+		writeCalendarImportError(w, "SyncExternalCalendar", resourceID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Delete godoc
+// @Summary Delete external calendar
+// @Description Removes an external calendar and releases all blocks imported from it
+// @Tags calendar
+// @Param id path int true "Resource ID"
+// @Param calendar_id path int true "External calendar ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/external-calendars/{calendar_id} [delete]
+func (h *CalendarImportHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	resourceID, calendarID, ok := parseExternalCalendarPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.importService.Delete(r.Context(), resourceID, calendarID); err != nil {
+		if errors.Is(err, repository.ErrExternalCalendarNotFound) {
+			http.Error(w, `{"error": "External calendar not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "Failed to delete external calendar"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBlocks godoc
+// @Summary List resource blocks
+// @Description Retrieves periods when the resource is unavailable because of external calendars. Defaults to the next year.
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param from query string false "Start of range (RFC3339)"
+// @Param to query string false "End of range (RFC3339)"
+// @Success 200 {array} models.ResourceBlock
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/resources/{id}/blocks [get]
+func (h *CalendarImportHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid resource ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	from := time.Now()
+	to := from.AddDate(1, 0, 0)
+	query := r.URL.Query()
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, `{"error": "Invalid `+param+`, expected RFC3339 time"}`, http.StatusBadRequest)
+				return
+			}
+			*target = t
+		}
+	}
+
+	blocks, err := h.importService.ListBlocks(r.Context(), resourceID, from, to)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch blocks"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+// calendarFile возвращает файл из поля "file" формы или nil, если запрос без файла
+func calendarFile(w http.ResponseWriter, r *http.Request) (multipart.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, ical.MaxCalendarSize+(1<<20))
+	if err := r.ParseMultipartForm(ical.MaxCalendarSize); err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	file, _, err := r.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	return file, err
+}
+
+func writeCalendarImportError(w http.ResponseWriter, op string, resourceID int64, err error) {
+	switch {
+	case errors.Is(err, repository.ErrResourceNotFound):
+		http.Error(w, `{"error": "Resource not found"}`, http.StatusNotFound)
+	case errors.Is(err, repository.ErrExternalCalendarNotFound):
+		http.Error(w, `{"error": "External calendar not found"}`, http.StatusNotFound)
+	case errors.Is(err, service.ErrCalendarAccessDenied):
+		http.Error(w, `{"error": "Only the resource owner can import calendars"}`, http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidCalendarURL),
+		errors.Is(err, service.ErrCalendarFileRequired),
+		errors.Is(err, ical.ErrInvalidCalendar),
+		errors.Is(err, ical.ErrCalendarTooLarge):
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+	case errors.Is(err, service.ErrCalendarFetchFailed):
+		logger.Error("%s: resource %d - %v", op, resourceID, err)
+		http.Error(w, `{"error": "Failed to fetch calendar from url"}`, http.StatusBadGateway)
+	default:
+		logger.Error("%s: resource %d - %v", op, resourceID, err)
+		http.Error(w, `{"error": "Failed to import calendar"}`, http.StatusInternalServerError)
+	}
+}
+
+func parseExternalCalendarPath(w http.ResponseWriter, r *http.Request) (resourceID, calendarID int64, ok bool) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid resource ID"}`, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	calendarID, err = strconv.ParseInt(r.PathValue("calendar_id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid calendar ID"}`, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return resourceID, calendarID, true
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+type CouponHandler struct {
+	couponService service.CouponService
+}
+
+func NewCouponHandler(couponService service.CouponService) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+	}
+}
+
+// Create handles POST /coupons
+// @Summary Create a new coupon
+// @Description Create a promo code with a percent or fixed discount, validity dates, usage limits and optional category/resource restrictions
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param request body models.CouponRequest true "Coupon details"
+// @Success 201 {object} models.Coupon
+// @Failure 400 {string} string "Invalid request body"
+// @Failure 409 {string} string "Coupon code already exists"
+// @Router /coupons [post]
+func (h *CouponHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.couponService.Create(r.Context(), req)
+	if err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(coupon)
+}
+
+// GetByID handles GET /coupons/{id}
+// @Summary Get coupon by ID
+// @Description Get details of a specific coupon including usage count
+// @Tags coupons
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} models.Coupon
+// @Failure 400 {string} string "Invalid coupon ID"
+// @Failure 404 {string} string "Coupon not found"
+// @Router /coupons/{id} [get]
+func (h *CouponHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid coupon ID", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.couponService.GetByID(r.Context(), id)
+	if err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupon)
+}
+
+// List handles GET /coupons
+// @Summary List all coupons
+// @Description Get a list of all promo codes
+// @Tags coupons
+// @Produce json
+// @Success 200 {array} models.Coupon
+// @Failure 500 {string} string "Internal server error"
+// @Router /coupons [get]
+func (h *CouponHandler) List(w http.ResponseWriter, r *http.Request) {
+	coupons, err := h.couponService.List(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupons)
+}
+
+// Update handles PUT /coupons/{id}
+// @Summary Update a coupon
+// @Description Replace the settings and restrictions of an existing coupon
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Param request body models.CouponRequest true "Updated coupon details"
+// @Success 200 {object} models.Coupon
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Coupon not found"
+// @Router /coupons/{id} [put]
+func (h *CouponHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid coupon ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.couponService.Update(r.Context(), id, req)
+	if err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupon)
+}
+
+// Delete handles DELETE /coupons/{id}
+// @Summary Delete a coupon
+// @Description Delete a coupon by ID
+// @Tags coupons
+// @Param id path int true "Coupon ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid coupon ID"
+// @Failure 404 {string} string "Coupon not found"
+// @Router /coupons/{id} [delete]
+func (h *CouponHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid coupon ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.couponService.Delete(r.Context(), id); err != nil {
+		writeCouponError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeCouponError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCouponNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrCouponCodeExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"smartbooking/internal/invoice"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+type InvoiceHandler struct {
+	invoiceService service.InvoiceService
+	seller         invoice.Seller
+}
+
+func NewInvoiceHandler(invoiceService service.InvoiceService, seller invoice.Seller) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		seller:         seller,
+	}
+}
+
+// GetForBooking handles GET /bookings/{id}/invoice
+// @Summary Get booking invoice
+// @Description Get the invoice of a confirmed or paid booking. The invoice is issued with the next sequential
+// @Description number on first request. Use format=html (default), pdf or json.
+// @Tags invoices
+// @Produce html
+// @Produce application/pdf
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param format query string false "Output format: html, pdf or json"
+// @Success 200 {object} models.Invoice
+// @Failure 400 {string} string "Invoice is available only for confirmed or paid bookings"
+// @Failure 404 {string} string "Booking not found"
+// @Router /bookings/{id}/invoice [get]
+func (h *InvoiceHandler) GetForBooking(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+	if format != "html" && format != "pdf" && format != "json" {
+		http.Error(w, "Invalid format, expected html, pdf or json", http.StatusBadRequest)
+		return
+	}
+
+	inv, err := h.invoiceService.GetForBooking(r.Context(), bookingID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrBookingNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrInvoiceNotAvailable):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(inv)
+		return
+	}
+
+	// Рендерим в буфер, чтобы при ошибке не отдать клиенту половину документа
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = invoice.RenderPDF(&buf, inv, h.seller)
+	} else {
+		err = invoice.RenderHTML(&buf, inv, h.seller)
+	}
+	if err != nil {
+		logger.Error("Failed to render invoice %s: %v", inv.Number, err)
+		http.Error(w, "Failed to render invoice", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == "pdf" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, inv.Number))
+	}
+	buf.WriteTo(w)
+}
+
+// ListByOwner godoc
+// @Summary Get invoices for owner's resources
+// @Description Retrieves all invoices issued for bookings of resources owned by a specific owner
+// @Tags owners
+// @Produce json
+// @Param id path int true "Owner ID"
+// @Success 200 {array} models.Invoice
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/invoices [get]
+func (h *InvoiceHandler) ListByOwner(w http.ResponseWriter, r *http.Request) {
+	ownerIDStr := r.PathValue("id")
+	ownerID, err := strconv.ParseInt(ownerIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid owner ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	invoices, err := h.invoiceService.ListByOwner(r.Context(), ownerID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch owner invoices"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invoices)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+// NotificationHandler handles HTTP requests for in-app notifications
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List godoc
+// @Summary List user notifications
+// @Description Retrieves notifications of a user, newest first
+// @Tags notifications
+// @Produce json
+// @Param id path int true "User ID"
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.Notification
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/{id}/notifications [get]
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	unreadOnly := query.Get("unread") == "true"
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	notifications, err := h.notificationService.List(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch notifications"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// CountUnread godoc
+// @Summary Count unread notifications
+// @Description Returns the number of unread notifications of a user
+// @Tags notifications
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/{id}/notifications/unread-count [get]
+func (h *NotificationHandler) CountUnread(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.notificationService.CountUnread(r.Context(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to count notifications"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"unread": count})
+}
+
+// MarkRead godoc
+// @Summary Mark notification as read
+// @Description Marks a single notification of a user as read
+// @Tags notifications
+// @Param id path int true "User ID"
+// @Param notification_id path int true "Notification ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/{id}/notifications/{notification_id}/read [post]
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+	notificationID, err := strconv.ParseInt(r.PathValue("notification_id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid notification ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), notificationID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			http.Error(w, `{"error": "Notification not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "Failed to update notification"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications as read
+// @Description Marks every unread notification of a user as read and returns how many were updated
+// @Tags notifications
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/users/{id}/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid user ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to update notifications"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"updated": updated})
+}
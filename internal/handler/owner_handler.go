@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"smartbooking/internal/logger"
+	"smartbooking/internal/repository"
 	"smartbooking/internal/service"
 )
 
@@ -103,3 +107,72 @@ func (h *OwnerHandler) GetOwnerStatistics(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
+
+// GetOwnerCalendar godoc
+// @Summary Get owner calendar
+// @Description Returns bookings, external calendar blackouts and opening hours of the owner's resources grouped by resource and day. The period is either from/to or the day, week (Monday first) or month containing date; days of each resource are counted in its own time zone unless tz is given.
+// @Tags owners
+// @Produce json
+// @Param id path int true "Owner ID"
+// @Param view query string false "day, week or month" default(week)
+// @Param date query string false "Day within the period (YYYY-MM-DD), defaults to today"
+// @Param from query string false "Range start (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Range end (RFC3339, exclusive, or YYYY-MM-DD, inclusive)"
+// @Param tz query string false "IANA time zone for all resources; defaults to each resource's own zone"
+// @Param resource_id query int false "Only this resource"
+// @Success 200 {object} models.OwnerCalendar
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/calendar [get]
+func (h *OwnerHandler) GetOwnerCalendar(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid owner ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	params := r.URL.Query()
+	query := service.OwnerCalendarQuery{
+		View: params.Get("view"),
+		Date: params.Get("date"),
+		From: params.Get("from"),
+		To:   params.Get("to"),
+	}
+
+	if tz := params.Get("tz"); tz != "" {
+		query.Location, err = time.LoadLocation(tz)
+		if err != nil {
+			http.Error(w, `{"error": "Invalid tz, expected IANA time zone"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if value := params.Get("resource_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, `{"error": "Invalid resource_id"}`, http.StatusBadRequest)
+			return
+		}
+		query.ResourceID = &id
+	}
+
+	calendar, err := h.ownerService.GetOwnerCalendar(r.Context(), ownerID, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCalendarView),
+			errors.Is(err, service.ErrInvalidCalendarRange),
+			errors.Is(err, service.ErrInvalidCalendarTime):
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, repository.ErrResourceNotFound):
+			http.Error(w, `{"error": "Resource not found"}`, http.StatusNotFound)
+		default:
+			logger.Error("GetOwnerCalendar: owner %d - %v", ownerID, err)
+			http.Error(w, `{"error": "Failed to fetch owner calendar"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendar)
+}
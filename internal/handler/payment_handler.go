@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"smartbooking/internal/logger"
+	"smartbooking/internal/money"
+	"smartbooking/internal/payment"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+// maxWebhookBodySize ограничивает размер тела вебхука провайдера
+const maxWebhookBodySize = 1 << 20
+
+type PaymentHandler struct {
+	paymentService service.PaymentService
+	fakeProvider   *payment.FakeProvider
+}
+
+// NewPaymentHandler creates a new PaymentHandler. fakeProvider может быть nil;
+// если он задан, доступен эндпоинт имитации оплаты для локальной разработки.
+func NewPaymentHandler(paymentService service.PaymentService, fakeProvider *payment.FakeProvider) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+		fakeProvider:   fakeProvider,
+	}
+}
+
+type CreatePaymentRequest struct {
+	// Позиция графика платежей; если не указана, оплачивается ближайшая неоплаченная
+	InstallmentID int64 `json:"installment_id,omitempty"`
+}
+
+type RefundRequest struct {
+	Amount money.Amount `json:"amount,omitempty"`
+}
+
+type SimulatePaymentRequest struct {
+	Succeed bool `json:"succeed"`
+}
+
+// CreateForBooking handles POST /bookings/{id}/payments
+// @Summary Start a payment for a booking
+// @Description Create a payment intent for the next unpaid installment of the booking (deposit or full amount),
+// @Description or for a specific installment. The booking is confirmed once the first payment succeeds.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param request body CreatePaymentRequest false "Installment to pay"
+// @Success 201 {object} models.Payment
+// @Failure 400 {string} string "Booking cannot be paid"
+// @Failure 404 {string} string "Booking not found"
+// @Router /bookings/{id}/payments [post]
+func (h *PaymentHandler) CreateForBooking(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CreatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.paymentService.CreateForBooking(r.Context(), bookingID, req.InstallmentID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// ListByBooking handles GET /bookings/{id}/payments
+// @Summary List payments for a booking
+// @Description Get all payment attempts for a booking
+// @Tags payments
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {array} models.Payment
+// @Failure 400 {string} string "Invalid booking ID"
+// @Failure 500 {string} string "Internal server error"
+// @Router /bookings/{id}/payments [get]
+func (h *PaymentHandler) ListByBooking(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	payments, err := h.paymentService.ListByBooking(r.Context(), bookingID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payments)
+}
+
+// GetSchedule handles GET /bookings/{id}/payment-schedule
+// @Summary Get booking payment schedule
+// @Description Get the amount due, amount paid, balance and the deposit/balance installments of a booking
+// @Tags payments
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} models.PaymentSchedule
+// @Failure 400 {string} string "Invalid booking ID"
+// @Failure 404 {string} string "Booking not found"
+// @Router /bookings/{id}/payment-schedule [get]
+func (h *PaymentHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.paymentService.GetSchedule(r.Context(), bookingID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// GetByID handles GET /payments/{id}
+// @Summary Get payment by ID
+// @Description Get details of a specific payment
+// @Tags payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 400 {string} string "Invalid payment ID"
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/{id} [get]
+func (h *PaymentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.paymentService.GetByID(r.Context(), id)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// Capture handles POST /payments/{id}/capture
+// @Summary Capture an authorized payment
+// @Description Capture funds of a payment that was authorized with manual capture
+// @Tags payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 400 {string} string "Payment is not awaiting capture"
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/{id}/capture [post]
+func (h *PaymentHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.paymentService.Capture(r.Context(), id)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// Refund handles POST /payments/{id}/refund
+// @Summary Refund a payment
+// @Description Refund a succeeded payment fully or partially (amount omitted = remaining amount)
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Param request body RefundRequest false "Refund amount"
+// @Success 200 {object} models.Payment
+// @Failure 400 {string} string "Payment cannot be refunded"
+// @Failure 404 {string} string "Payment not found"
+// @Router /payments/{id}/refund [post]
+func (h *PaymentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.paymentService.Refund(r.Context(), id, req.Amount)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// Webhook handles POST /payments/webhook
+// @Summary Payment provider webhook
+// @Description Receives signed payment status events from the payment provider
+// @Tags payments
+// @Accept json
+// @Param X-Payment-Signature header string true "Webhook signature (t=<unix>,v1=<hmac>)"
+// @Success 200 "OK"
+// @Failure 400 {string} string "Invalid signature or payload"
+// @Router /payments/webhook [post]
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(r.Context(), payload, r.Header.Get("X-Payment-Signature")); err != nil {
+		logger.Error("Payment webhook rejected: %v", err)
+		writePaymentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Simulate handles POST /payments/{id}/simulate
+// @Summary Simulate customer payment (fake provider only)
+// @Description Completes or declines a payment in the fake provider and delivers the signed webhook
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Param request body SimulatePaymentRequest true "Payment outcome"
+// @Success 200 {object} models.Payment
+// @Failure 404 {string} string "Not available"
+// @Router /payments/{id}/simulate [post]
+func (h *PaymentHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	if h.fakeProvider == nil {
+		http.Error(w, "Payment simulation is not available", http.StatusNotFound)
+		return
+	}
+
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req SimulatePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.paymentService.GetByID(r.Context(), id)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	payload, signature, err := h.fakeProvider.Simulate(p.ProviderPaymentID, req.Succeed)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(r.Context(), payload, signature); err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	p, err = h.paymentService.GetByID(r.Context(), id)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrPaymentNotFound),
+		errors.Is(err, repository.ErrBookingNotFound),
+		errors.Is(err, repository.ErrInstallmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrBookingNotPayable),
+		errors.Is(err, service.ErrPaymentNotCaptured),
+		errors.Is(err, service.ErrPaymentNotRefundable),
+		errors.Is(err, service.ErrInstallmentPaid),
+		errors.Is(err, payment.ErrInvalidSignature),
+		errors.Is(err, payment.ErrInvalidState),
+		errors.Is(err, payment.ErrInvalidAmount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
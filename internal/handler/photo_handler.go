@@ -2,11 +2,14 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
 	"smartbooking/internal/service"
+	"smartbooking/internal/storage"
 )
 
 type PhotoHandler struct {
@@ -19,12 +22,20 @@ func NewPhotoHandler(photoService service.PhotoService) *PhotoHandler {
 	}
 }
 
+// maxPhotoRequestSize предел тела запроса с фотографиями
+const maxPhotoRequestSize = 100 << 20
+
+// UploadPhoto загружает одно фото: поля resource_id, is_primary,
+// display_order, caption, alt_text и файл photo
 func (h *PhotoHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	r.ParseMultipartForm(10 << 20)
+	if err := parsePhotoForm(w, r); err != nil {
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
 
 	resourceIDStr := r.FormValue("resource_id")
 	resourceID, err := strconv.ParseInt(resourceIDStr, 10, 64)
@@ -33,8 +44,11 @@ func (h *PhotoHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isPrimaryStr := r.FormValue("is_primary")
-	isPrimary := isPrimaryStr == "true"
+	req, ok := photoUploadRequest(r, resourceID)
+	if !ok {
+		http.Error(w, "Invalid display_order", http.StatusBadRequest)
+		return
+	}
 
 	file, handler, err := r.FormFile("photo")
 	if err != nil {
@@ -43,9 +57,184 @@ func (h *PhotoHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	photo, err := h.photoService.UploadPhoto(r.Context(), resourceID, file, handler.Filename, isPrimary)
+	photos, err := h.photoService.UploadPhotos(r.Context(), req, []service.PhotoFile{{
+		Reader:   file,
+		FileName: handler.Filename,
+		PhotoDetails: models.PhotoDetails{
+			Caption: r.FormValue("caption"),
+			AltText: r.FormValue("alt_text"),
+		},
+	}})
+	if err != nil {
+		writePhotoError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(photos[0])
+}
+
+// UploadPhotos загружает несколько фото ресурса одним запросом: файлы photos,
+// подписи caption и alt_text в том же порядке, общие is_primary и display_order.
+// Сохраняются все файлы или ни одного.
+func (h *PhotoHandler) UploadPhotos(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid resource id", http.StatusBadRequest)
+		return
+	}
+	if err := parsePhotoForm(w, r); err != nil {
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := photoUploadRequest(r, resourceID)
+	if !ok {
+		http.Error(w, "Invalid display_order", http.StatusBadRequest)
+		return
+	}
+
+	headers := r.MultipartForm.File["photos"]
+	captions := r.MultipartForm.Value["caption"]
+	altTexts := r.MultipartForm.Value["alt_text"]
+
+	files := make([]service.PhotoFile, 0, len(headers))
+	for i, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			http.Error(w, "Error retrieving file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		photo := service.PhotoFile{Reader: file, FileName: header.Filename}
+		if i < len(captions) {
+			photo.Caption = captions[i]
+		}
+		if i < len(altTexts) {
+			photo.AltText = altTexts[i]
+		}
+		files = append(files, photo)
+	}
+
+	photos, err := h.photoService.UploadPhotos(r.Context(), req, files)
+	if err != nil {
+		writePhotoError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(photos)
+}
+
+// SetPhotoOrder задает порядок галереи полным списком ID фото ресурса
+func (h *PhotoHandler) SetPhotoOrder(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid resource id", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PhotoOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	photos, err := h.photoService.SetPhotoOrder(r.Context(), resourceID, req.PhotoIDs)
+	if err != nil {
+		writePhotoError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(photos)
+}
+
+// UpdatePhotoDetails меняет подпись и альтернативный текст фото
+func (h *PhotoHandler) UpdatePhotoDetails(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error uploading photo: %v", err), http.StatusInternalServerError)
+		http.Error(w, "Invalid photo id", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PhotoDetails
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	photo, err := h.photoService.UpdatePhotoDetails(r.Context(), id, req)
+	if err != nil {
+		writePhotoError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(photo)
+}
+
+func parsePhotoForm(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPhotoRequestSize)
+	return r.ParseMultipartForm(10 << 20)
+}
+
+// photoUploadRequest читает общие поля загрузки is_primary и display_order
+func photoUploadRequest(r *http.Request, resourceID int64) (models.PhotoUploadRequest, bool) {
+	req := models.PhotoUploadRequest{
+		ResourceID: resourceID,
+		IsPrimary:  r.FormValue("is_primary") == "true",
+	}
+	if v := r.FormValue("display_order"); v != "" {
+		order, err := strconv.Atoi(v)
+		if err != nil {
+			return req, false
+		}
+		req.DisplayOrder = &order
+	}
+	return req, true
+}
+
+// CreateUploadURL выдает подписанную ссылку для загрузки фото напрямую в хранилище
+func (h *PhotoHandler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	var req models.PhotoUploadURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ResourceID == 0 {
+		http.Error(w, "Invalid resource_id", http.StatusBadRequest)
+		return
+	}
+
+	upload, err := h.photoService.CreateUploadURL(r.Context(), req.ResourceID, req.ContentType)
+	if err != nil {
+		writePhotoError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(upload)
+}
+
+// ConfirmUpload регистрирует фото, загруженное по ссылке из CreateUploadURL
+func (h *PhotoHandler) ConfirmUpload(w http.ResponseWriter, r *http.Request) {
+	var req models.PhotoConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ResourceID == 0 || req.StorageKey == "" {
+		http.Error(w, "resource_id and storage_key are required", http.StatusBadRequest)
+		return
+	}
+
+	photo, err := h.photoService.ConfirmUpload(r.Context(), req)
+	if err != nil {
+		writePhotoError(w, err)
 		return
 	}
 
@@ -54,6 +243,25 @@ func (h *PhotoHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(photo)
 }
 
+func writePhotoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnsupportedPhotoType), errors.Is(err, service.ErrInvalidUploadKey),
+		errors.Is(err, service.ErrNoPhotos), errors.Is(err, service.ErrTooManyPhotos),
+		errors.Is(err, service.ErrPhotoOrderMismatch), errors.Is(err, service.ErrPhotoDetailsTooLong):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, storage.ErrFileNotFound):
+		http.Error(w, "Uploaded file not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrPhotoNotFound):
+		http.Error(w, "Photo not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrResourceNotFound):
+		http.Error(w, "Resource not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrDirectUploadUnsupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h *PhotoHandler) GetResourcePhotos(w http.ResponseWriter, r *http.Request) {
 	resourceIDStr := r.PathValue("resource_id")
 	resourceID, err := strconv.ParseInt(resourceIDStr, 10, 64)
@@ -87,7 +295,7 @@ func (h *PhotoHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
 
 	err = h.photoService.DeletePhoto(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writePhotoError(w, err)
 		return
 	}
 
@@ -118,7 +326,7 @@ func (h *PhotoHandler) SetPrimaryPhoto(w http.ResponseWriter, r *http.Request) {
 
 	err = h.photoService.SetPrimaryPhoto(r.Context(), id, req.ResourceID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writePhotoError(w, err)
 		return
 	}
 
@@ -2,11 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+	"smartbooking/internal/repository"
 	"smartbooking/internal/service"
 )
 
@@ -21,10 +25,24 @@ func NewResourceHandler(resourceService service.ResourceService) *ResourceHandle
 }
 
 type CreateResourceRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Capacity    int     `json:"capacity"`
-	OwnerID     *int64  `json:"owner_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Capacity    int    `json:"capacity"`
+	OwnerID     *int64 `json:"owner_id"`
+
+	// Цена за час и ее валюта (ISO 4217, по умолчанию KZT)
+	PricePerHour *money.Amount `json:"price_per_hour,omitempty"`
+	Currency     string        `json:"currency,omitempty"`
+
+	// Бронирование подтверждается только после оплаты
+	RequiresPrepayment bool `json:"requires_prepayment"`
+
+	// Депозит в процентах при бронировании и срок доплаты остатка (часов до начала)
+	DepositPercent  *float64 `json:"deposit_percent,omitempty"`
+	BalanceDueHours int      `json:"balance_due_hours,omitempty"`
+
+	// Часовой пояс IANA (по умолчанию UTC): в нем считаются расписание и отчеты по дням
+	Timezone string `json:"timezone,omitempty"`
 }
 
 // Create handles POST /resources
@@ -45,13 +63,10 @@ func (h *ResourceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resource := &models.Resource{
-		Name:        req.Name,
-		Description: req.Description,
-		Capacity:    req.Capacity,
-		OwnerID:     req.OwnerID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	resource, err := resourceFromRequest(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	if err := h.resourceService.Create(r.Context(), resource); err != nil {
@@ -64,6 +79,92 @@ func (h *ResourceHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resource)
 }
 
+// Update handles PUT /resources/{id}
+// @Summary Update a resource
+// @Description Replace the details of a resource. An empty timezone keeps the current one. Opening hours and pricing windows are stored in local time, so they follow a changed time zone.
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param request body CreateResourceRequest true "Resource details"
+// @Success 200 {object} models.Resource
+// @Failure 400 {string} string "Invalid request body"
+// @Failure 404 {string} string "Resource not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /resources/{id} [put]
+func (h *ResourceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid resource ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CreateResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resource, err := resourceFromRequest(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resource.ID = id
+
+	if err := h.resourceService.Update(r.Context(), resource); err != nil {
+		if errors.Is(err, repository.ErrResourceNotFound) {
+			http.Error(w, "Resource not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resource)
+}
+
+// resourceFromRequest проверяет поля запроса создания или изменения ресурса.
+// Пустой часовой пояс остается пустым: при создании это UTC, при изменении —
+// текущий пояс ресурса.
+func resourceFromRequest(req *CreateResourceRequest) (*models.Resource, error) {
+	if req.DepositPercent != nil && (*req.DepositPercent <= 0 || *req.DepositPercent >= 100) {
+		return nil, errors.New("deposit_percent must be between 0 and 100")
+	}
+	if req.BalanceDueHours < 0 {
+		return nil, errors.New("balance_due_hours must not be negative")
+	}
+	if req.PricePerHour != nil && *req.PricePerHour < 0 {
+		return nil, errors.New("price_per_hour must not be negative")
+	}
+	currency, err := money.NormalizeCurrency(req.Currency)
+	if err != nil {
+		return nil, errors.New("currency must be a 3-letter ISO 4217 code")
+	}
+	timezone := req.Timezone
+	if strings.TrimSpace(timezone) != "" {
+		if timezone, err = models.NormalizeTimezone(timezone); err != nil {
+			return nil, errors.New("timezone must be an IANA time zone name, e.g. Asia/Almaty")
+		}
+	}
+
+	return &models.Resource{
+		Name:               req.Name,
+		Description:        req.Description,
+		Capacity:           req.Capacity,
+		OwnerID:            req.OwnerID,
+		PricePerHour:       req.PricePerHour,
+		Currency:           currency,
+		RequiresPrepayment: req.RequiresPrepayment,
+		DepositPercent:     req.DepositPercent,
+		BalanceDueHours:    req.BalanceDueHours,
+		Timezone:           timezone,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // GetByID handles GET /resources/{id}
 // @Summary Get resource by ID
 // @Description Get details of a specific resource
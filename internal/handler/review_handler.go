@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
 	"smartbooking/internal/service"
 )
 
@@ -24,22 +28,41 @@ type CreateReviewRequest struct {
 	BookingID  *int64 `json:"booking_id,omitempty"`
 	Rating     int    `json:"rating"`
 	Comment    string `json:"comment"`
+	models.SubRatings
 }
 
 type UpdateReviewRequest struct {
 	Rating  int    `json:"rating"`
 	Comment string `json:"comment"`
+	models.SubRatings
+}
+
+type ReplyReviewRequest struct {
+	OwnerID int64  `json:"owner_id"`
+	Text    string `json:"text"`
+}
+
+type HelpfulVoteRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+type ReportReviewRequest struct {
+	UserID int64  `json:"user_id"`
+	Reason string `json:"reason"`
 }
 
 // Create handles POST /reviews
 // @Summary Create a new review
-// @Description Create a new review for a resource
+// @Description Create a review for a resource. A review with booking_id is verified: the booking must belong to the user, be for the same resource and have ended. Only one review per booking is allowed.
 // @Tags reviews
 // @Accept json
 // @Produce json
 // @Param request body CreateReviewRequest true "Review details"
 // @Success 201 {object} models.Review
 // @Failure 400 {string} string "Invalid request body"
+// @Failure 403 {string} string "Booking belongs to another user"
+// @Failure 404 {string} string "Booking not found"
+// @Failure 409 {string} string "Booking has already been reviewed"
 // @Failure 500 {string} string "Internal server error"
 // @Router /reviews [post]
 func (h *ReviewHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -49,9 +72,18 @@ func (h *ReviewHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	review, err := h.reviewService.Create(r.Context(), req.UserID, req.ResourceID, req.BookingID, req.Rating, req.Comment)
+	review, err := h.reviewService.Create(r.Context(), req.UserID, req.ResourceID, req.BookingID, req.Rating, req.SubRatings, req.Comment)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		switch {
+		case errors.Is(err, service.ErrReviewBookingNotOwned):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, repository.ErrBookingNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, repository.ErrReviewAlreadyExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
 		return
 	}
 
@@ -62,10 +94,11 @@ func (h *ReviewHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // GetByID handles GET /reviews/{id}
 // @Summary Get review by ID
-// @Description Get details of a specific review
+// @Description Get details of a published review. A review awaiting moderation is returned only to its author
 // @Tags reviews
 // @Produce json
 // @Param id path int true "Review ID"
+// @Param viewer_id query int false "ID of the requesting user"
 // @Success 200 {object} models.Review
 // @Failure 400 {string} string "Invalid review ID"
 // @Failure 404 {string} string "Review not found"
@@ -78,16 +111,20 @@ func (h *ReviewHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid review ID", http.StatusBadRequest)
 		return
 	}
-
-	review, err := h.reviewService.GetByID(r.Context(), id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	viewerID, ok := parseViewerID(w, r)
+	if !ok {
 		return
 	}
-	if review == nil {
+
+	review, err := h.reviewService.GetByID(r.Context(), id, viewerID)
+	if errors.Is(err, repository.ErrReviewNotFound) {
 		http.Error(w, "Review not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(review)
@@ -95,12 +132,17 @@ func (h *ReviewHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // GetByResource handles GET /resources/{resource_id}/reviews
 // @Summary Get reviews for a resource
-// @Description Get all reviews for a specific resource
+// @Description Get a page of published reviews for a specific resource with photos and owner replies
 // @Tags reviews
 // @Produce json
 // @Param resource_id path int true "Resource ID"
-// @Success 200 {array} models.Review
-// @Failure 400 {string} string "Invalid resource ID"
+// @Param verified query bool false "Filter by verified flag"
+// @Param rating query int false "Filter by rating (1-5)"
+// @Param sort query string false "Sort order: newest (default), highest, lowest, helpful"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.ReviewPage
+// @Failure 400 {string} string "Invalid request"
 // @Failure 500 {string} string "Internal server error"
 // @Router /resources/{resource_id}/reviews [get]
 func (h *ReviewHandler) GetByResource(w http.ResponseWriter, r *http.Request) {
@@ -111,24 +153,29 @@ func (h *ReviewHandler) GetByResource(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reviews, err := h.reviewService.GetByResource(r.Context(), resourceID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	query, ok := parseReviewListQuery(w, r)
+	if !ok {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(reviews)
+	page, err := h.reviewService.GetByResource(r.Context(), resourceID, query)
+	writeReviewPage(w, page, err)
 }
 
 // GetByUser handles GET /users/{user_id}/reviews
 // @Summary Get reviews by user
-// @Description Get all reviews written by a specific user
+// @Description Get a page of published reviews written by a specific user. When viewer_id is the author, reviews awaiting moderation are included
 // @Tags reviews
 // @Produce json
 // @Param user_id path int true "User ID"
-// @Success 200 {array} models.Review
-// @Failure 400 {string} string "Invalid user ID"
+// @Param viewer_id query int false "ID of the requesting user"
+// @Param verified query bool false "Filter by verified flag"
+// @Param rating query int false "Filter by rating (1-5)"
+// @Param sort query string false "Sort order: newest (default), highest, lowest, helpful"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} models.ReviewPage
+// @Failure 400 {string} string "Invalid request"
 // @Failure 500 {string} string "Internal server error"
 // @Router /users/{user_id}/reviews [get]
 func (h *ReviewHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
@@ -139,14 +186,77 @@ func (h *ReviewHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reviews, err := h.reviewService.GetByUser(r.Context(), userID)
+	viewerID, ok := parseViewerID(w, r)
+	if !ok {
+		return
+	}
+	query, ok := parseReviewListQuery(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.reviewService.GetByUser(r.Context(), userID, viewerID, query)
+	writeReviewPage(w, page, err)
+}
+
+// parseViewerID читает необязательный viewer_id — пользователя, который
+// смотрит отзывы; 0 означает анонимный запрос
+func parseViewerID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	value := r.URL.Query().Get("viewer_id")
+	if value == "" {
+		return 0, true
+	}
+	viewerID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || viewerID <= 0 {
+		http.Error(w, "Invalid viewer_id", http.StatusBadRequest)
+		return 0, false
+	}
+	return viewerID, true
+}
+
+func parseReviewListQuery(w http.ResponseWriter, r *http.Request) (service.ReviewListQuery, bool) {
+	values := r.URL.Query()
+	query := service.ReviewListQuery{
+		Sort:   values.Get("sort"),
+		Cursor: values.Get("cursor"),
+	}
+	query.Limit, _ = strconv.Atoi(values.Get("limit"))
+
+	if value := values.Get("verified"); value != "" {
+		verified, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid verified flag", http.StatusBadRequest)
+			return query, false
+		}
+		query.Verified = &verified
+	}
+	if value := values.Get("rating"); value != "" {
+		rating, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid rating", http.StatusBadRequest)
+			return query, false
+		}
+		query.Rating = &rating
+	}
+
+	return query, true
+}
+
+func writeReviewPage(w http.ResponseWriter, page *models.ReviewPage, err error) {
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, service.ErrInvalidReviewSort),
+			errors.Is(err, service.ErrInvalidReviewCursor),
+			errors.Is(err, service.ErrInvalidRating):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(reviews)
+	json.NewEncoder(w).Encode(page)
 }
 
 // Update handles PUT /reviews/{id}
@@ -175,7 +285,7 @@ func (h *ReviewHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	review, err := h.reviewService.Update(r.Context(), id, req.Rating, req.Comment)
+	review, err := h.reviewService.Update(r.Context(), id, req.Rating, req.SubRatings, req.Comment)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -187,7 +297,7 @@ func (h *ReviewHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete handles DELETE /reviews/{id}
 // @Summary Delete a review
-// @Description Delete a review by ID
+// @Description Delete a review by ID together with its photos
 // @Tags reviews
 // @Param id path int true "Review ID"
 // @Success 204 "No Content"
@@ -210,17 +320,357 @@ func (h *ReviewHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetResourceAverageRating handles GET /resources/{resource_id}/rating
-// @Summary Get average rating for a resource
-// @Description Get the average rating for a specific resource
+// Reply handles PUT /reviews/{id}/reply
+// @Summary Reply to a review
+// @Description Post or edit the resource owner's public reply to a review. Only the owner of the reviewed resource may reply; the reviewer is notified about a new reply.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body ReplyReviewRequest true "Reply details"
+// @Success 200 {object} models.Review
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Only the resource owner can reply"
+// @Failure 404 {string} string "Review not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reviews/{id}/reply [put]
+func (h *ReviewHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ReplyReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.reviewService.Reply(r.Context(), id, req.OwnerID, req.Text)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReplyTextRequired):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrNotResourceOwner):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, repository.ErrReviewNotFound):
+			http.Error(w, "Review not found", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// Report handles POST /reviews/{id}/reports
+// @Summary Report a review
+// @Description Report an abusive review; it is added to the moderation queue
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body ReportReviewRequest true "Report details"
+// @Success 201 {object} models.ReviewReport
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Review not found"
+// @Failure 409 {string} string "Review has already been reported by this user"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reviews/{id}/reports [post]
+func (h *ReviewHandler) Report(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ReportReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.reviewService.Report(r.Context(), id, req.UserID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrReviewNotFound):
+			http.Error(w, "Review not found", http.StatusNotFound)
+		case errors.Is(err, repository.ErrReviewAlreadyReported):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// UploadPhoto handles POST /reviews/{id}/photos
+// @Summary Attach a photo to a review
+// @Description Upload an image (jpeg, png, gif, webp) for a review. Only the review author may upload; the number of photos per review is limited.
+// @Tags reviews
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param user_id formData int true "Review author ID"
+// @Param photo formData file true "Image file"
+// @Success 201 {object} models.ReviewPhoto
+// @Failure 400 {string} string "Invalid request or photo limit reached"
+// @Failure 403 {string} string "Only the review author can manage its photos"
+// @Failure 404 {string} string "Review not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reviews/{id}/photos [post]
+func (h *ReviewHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+	r.ParseMultipartForm(10 << 20)
+
+	userID, err := strconv.ParseInt(r.FormValue("user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("photo")
+	if err != nil {
+		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.reviewService.UploadPhoto(r.Context(), id, userID, file, header.Filename)
+	if err != nil {
+		writeReviewPhotoError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(photo)
+}
+
+// DeletePhoto handles DELETE /reviews/{id}/photos/{photo_id}
+// @Summary Delete a review photo
+// @Description Delete a photo from a review and from storage. Only the review author may delete.
+// @Tags reviews
+// @Param id path int true "Review ID"
+// @Param photo_id path int true "Photo ID"
+// @Param user_id query int true "Review author ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Only the review author can manage its photos"
+// @Failure 404 {string} string "Photo not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reviews/{id}/photos/{photo_id} [delete]
+func (h *ReviewHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+	photoID, err := strconv.ParseInt(r.PathValue("photo_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		return
+	}
+	userID, err := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reviewService.DeletePhoto(r.Context(), id, photoID, userID); err != nil {
+		writeReviewPhotoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeReviewPhotoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnsupportedPhotoType), errors.Is(err, service.ErrTooManyReviewPhotos):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrNotReviewAuthor):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrReviewNotFound):
+		http.Error(w, "Review not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrReviewPhotoNotFound):
+		http.Error(w, "Photo not found", http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// MarkHelpful handles POST /reviews/{id}/helpful
+// @Summary Mark a review as helpful
+// @Description Add the user's "helpful" vote to a published review. Each user can vote once per review and not for their own review.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body HelpfulVoteRequest true "Voter"
+// @Success 200 {object} models.Review
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Review not found"
+// @Failure 409 {string} string "Already marked helpful"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reviews/{id}/helpful [post]
+func (h *ReviewHandler) MarkHelpful(w http.ResponseWriter, r *http.Request) {
+	var req HelpfulVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	h.vote(w, r, req.UserID, h.reviewService.MarkHelpful)
+}
+
+// UnmarkHelpful handles DELETE /reviews/{id}/helpful
+// @Summary Remove a helpful vote
+// @Description Remove the user's "helpful" vote from a review
+// @Tags reviews
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param user_id query int true "Voter ID"
+// @Success 200 {object} models.Review
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Review or vote not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reviews/{id}/helpful [delete]
+func (h *ReviewHandler) UnmarkHelpful(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+	h.vote(w, r, userID, h.reviewService.UnmarkHelpful)
+}
+
+func (h *ReviewHandler) vote(w http.ResponseWriter, r *http.Request, userID int64, action func(ctx context.Context, reviewID, userID int64) (*models.Review, error)) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	review, err := action(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCannotVoteOwnReview):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, repository.ErrReviewNotFound):
+			http.Error(w, "Review not found", http.StatusNotFound)
+		case errors.Is(err, repository.ErrReviewVoteNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, repository.ErrReviewAlreadyVoted):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// ModerationQueue handles GET /admin/reviews/moderation
+// @Summary Review moderation queue
+// @Description Get reviews awaiting moderation and reviews with open reports, oldest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.ReviewModerationItem
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/reviews/moderation [get]
+func (h *ReviewHandler) ModerationQueue(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	items, err := h.reviewService.ModerationQueue(r.Context(), limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// Approve handles POST /admin/reviews/{id}/approve
+// @Summary Approve a review
+// @Description Publish a review and resolve its open reports
+// @Tags admin
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} models.Review
+// @Failure 400 {string} string "Invalid review ID"
+// @Failure 404 {string} string "Review not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/reviews/{id}/approve [post]
+func (h *ReviewHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.reviewService.Approve)
+}
+
+// Hide handles POST /admin/reviews/{id}/hide
+// @Summary Hide a review
+// @Description Hide a review from listings and the average rating and resolve its open reports
+// @Tags admin
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} models.Review
+// @Failure 400 {string} string "Invalid review ID"
+// @Failure 404 {string} string "Review not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/reviews/{id}/hide [post]
+func (h *ReviewHandler) Hide(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.reviewService.Hide)
+}
+
+func (h *ReviewHandler) moderate(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, id int64) (*models.Review, error)) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	review, err := action(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReviewNotFound) {
+			http.Error(w, "Review not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// GetRatingSummary handles GET /resources/{resource_id}/rating
+// @Summary Get rating summary for a resource
+// @Description Get the rating summary of published reviews: average, count, 1-5 star histogram, verified-only average, sub-rating averages and a monthly trend
 // @Tags reviews
 // @Produce json
 // @Param resource_id path int true "Resource ID"
-// @Success 200 {object} map[string]float64
+// @Param months query int false "Trend length in months including the current one (default 12, max 60)"
+// @Success 200 {object} models.RatingSummary
 // @Failure 400 {string} string "Invalid resource ID"
+// @Failure 404 {string} string "Resource not found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /resources/{resource_id}/rating [get]
-func (h *ReviewHandler) GetResourceAverageRating(w http.ResponseWriter, r *http.Request) {
+func (h *ReviewHandler) GetRatingSummary(w http.ResponseWriter, r *http.Request) {
 	resourceIDStr := r.PathValue("resource_id")
 	resourceID, err := strconv.ParseInt(resourceIDStr, 10, 64)
 	if err != nil {
@@ -228,12 +678,18 @@ func (h *ReviewHandler) GetResourceAverageRating(w http.ResponseWriter, r *http.
 		return
 	}
 
-	avgRating, err := h.reviewService.GetResourceAverageRating(r.Context(), resourceID)
+	months, _ := strconv.Atoi(r.URL.Query().Get("months"))
+
+	summary, err := h.reviewService.GetRatingSummary(r.Context(), resourceID, months)
 	if err != nil {
+		if errors.Is(err, repository.ErrResourceNotFound) {
+			http.Error(w, "Resource not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]float64{"average_rating": avgRating})
+	json.NewEncoder(w).Encode(summary)
 }
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"smartbooking/internal/logger"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/service"
+)
+
+// WebhookHandler handles HTTP requests for owner webhook subscriptions
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest represents the request body for creating a webhook subscription
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types,omitempty"` // empty means all events
+}
+
+// Create godoc
+// @Summary Create webhook subscription
+// @Description Subscribes an owner URL to booking, review and resource events. The response contains the signing secret; it is not shown again.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Owner ID"
+// @Param request body CreateWebhookRequest true "Webhook URL and event types"
+// @Success 201 {object} models.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid owner ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(r.Context(), ownerID, req.URL, req.EventTypes)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhookURL) || errors.Is(err, service.ErrInvalidWebhookEvent) {
+			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		logger.Error("CreateWebhook: failed for owner %d - %v", ownerID, err)
+		http.Error(w, `{"error": "Failed to create webhook"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// List godoc
+// @Summary List webhook subscriptions
+// @Description Retrieves webhook subscriptions of an owner (without secrets)
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Owner ID"
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid owner ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.webhookService.ListSubscriptions(r.Context(), ownerID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to fetch webhooks"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// Delete godoc
+// @Summary Delete webhook subscription
+// @Description Deletes a webhook subscription of an owner together with its delivery log
+// @Tags webhooks
+// @Param id path int true "Owner ID"
+// @Param webhook_id path int true "Webhook subscription ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/webhooks/{webhook_id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ownerID, webhookID, ok := parseWebhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), ownerID, webhookID); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			http.Error(w, `{"error": "Webhook not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "Failed to delete webhook"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Retrieves the delivery log of a webhook subscription, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Owner ID"
+// @Param webhook_id path int true "Webhook subscription ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/webhooks/{webhook_id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ownerID, webhookID, ok := parseWebhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), ownerID, webhookID, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			http.Error(w, `{"error": "Webhook not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"error": "Failed to fetch deliveries"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Queues a delivery to be sent again with a fresh retry budget
+// @Tags webhooks
+// @Param id path int true "Owner ID"
+// @Param webhook_id path int true "Webhook subscription ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/owners/{id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ownerID, webhookID, ok := parseWebhookPath(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(r.PathValue("delivery_id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid delivery ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.webhookService.Redeliver(r.Context(), ownerID, webhookID, deliveryID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrWebhookNotFound):
+			http.Error(w, `{"error": "Webhook not found"}`, http.StatusNotFound)
+		case errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+			http.Error(w, `{"error": "Delivery not found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"error": "Failed to redeliver webhook"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func parseWebhookPath(w http.ResponseWriter, r *http.Request) (ownerID, webhookID int64, ok bool) {
+	ownerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid owner ID"}`, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	webhookID, err = strconv.ParseInt(r.PathValue("webhook_id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid webhook ID"}`, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return ownerID, webhookID, true
+}
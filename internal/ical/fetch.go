@@ -0,0 +1,123 @@
+package ical
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// MaxCalendarSize ограничение размера загружаемого календаря
+const MaxCalendarSize = 5 << 20
+
+var (
+	// ErrCalendarTooLarge возвращается, если календарь больше MaxCalendarSize
+	ErrCalendarTooLarge = errors.New("calendar is too large")
+	// ErrForbiddenAddress возвращается, если адрес календаря ведет во внутреннюю сеть
+	ErrForbiddenAddress = errors.New("calendar url points to a private address")
+)
+
+// Fetcher загружает внешние календари по HTTP
+type Fetcher struct {
+	http *http.Client
+}
+
+// NewFetcher creates a calendar fetcher with the given request timeout. URL
+// календаря задает владелец ресурса, поэтому соединения с loopback, частными и
+// link-local адресами запрещены: сервер не должен ходить во внутреннюю сеть.
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return NewFetcherWithDialer(timeout, &net.Dialer{Control: denyPrivateAddress})
+}
+
+// NewFetcherWithDialer creates a calendar fetcher that connects through dialer.
+// Проверку адресов задает dialer; тесты передают его без проверки, чтобы
+// обращаться к локальному серверу.
+func NewFetcherWithDialer(timeout time.Duration, dialer *net.Dialer) *Fetcher {
+	// Прокси не используется: иначе проверялся бы адрес прокси, а не календаря
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+	return &Fetcher{
+		http: &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// denyPrivateAddress отклоняет соединение с внутренним адресом. Проверяется
+// адрес, к которому идет подключение после разрешения имени, поэтому проверку не
+// обойти ни DNS-записью на внутренний адрес, ни редиректом.
+func denyPrivateAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// NormalizeURL проверяет адрес календаря; схема webcal:// заменяется на https://
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid calendar url %q", rawURL)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("unsupported calendar url scheme %q", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// Fetch загружает и разбирает календарь. Ответ не 2xx считается ошибкой.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]Event, error) {
+	calURL, err := NormalizeURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, calURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar, */*;q=0.5")
+	req.Header.Set("User-Agent", "SmartBooking-Calendar-Sync/1.0")
+
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("calendar url returned status %d", resp.StatusCode)
+	}
+
+	return ParseLimited(resp.Body)
+}
+
+// ParseLimited разбирает календарь, отказываясь читать больше MaxCalendarSize
+func ParseLimited(r io.Reader) ([]Event, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxCalendarSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxCalendarSize {
+		return nil, ErrCalendarTooLarge
+	}
+	return Parse(bytes.NewReader(data))
+}
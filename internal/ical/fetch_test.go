@@ -0,0 +1,66 @@
+package ical
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDenyPrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		denied  bool
+	}{
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", false},
+
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.0.0.5:80", true},
+		{"172.16.0.1:80", true},
+		{"192.168.1.10:80", true},
+		{"[fd00::1]:80", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"0.0.0.0:80", true},
+		{"[::]:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"224.0.0.1:80", true},
+	}
+
+	for _, tt := range tests {
+		err := denyPrivateAddress("tcp", tt.address, nil)
+		if tt.denied && !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("%s: err = %v, want ErrForbiddenAddress", tt.address, err)
+		}
+		if !tt.denied && err != nil {
+			t.Errorf("%s: err = %v, want allowed", tt.address, err)
+		}
+	}
+}
+
+func TestFetchRejectsLoopbackServer(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer srv.Close()
+
+	if _, err := NewFetcher(time.Second).Fetch(context.Background(), srv.URL); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Fetch = %v, want ErrForbiddenAddress", err)
+	}
+	if requests != 0 {
+		t.Errorf("loopback server received %d requests", requests)
+	}
+
+	// Без проверки адресов тот же сервер доступен
+	fetcher := NewFetcherWithDialer(time.Second, &net.Dialer{})
+	if _, err := fetcher.Fetch(context.Background(), srv.URL); err != nil {
+		t.Errorf("Fetch with permissive dialer: %v", err)
+	}
+}
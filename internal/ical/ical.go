@@ -0,0 +1,171 @@
+// Package ical формирует календари iCalendar (RFC 5545) для подписки
+// из Google Calendar, Apple Calendar и других клиентов
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType MIME-тип календаря
+const ContentType = "text/calendar; charset=utf-8"
+
+const prodID = "-//SmartBooking//Booking Calendar//RU"
+
+// Status статус события (RFC 5545, 3.8.1.11)
+type Status string
+
+const (
+	StatusTentative Status = "TENTATIVE"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Event событие календаря (VEVENT). UID должен быть стабильным, чтобы клиент
+// обновлял событие, а не создавал копию; Sequence увеличивается при существенных
+// изменениях, например при отмене.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       Status
+	Sequence     int
+	Created      time.Time
+	LastModified time.Time
+
+	// Floating заполняется при разборе: DTSTART задан датой или местным временем
+	// без TZID. Такие Start и End содержат показания часов, прочитанные как UTC,
+	// и их нужно перенести в часовой пояс получателя (см. InLocation).
+	Floating bool
+}
+
+// InLocation переносит показания часов плавающего события в loc;
+// события с явным часовым поясом возвращаются без изменений
+func (e Event) InLocation(loc *time.Location) Event {
+	if !e.Floating {
+		return e
+	}
+	e.Start = wallClock(e.Start, loc)
+	e.End = wallClock(e.End, loc)
+	return e
+}
+
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// Calendar календарь (VCALENDAR)
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write записывает календарь в w
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	lw := &lineWriter{w: bw}
+	now := time.Now()
+
+	lw.line("BEGIN", "VCALENDAR")
+	lw.line("VERSION", "2.0")
+	lw.line("PRODID", prodID)
+	lw.line("CALSCALE", "GREGORIAN")
+	lw.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME", escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		lw.line("BEGIN", "VEVENT")
+		lw.line("UID", e.UID)
+		lw.line("DTSTAMP", formatTime(now))
+		lw.line("DTSTART", formatTime(e.Start))
+		lw.line("DTEND", formatTime(e.End))
+		lw.line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			lw.line("LOCATION", escapeText(e.Location))
+		}
+		if e.Status != "" {
+			lw.line("STATUS", string(e.Status))
+		}
+		lw.line("SEQUENCE", strconv.Itoa(e.Sequence))
+		if !e.Created.IsZero() {
+			lw.line("CREATED", formatTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			lw.line("LAST-MODIFIED", formatTime(e.LastModified))
+		}
+		lw.line("END", "VEVENT")
+	}
+
+	lw.line("END", "VCALENDAR")
+	if lw.err != nil {
+		return lw.err
+	}
+	return bw.Flush()
+}
+
+// formatTime форматирует время в UTC (форма DATE-TIME с суффиксом Z)
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// lineWriter пишет строки контента, разбивая их на части не длиннее 75 октетов
+// (RFC 5545, 3.1) без разрыва многобайтовых символов UTF-8
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+const maxLineOctets = 75
+
+func (lw *lineWriter) line(name, value string) {
+	if lw.err != nil {
+		return
+	}
+
+	s := name + ":" + value
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		lw.write(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// Продолжение начинается с пробела, который входит в лимит
+		limit = maxLineOctets - 1
+	}
+	lw.write(s + "\r\n")
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err == nil {
+		_, lw.err = lw.w.WriteString(s)
+	}
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
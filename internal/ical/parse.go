@@ -0,0 +1,274 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendar возвращается, если данные не похожи на iCalendar
+var ErrInvalidCalendar = errors.New("invalid iCalendar data")
+
+// maxLineLength ограничение длины развернутой строки контента
+const maxLineLength = 64 * 1024
+
+// Parse читает события VEVENT календаря. Поддерживаются DTSTART/DTEND в UTC,
+// с TZID и в виде даты (событие на весь день), а также DURATION вместо DTEND.
+// Даты и время без часового пояса читаются как UTC и помечаются Floating.
+// Правила повторения (RRULE) не разворачиваются: учитывается только первое
+// вхождение — ленты бронирований (Airbnb, Booking.com и т.п.) их не используют.
+// События без DTSTART пропускаются.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrInvalidCalendar
+	}
+
+	var events []Event
+	var current *Event
+	var duration time.Duration
+	hasEnd := false
+	allDay := false
+	depth := 0 // вложенные компоненты внутри VEVENT (VALARM)
+
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &Event{}
+			duration, hasEnd, allDay, depth = 0, false, false, 0
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT") && current != nil:
+			if !current.Start.IsZero() {
+				if !hasEnd {
+					switch {
+					case duration > 0:
+						current.End = current.Start.Add(duration)
+					case allDay:
+						current.End = current.Start.AddDate(0, 0, 1)
+					default:
+						current.End = current.Start
+					}
+				}
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		if name == "BEGIN" {
+			depth++
+			continue
+		}
+		if name == "END" {
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			current.UID = value
+		case "SUMMARY":
+			current.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(value)
+		case "LOCATION":
+			current.Location = unescapeText(value)
+		case "STATUS":
+			current.Status = Status(strings.ToUpper(value))
+		case "SEQUENCE":
+			current.Sequence, _ = strconv.Atoi(value)
+		case "DTSTART":
+			t, date, err := parseDateTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DTSTART %q: %w", value, err)
+			}
+			current.Start, allDay = t, date
+			current.Floating = date || (!strings.HasSuffix(value, "Z") && params["TZID"] == "")
+		case "DTEND":
+			t, _, err := parseDateTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("DTEND %q: %w", value, err)
+			}
+			current.End, hasEnd = t, true
+		case "DURATION":
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("DURATION %q: %w", value, err)
+			}
+			duration = d
+		case "CREATED":
+			current.Created, _, _ = parseDateTime(value, params)
+		case "LAST-MODIFIED":
+			current.LastModified, _, _ = parseDateTime(value, params)
+		}
+	}
+
+	return events, nil
+}
+
+// unfold читает строки контента, склеивая продолженные строки (RFC 5545, 3.1)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxLineLength)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(line, "\ufeff"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+
+	return lines, nil
+}
+
+// splitLine разбирает "NAME;PARAM=VALUE:value". Двоеточие внутри кавычек
+// в параметрах не считается разделителем.
+func splitLine(line string) (name string, params map[string]string, value string, ok bool) {
+	inQuotes := false
+	sep := -1
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			inQuotes = !inQuotes
+		} else if line[i] == ':' && !inQuotes {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	name = strings.ToUpper(parts[0])
+	params = make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return name, params, line[sep+1:], true
+}
+
+// parseDateTime разбирает DATE или DATE-TIME. Время без Z и без TZID
+// ("плавающее") читается как UTC. Второе значение — true для DATE.
+func parseDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration разбирает длительность вида P1D, PT2H30M, P1W (RFC 5545, 3.3.6)
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(value, "+")
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if !strings.HasPrefix(s, "P") {
+		return 0, ErrInvalidCalendar
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			num += string(c)
+		case c == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, ErrInvalidCalendar
+			}
+			num = ""
+			switch {
+			case c == 'W':
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case c == 'D':
+				total += time.Duration(n) * 24 * time.Hour
+			case c == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case c == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case c == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, ErrInvalidCalendar
+			}
+		}
+	}
+	if num != "" {
+		return 0, ErrInvalidCalendar
+	}
+
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// unescapeText снимает экранирование значения типа TEXT
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
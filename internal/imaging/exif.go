@@ -0,0 +1,81 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const exifOrientationTag = 0x0112
+
+// jpegOrientation достает значение Orientation из блока EXIF (APP1) файла
+// JPEG. Если блока нет или он поврежден, возвращает 1 — без поворота.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Начало сжатых данных: метаданные идут только до него
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation читает тег Orientation из нулевого IFD заголовка TIFF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// Значение типа SHORT хранится прямо в поле значения записи
+		if order.Uint16(tiff[entry+2:]) != 3 {
+			return 1
+		}
+		if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+			return v
+		}
+		return 1
+	}
+	return 1
+}
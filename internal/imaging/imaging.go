@@ -0,0 +1,290 @@
+// Package imaging проверяет загруженные изображения и готовит их к хранению:
+// определяет настоящий тип по содержимому, снимает метаданные (EXIF, GPS)
+// и строит уменьшенные копии
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	// MaxFileSize предельный размер исходного файла, как и у хранилища
+	MaxFileSize = 10 * 1024 * 1024
+	// MaxPixels предельное число пикселей; защищает от файлов, которые
+	// занимают мало места, но распаковываются в гигабайты
+	MaxPixels = 40_000_000
+
+	jpegQuality          = 90
+	jpegRenditionQuality = 85
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrFileTooLarge      = errors.New("image file exceeds 10MB limit")
+	ErrTooManyPixels     = errors.New("image dimensions are too large")
+)
+
+// Размеры уменьшенных копий по длинной стороне
+const (
+	ThumbnailSize = 320
+	MediumSize    = 1280
+)
+
+// Image закодированное изображение, готовое к загрузке в хранилище
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Ext расширение файла, соответствующее ContentType
+func (i *Image) Ext() string {
+	return Ext(i.ContentType)
+}
+
+// Supported сообщает, умеет ли пакет обрабатывать изображения этого типа
+func Supported(contentType string) bool {
+	return Ext(contentType) != ""
+}
+
+// Ext расширение файла для поддерживаемого MIME-типа, для остальных пустая строка
+func Ext(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ""
+	}
+}
+
+// Source декодированное исходное изображение
+type Source struct {
+	raw         []byte
+	contentType string
+	decoded     image.Image
+	// pixels изображение в правильной ориентации, общая основа для всех копий
+	pixels *image.RGBA
+}
+
+// Decode читает файл и проверяет, что это изображение поддерживаемого
+// формата (JPEG, PNG, GIF). Тип определяется по содержимому, а не по имени
+// файла. Ориентация из EXIF применяется сразу, поэтому после удаления
+// метаданных фотографии с телефона не оказываются повернутыми.
+func Decode(r io.Reader) (*Source, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(raw) > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	contentType := http.DetectContentType(raw)
+	if !Supported(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrUnsupportedFormat)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	// Для GIF декодируется только первый кадр — его достаточно для копий
+	decoded, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(raw)
+	}
+
+	return &Source{
+		raw:         raw,
+		contentType: contentType,
+		decoded:     decoded,
+		pixels:      orient(decoded, orientation),
+	}, nil
+}
+
+// ContentType настоящий MIME-тип файла
+func (s *Source) ContentType() string {
+	return s.contentType
+}
+
+// Width ширина с учетом ориентации
+func (s *Source) Width() int {
+	return s.pixels.Rect.Dx()
+}
+
+// Height высота с учетом ориентации
+func (s *Source) Height() int {
+	return s.pixels.Rect.Dy()
+}
+
+// Original возвращает исходное изображение без метаданных. JPEG и PNG
+// перекодируются: кодировщики стандартной библиотеки не пишут EXIF и
+// текстовые блоки. GIF сохраняется как есть, чтобы не потерять анимацию;
+// формат не поддерживает EXIF.
+func (s *Source) Original() (*Image, error) {
+	img := &Image{ContentType: s.contentType, Width: s.Width(), Height: s.Height()}
+
+	var buf bytes.Buffer
+	var err error
+	switch s.contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, s.pixels, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		// Кодируем декодированное изображение, а не RGBA-копию, чтобы
+		// сохранить палитру и не раздувать файл
+		err = png.Encode(&buf, s.decoded)
+	default:
+		img.Data = s.raw
+		return img, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	img.Data = buf.Bytes()
+	return img, nil
+}
+
+// Fit возвращает копию, вписанную в квадрат maxSide×maxSide. Изображение
+// только уменьшается. JPEG остается JPEG, PNG и GIF сохраняются в PNG,
+// чтобы не потерять прозрачность.
+func (s *Source) Fit(maxSide int) (*Image, error) {
+	w, h := fitSize(s.Width(), s.Height(), maxSide)
+
+	var dst image.Image = s.pixels
+	if w != s.Width() || h != s.Height() {
+		dst = resize(s.pixels, w, h)
+	}
+
+	img := &Image{Width: w, Height: h}
+	var buf bytes.Buffer
+	var err error
+	if s.contentType == "image/jpeg" {
+		img.ContentType = "image/jpeg"
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegRenditionQuality})
+	} else {
+		img.ContentType = "image/png"
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rendition: %w", err)
+	}
+
+	img.Data = buf.Bytes()
+	return img, nil
+}
+
+func fitSize(w, h, maxSide int) (int, int) {
+	if w <= maxSide && h <= maxSide {
+		return w, h
+	}
+	if w >= h {
+		return maxSide, max(1, h*maxSide/w)
+	}
+	return max(1, w*maxSide/h), maxSide
+}
+
+// orient переводит изображение в RGBA и поворачивает его согласно значению
+// EXIF Orientation (1–8)
+func orient(src image.Image, orientation int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	if orientation < 2 || orientation > 8 {
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+		return dst
+	}
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // отражение по горизонтали
+				dx, dy = w-1-x, y
+			case 3: // поворот на 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // отражение по вертикали
+				dx, dy = x, h-1-y
+			case 5: // транспонирование
+				dx, dy = y, x
+			case 6: // поворот на 90° по часовой
+				dx, dy = h-1-y, x
+			case 7: // поперечное транспонирование
+				dx, dy = h-1-y, w-1-x
+			case 8: // поворот на 90° против часовой
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// resize уменьшает изображение усреднением по площади: каждый пиксель
+// результата — среднее прямоугольника исходных пикселей
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for dy := 0; dy < h; dy++ {
+		y0 := dy * sh / h
+		y1 := max(y0+1, (dy+1)*sh/h)
+		for dx := 0; dx < w; dx++ {
+			x0 := dx * sw / w
+			x1 := max(x0+1, (dx+1)*sw/w)
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
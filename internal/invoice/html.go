@@ -0,0 +1,33 @@
+package invoice
+
+import (
+	"embed"
+	"html/template"
+	"io"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+)
+
+//go:embed templates/invoice.html
+var templateFS embed.FS
+
+var htmlTemplate = template.Must(template.New("invoice.html").Funcs(template.FuncMap{
+	"money":    func(v money.Amount) string { return FormatMoney(v, "") },
+	"quantity": FormatQuantity,
+}).ParseFS(templateFS, "templates/invoice.html"))
+
+// RenderHTML writes the invoice as a standalone HTML page
+func RenderHTML(w io.Writer, inv *models.Invoice, seller Seller) error {
+	return htmlTemplate.Execute(w, struct {
+		Title   string
+		Invoice *models.Invoice
+		Seller  Seller
+		Balance money.Amount
+	}{
+		Title:   Title(inv),
+		Invoice: inv,
+		Seller:  seller,
+		Balance: Balance(inv),
+	})
+}
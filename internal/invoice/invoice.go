@@ -0,0 +1,67 @@
+// Package invoice renders booking invoices as HTML and PDF documents.
+package invoice
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+)
+
+// Seller holds the issuer details printed on every invoice
+type Seller struct {
+	Name    string
+	Address string
+	TaxID   string
+	TaxName string
+}
+
+// Title returns "Invoice" or "Receipt" when the booking is fully paid
+func Title(inv *models.Invoice) string {
+	if inv.AmountPaid >= inv.Total && inv.Total > 0 {
+		return "Receipt"
+	}
+	return "Invoice"
+}
+
+// Balance returns the amount still to be paid
+func Balance(inv *models.Invoice) money.Amount {
+	return max(0, inv.Total-inv.AmountPaid)
+}
+
+// FormatMoney formats an amount with thousands separators and the currency code, e.g. "12 500.00 KZT"
+func FormatMoney(amount money.Amount, currency string) string {
+	s := amount.String()
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	intPart, frac := s[:len(s)-3], s[len(s)-2:]
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteByte('.')
+	b.WriteString(frac)
+	if currency != "" {
+		b.WriteByte(' ')
+		b.WriteString(currency)
+	}
+
+	return b.String()
+}
+
+// FormatQuantity prints whole quantities without decimals
+func FormatQuantity(q float64) string {
+	if q == math.Trunc(q) {
+		return strconv.FormatFloat(q, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(q, 'f', 2, 64)
+}
@@ -0,0 +1,272 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"smartbooking/internal/models"
+)
+
+// Минимальный генератор PDF без внешних зависимостей: страницы A4 и
+// стандартные шрифты Helvetica в кодировке WinAnsi. Кириллица в этих
+// шрифтах недоступна, поэтому транслитерируется латиницей.
+
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	marginLeft   = 50.0
+	marginRight  = pageWidth - 50.0
+	marginTop    = pageHeight - 50.0
+	marginBottom = 70.0
+	maxDescChars = 52
+)
+
+// RenderPDF writes the invoice as a PDF document
+func RenderPDF(w io.Writer, inv *models.Invoice, seller Seller) error {
+	doc := newPDFDocument()
+
+	doc.text(marginLeft, doc.y, 20, true, Title(inv)+" "+inv.Number)
+	doc.y -= 18
+	doc.text(marginLeft, doc.y, 9, false, fmt.Sprintf("Issued %s - Booking #%d", inv.IssuedAt.Format("02.01.2006"), inv.BookingID))
+	doc.y -= 32
+
+	top := doc.y
+	doc.text(marginLeft, doc.y, 10, true, seller.Name)
+	for _, line := range []string{seller.Address, taxIDLine(seller.TaxID)} {
+		if line != "" {
+			doc.y -= 13
+			doc.text(marginLeft, doc.y, 9, false, line)
+		}
+	}
+	left := doc.y
+
+	doc.y = top
+	doc.text(330, doc.y, 10, true, "Billed to")
+	doc.y -= 13
+	doc.text(330, doc.y, 9, false, inv.CustomerName)
+	doc.y -= 13
+	doc.text(330, doc.y, 9, false, inv.CustomerEmail)
+	doc.y = min(left, doc.y) - 28
+
+	doc.text(marginLeft, doc.y, 10, false, fmt.Sprintf("%s: %s - %s", inv.ResourceName,
+		inv.StartTime.Format("02.01.2006 15:04"), inv.EndTime.Format("02.01.2006 15:04")))
+	doc.y -= 26
+
+	tableHeader := func() {
+		doc.text(marginLeft, doc.y, 9, true, "#")
+		doc.text(72, doc.y, 9, true, "Description")
+		doc.textRight(380, doc.y, 9, true, "Qty")
+		doc.textRight(465, doc.y, 9, true, "Unit price")
+		doc.textRight(marginRight, doc.y, 9, true, "Amount")
+		doc.y -= 6
+		doc.line(marginLeft, doc.y, marginRight, doc.y)
+		doc.y -= 14
+	}
+	tableHeader()
+
+	for _, item := range inv.Items {
+		if doc.needsPage(20) {
+			tableHeader()
+		}
+		desc := item.Description
+		if len([]rune(desc)) > maxDescChars {
+			desc = string([]rune(desc)[:maxDescChars-3]) + "..."
+		}
+		doc.text(marginLeft, doc.y, 9, false, fmt.Sprintf("%d", item.Position))
+		doc.text(72, doc.y, 9, false, desc)
+		doc.textRight(380, doc.y, 9, false, FormatQuantity(item.Quantity))
+		doc.textRight(465, doc.y, 9, false, FormatMoney(item.UnitPrice, ""))
+		doc.textRight(marginRight, doc.y, 9, false, FormatMoney(item.Amount, ""))
+		doc.y -= 18
+	}
+
+	doc.needsPage(110)
+	doc.line(330, doc.y+8, marginRight, doc.y+8)
+	doc.y -= 6
+
+	totals := []totalLine{{"Subtotal", FormatMoney(inv.Subtotal, ""), false}}
+	if inv.DiscountAmount > 0 {
+		totals = append(totals, totalLine{"Discount", FormatMoney(-inv.DiscountAmount, ""), false})
+	}
+	totals = append(totals,
+		totalLine{"Total", FormatMoney(inv.Total, inv.Currency), true},
+		totalLine{fmt.Sprintf("incl. %s %s%%", seller.TaxName, FormatQuantity(inv.TaxRate)), FormatMoney(inv.TaxAmount, ""), false},
+		totalLine{"Paid", FormatMoney(inv.AmountPaid, ""), false},
+		totalLine{"Balance due", FormatMoney(Balance(inv), ""), true},
+	)
+
+	for _, t := range totals {
+		doc.textRight(440, doc.y, 10, t.bold, t.label)
+		doc.textRight(marginRight, doc.y, 10, t.bold, t.value)
+		doc.y -= 16
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+type totalLine struct {
+	label string
+	value string
+	bold  bool
+}
+
+func taxIDLine(taxID string) string {
+	if taxID == "" {
+		return ""
+	}
+	return "Tax ID: " + taxID
+}
+
+// pdfDocument накапливает контент страниц и сериализует их в PDF
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{}
+	d.addPage()
+	return d
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = marginTop
+}
+
+// needsPage начинает новую страницу, если до нижнего поля осталось меньше height
+func (d *pdfDocument) needsPage(height float64) bool {
+	if d.y-height >= marginBottom {
+		return false
+	}
+	d.addPage()
+	return true
+}
+
+func (d *pdfDocument) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *pdfDocument) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapePDFString(encodeWinAnsi(s)))
+}
+
+// textRight выравнивает текст по правому краю right
+func (d *pdfDocument) textRight(right, y, size float64, bold bool, s string) {
+	d.text(right-textWidth(encodeWinAnsi(s), size), y, size, bold, s)
+}
+
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// WriteTo сериализует документ: каталог, дерево страниц, шрифты и по паре объектов
+// (страница, поток содержимого) на каждую страницу, затем таблицу xref.
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+func escapePDFString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", " ", "\n", " ")
+	return r.Replace(s)
+}
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u",
+	'һ': "h", 'і': "i",
+}
+
+// encodeWinAnsi переводит строку в однобайтовую кодировку WinAnsi (Latin-1 + типографские
+// символы); кириллица транслитерируется, остальные символы заменяются на "?"
+func encodeWinAnsi(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		case r == '–':
+			b.WriteByte(0x96)
+		case r == '—':
+			b.WriteByte(0x97)
+		default:
+			if latin, ok := cyrillic[unicode.ToLower(r)]; ok {
+				if unicode.IsUpper(r) && latin != "" {
+					latin = strings.ToUpper(latin[:1]) + latin[1:]
+				}
+				b.WriteString(latin)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths — ширины символов ASCII 32..126 шрифта Helvetica (1/1000 кегля)
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth приблизительно вычисляет ширину закодированной строки в пунктах
+func textWidth(encoded string, size float64) float64 {
+	total := 0
+	for i := 0; i < len(encoded); i++ {
+		c := encoded[i]
+		if c >= 32 && c <= 126 {
+			total += helveticaWidths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
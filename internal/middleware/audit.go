@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"smartbooking/internal/audit"
+)
+
+// UserIDHeader заголовок с ID пользователя, от имени которого выполняется запрос
+const UserIDHeader = "X-User-ID"
+
+// AuditMiddleware кладет в контекст запроса исполнителя для журнала изменений:
+// ID пользователя из заголовка X-User-ID, IP клиента и User-Agent
+func AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := audit.Actor{
+			IPAddress: clientIP(r),
+			UserAgent: r.UserAgent(),
+		}
+		if id, err := strconv.ParseInt(r.Header.Get(UserIDHeader), 10, 64); err == nil && id > 0 {
+			actor.UserID = &id
+		}
+
+		next.ServeHTTP(w, r.WithContext(audit.WithActor(r.Context(), actor)))
+	})
+}
+
+// clientIP возвращает IP клиента с учетом прокси; пустую строку, если адрес не разобран
+func clientIP(r *http.Request) string {
+	candidate := r.RemoteAddr
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		candidate, _, _ = strings.Cut(forwarded, ",")
+	} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		candidate = realIP
+	}
+
+	candidate = strings.TrimSpace(candidate)
+	if host, _, err := net.SplitHostPort(candidate); err == nil {
+		candidate = host
+	}
+	if net.ParseIP(candidate) == nil {
+		return ""
+	}
+	return candidate
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog запись журнала изменений
+type AuditLog struct {
+	ID         int64           `json:"id"`
+	UserID     *int64          `json:"user_id,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   *int64          `json:"entity_id,omitempty"`
+	OldValue   json.RawMessage `json:"old_value,omitempty"`
+	NewValue   json.RawMessage `json:"new_value,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditLogFilter для выборки журнала изменений
+type AuditLogFilter struct {
+	EntityType string     `json:"entity_type"`
+	EntityID   *int64     `json:"entity_id"`
+	UserID     *int64     `json:"user_id"`
+	Action     string     `json:"action"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+}
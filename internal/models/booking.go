@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"smartbooking/internal/money"
+)
 
 // BookingStatus represents the status of a booking
 type BookingStatus string
@@ -19,11 +23,22 @@ type Booking struct {
 	StartTime  time.Time     `json:"start_time"`
 	EndTime    time.Time     `json:"end_time"`
 	Status     BookingStatus `json:"status"`
-	TotalPrice float64       `json:"total_price,omitempty"`
+	TotalPrice money.Amount  `json:"total_price,omitempty"`
+	Currency   string        `json:"currency"`
 	Notes      string        `json:"notes,omitempty"`
 	CreatedAt  time.Time     `json:"created_at"`
 	UpdatedAt  time.Time     `json:"updated_at"`
 
+	// Скидка по промокоду, уже вычтенная из TotalPrice
+	DiscountAmount money.Amount `json:"discount_amount,omitempty"`
+
+	// Оплачено за вычетом возвратов и остаток к оплате (TotalPrice - AmountPaid)
+	AmountPaid money.Amount `json:"amount_paid"`
+	Balance    money.Amount `json:"balance"`
+
+	// Расчет цены по тарифам ресурса, из которого получена стоимость
+	PriceItems []*PriceItem `json:"price_items,omitempty"`
+
 	// For JOIN queries
 	UserName     string `json:"user_name,omitempty"`
 	UserEmail    string `json:"user_email,omitempty"`
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// ExternalCalendarSource способ получения внешнего календаря
+type ExternalCalendarSource string
+
+const (
+	CalendarSourceURL    ExternalCalendarSource = "url"
+	CalendarSourceUpload ExternalCalendarSource = "upload"
+)
+
+// ExternalCalendar внешний календарь ресурса, события которого блокируют бронирование
+type ExternalCalendar struct {
+	ID           int64                  `json:"id"`
+	ResourceID   int64                  `json:"resource_id"`
+	Name         string                 `json:"name"`
+	Source       ExternalCalendarSource `json:"source"`
+	URL          string                 `json:"url,omitempty"`
+	NextSyncAt   time.Time              `json:"next_sync_at"`
+	LastSyncedAt *time.Time             `json:"last_synced_at,omitempty"`
+	LastError    string                 `json:"last_error,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// ResourceBlock период, когда ресурс недоступен для бронирования
+type ResourceBlock struct {
+	ID         int64     `json:"id"`
+	ResourceID int64     `json:"resource_id"`
+	CalendarID *int64    `json:"calendar_id,omitempty"`
+	UID        string    `json:"uid"`
+	Summary    string    `json:"summary,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CalendarSyncResult итог синхронизации внешнего календаря
+type CalendarSyncResult struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+}
+
+// ResourceSchedule часы работы ресурса в день недели (0 = воскресенье)
+type ResourceSchedule struct {
+	ResourceID int64  `json:"resource_id"`
+	DayOfWeek  int    `json:"day_of_week"`
+	OpenTime   string `json:"open_time"`  // HH:MM
+	CloseTime  string `json:"close_time"` // HH:MM
+	IsClosed   bool   `json:"is_closed"`
+}
+
+// OwnerCalendar календарь владельца: бронирования, блокировки и часы работы
+// по ресурсам и дням. From–To охватывает периоды всех ресурсов.
+type OwnerCalendar struct {
+	View      string                   `json:"view,omitempty"`
+	From      time.Time                `json:"from"`
+	To        time.Time                `json:"to"`
+	Resources []*OwnerCalendarResource `json:"resources"`
+}
+
+// OwnerCalendarResource дни календаря одного ресурса. Дни и период From–To
+// отсчитываются в часовом поясе Timezone — по умолчанию в поясе ресурса.
+type OwnerCalendarResource struct {
+	ResourceID   int64               `json:"resource_id"`
+	ResourceName string              `json:"resource_name"`
+	Timezone     string              `json:"timezone"`
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	Days         []*OwnerCalendarDay `json:"days"`
+}
+
+// OwnerCalendarDay один день ресурса. Бронирование или блокировка, идущие
+// несколько дней, попадают в каждый из них. Hours пусто, если расписание
+// на этот день не задано.
+type OwnerCalendarDay struct {
+	Date      string            `json:"date"` // YYYY-MM-DD
+	Hours     *ResourceSchedule `json:"hours,omitempty"`
+	Bookings  []*Booking        `json:"bookings"`
+	Blackouts []*ResourceBlock  `json:"blackouts"`
+}
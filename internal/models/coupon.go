@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"smartbooking/internal/money"
+)
+
+// DiscountType определяет способ расчета скидки
+type DiscountType string
+
+const (
+	DiscountPercent DiscountType = "percent"
+	DiscountFixed   DiscountType = "fixed"
+)
+
+// Coupon представляет промокод на скидку
+type Coupon struct {
+	ID             int64        `json:"id"`
+	Code           string       `json:"code"`
+	Description    string       `json:"description,omitempty"`
+	DiscountType   DiscountType `json:"discount_type"`
+	DiscountValue  float64      `json:"discount_value"`
+	Currency       string       `json:"currency,omitempty"`
+	ValidFrom      *time.Time   `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time   `json:"valid_until,omitempty"`
+	MaxUses        *int         `json:"max_uses,omitempty"`
+	MaxUsesPerUser *int         `json:"max_uses_per_user,omitempty"`
+	IsActive       bool         `json:"is_active"`
+	CategoryIDs    []int64      `json:"category_ids"`
+	ResourceIDs    []int64      `json:"resource_ids"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+
+	// Для JOIN запросов
+	TimesUsed int `json:"times_used"`
+}
+
+// CouponRedemption представляет применение купона к бронированию
+type CouponRedemption struct {
+	ID             int64        `json:"id"`
+	CouponID       int64        `json:"coupon_id"`
+	UserID         int64        `json:"user_id"`
+	BookingID      int64        `json:"booking_id"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// CouponRequest для создания и обновления купона
+type CouponRequest struct {
+	Code           string       `json:"code"`
+	Description    string       `json:"description"`
+	DiscountType   DiscountType `json:"discount_type"`
+	DiscountValue  float64      `json:"discount_value"`
+	Currency       string       `json:"currency"`
+	ValidFrom      *time.Time   `json:"valid_from"`
+	ValidUntil     *time.Time   `json:"valid_until"`
+	MaxUses        *int         `json:"max_uses"`
+	MaxUsesPerUser *int         `json:"max_uses_per_user"`
+	IsActive       *bool        `json:"is_active"`
+	CategoryIDs    []int64      `json:"category_ids"`
+	ResourceIDs    []int64      `json:"resource_ids"`
+}
+
+// CouponQuote результат проверки промокода для конкретного бронирования
+type CouponQuote struct {
+	Code           string       `json:"code"`
+	Currency       string       `json:"currency"`
+	Subtotal       money.Amount `json:"subtotal"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+	TotalPrice     money.Amount `json:"total_price"`
+
+	// Расчет Subtotal по тарифам ресурса
+	Items []*PriceItem `json:"items,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OutboxEmailStatus represents the delivery state of a queued email
+type OutboxEmailStatus string
+
+const (
+	EmailPending OutboxEmailStatus = "pending"
+	EmailSent    OutboxEmailStatus = "sent"
+	EmailFailed  OutboxEmailStatus = "failed"
+)
+
+// OutboxEmail письмо в очереди на отправку
+type OutboxEmail struct {
+	ID            int64             `json:"id"`
+	To            string            `json:"to"`
+	Subject       string            `json:"subject"`
+	TextBody      string            `json:"-"`
+	HTMLBody      string            `json:"-"`
+	Template      string            `json:"template"`
+	EventID       *int64            `json:"event_id,omitempty"`
+	Status        OutboxEmailStatus `json:"status"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	LastError     string            `json:"last_error,omitempty"`
+	SentAt        *time.Time        `json:"sent_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
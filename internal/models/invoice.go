@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"smartbooking/internal/money"
+)
+
+// Invoice is an issued invoice for a booking. Invoiced amounts are a snapshot
+// taken when the invoice was issued; AmountPaid is read from the booking.
+type Invoice struct {
+	ID             int64          `json:"id"`
+	Number         string         `json:"number"`
+	BookingID      int64          `json:"booking_id"`
+	ResourceID     *int64         `json:"resource_id,omitempty"`
+	OwnerID        *int64         `json:"owner_id,omitempty"`
+	CustomerName   string         `json:"customer_name"`
+	CustomerEmail  string         `json:"customer_email"`
+	ResourceName   string         `json:"resource_name"`
+	Currency       string         `json:"currency"`
+	Subtotal       money.Amount   `json:"subtotal"`
+	DiscountAmount money.Amount   `json:"discount_amount"`
+	TaxRate        float64        `json:"tax_rate"`
+	TaxAmount      money.Amount   `json:"tax_amount"`
+	Total          money.Amount   `json:"total"`
+	IssuedAt       time.Time      `json:"issued_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Items          []*InvoiceItem `json:"items,omitempty"`
+
+	// Из бронирования (JOIN): период и текущая оплаченная сумма
+	StartTime  time.Time    `json:"start_time"`
+	EndTime    time.Time    `json:"end_time"`
+	AmountPaid money.Amount `json:"amount_paid"`
+
+	// Часовой пояс ресурса (JOIN); даты счета приводятся к нему
+	Timezone string `json:"timezone"`
+}
+
+// InvoiceItem is one line of an invoice
+type InvoiceItem struct {
+	ID          int64        `json:"id"`
+	InvoiceID   int64        `json:"invoice_id"`
+	Position    int          `json:"position"`
+	Description string       `json:"description"`
+	Quantity    float64      `json:"quantity"`
+	UnitPrice   money.Amount `json:"unit_price"`
+	Amount      money.Amount `json:"amount"`
+}
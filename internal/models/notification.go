@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// NotificationType определяет оформление уведомления в интерфейсе
+type NotificationType string
+
+const (
+	NotificationInfo    NotificationType = "info"
+	NotificationSuccess NotificationType = "success"
+	NotificationWarning NotificationType = "warning"
+	NotificationError   NotificationType = "error"
+)
+
+// Notification представляет уведомление пользователя внутри приложения
+type Notification struct {
+	ID                int64            `json:"id"`
+	UserID            int64            `json:"user_id"`
+	Title             string           `json:"title"`
+	Message           string           `json:"message"`
+	Type              NotificationType `json:"type"`
+	IsRead            bool             `json:"is_read"`
+	RelatedEntityType string           `json:"related_entity_type,omitempty"`
+	RelatedEntityID   *int64           `json:"related_entity_id,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent доменное событие, сохраненное в outbox до доставки подписчикам
+type OutboxEvent struct {
+	ID                int64           `json:"id"`
+	Type              string          `json:"type"`
+	Payload           json.RawMessage `json:"payload"`
+	Attempts          int             `json:"attempts"`
+	CompletedHandlers []string        `json:"completed_handlers"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"smartbooking/internal/money"
+)
+
+// PaymentStatus represents the status of a booking payment
+type PaymentStatus string
+
+const (
+	PaymentRequiresPayment   PaymentStatus = "requires_payment"
+	PaymentRequiresCapture   PaymentStatus = "requires_capture"
+	PaymentSucceeded         PaymentStatus = "succeeded"
+	PaymentFailed            PaymentStatus = "failed"
+	PaymentCanceled          PaymentStatus = "canceled"
+	PaymentPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentRefunded          PaymentStatus = "refunded"
+)
+
+// Payment represents a payment for a booking made through a payment provider
+type Payment struct {
+	ID                int64         `json:"id"`
+	BookingID         int64         `json:"booking_id"`
+	Provider          string        `json:"provider"`
+	ProviderPaymentID string        `json:"provider_payment_id"`
+	Amount            money.Amount  `json:"amount"`
+	RefundedAmount    money.Amount  `json:"refunded_amount"`
+	Currency          string        `json:"currency"`
+	Status            PaymentStatus `json:"status"`
+	FailureReason     string        `json:"failure_reason,omitempty"`
+	InstallmentID     *int64        `json:"installment_id,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+
+	// Возвращается только при создании, для подтверждения оплаты на клиенте
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// InstallmentKind describes which part of the booking price an installment covers
+type InstallmentKind string
+
+const (
+	InstallmentDeposit InstallmentKind = "deposit"
+	InstallmentBalance InstallmentKind = "balance"
+	InstallmentFull    InstallmentKind = "full"
+)
+
+// InstallmentStatus represents the status of a scheduled payment
+type InstallmentStatus string
+
+const (
+	InstallmentPending  InstallmentStatus = "pending"
+	InstallmentPaid     InstallmentStatus = "paid"
+	InstallmentRefunded InstallmentStatus = "refunded"
+)
+
+// PaymentInstallment is one entry of a booking payment schedule
+type PaymentInstallment struct {
+	ID        int64             `json:"id"`
+	BookingID int64             `json:"booking_id"`
+	Kind      InstallmentKind   `json:"kind"`
+	Amount    money.Amount      `json:"amount"`
+	DueAt     time.Time         `json:"due_at"`
+	Status    InstallmentStatus `json:"status"`
+	PaidAt    *time.Time        `json:"paid_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// PaymentSchedule summarizes what is owed for a booking and when
+type PaymentSchedule struct {
+	BookingID    int64                 `json:"booking_id"`
+	Currency     string                `json:"currency"`
+	AmountDue    money.Amount          `json:"amount_due"`
+	AmountPaid   money.Amount          `json:"amount_paid"`
+	Balance      money.Amount          `json:"balance"`
+	NextDue      *PaymentInstallment   `json:"next_due,omitempty"`
+	Installments []*PaymentInstallment `json:"installments"`
+}
@@ -8,6 +8,10 @@ type ResourcePhoto struct {
 	ResourceID   int64     `json:"resource_id"`
 	URL          string    `json:"url"`
 	StorageKey   string    `json:"storage_key,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	ThumbnailKey string    `json:"thumbnail_key,omitempty"`
+	MediumURL    string    `json:"medium_url,omitempty"`
+	MediumKey    string    `json:"medium_key,omitempty"`
 	FileName     string    `json:"file_name"`
 	FileSize     int64     `json:"file_size"`
 	MimeType     string    `json:"mime_type"`
@@ -17,11 +21,53 @@ type ResourcePhoto struct {
 	DisplayOrder int       `json:"display_order"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	PhotoDetails
+}
+
+// PhotoDetails подпись и альтернативный текст фотографии
+type PhotoDetails struct {
+	Caption string `json:"caption,omitempty"`
+	AltText string `json:"alt_text,omitempty"`
 }
 
-// PhotoUploadRequest для загрузки фото
+// PhotoUploadRequest для загрузки фото. При загрузке нескольких файлов
+// главным становится первый, а DisplayOrder задает позицию первого файла;
+// без нее фото добавляются в конец галереи.
 type PhotoUploadRequest struct {
+	ResourceID   int64 `json:"resource_id"`
+	IsPrimary    bool  `json:"is_primary"`
+	DisplayOrder *int  `json:"display_order,omitempty"`
+}
+
+// PhotoOrderRequest новый порядок галереи: все фото ресурса, каждое один раз
+type PhotoOrderRequest struct {
+	PhotoIDs []int64 `json:"photo_ids"`
+}
+
+// PhotoUploadURLRequest запрос ссылки для прямой загрузки фото в хранилище
+type PhotoUploadURLRequest struct {
+	ResourceID  int64  `json:"resource_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+}
+
+// PhotoUploadURL ссылка для прямой загрузки: клиент отправляет файл методом
+// Method с заголовками Headers, затем подтверждает загрузку по StorageKey
+type PhotoUploadURL struct {
+	URL        string            `json:"url"`
+	Method     string            `json:"method"`
+	Headers    map[string]string `json:"headers"`
+	StorageKey string            `json:"storage_key"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+}
+
+// PhotoConfirmRequest подтверждение прямой загрузки
+type PhotoConfirmRequest struct {
 	ResourceID   int64  `json:"resource_id"`
+	StorageKey   string `json:"storage_key"`
+	FileName     string `json:"file_name"`
 	IsPrimary    bool   `json:"is_primary"`
-	DisplayOrder int    `json:"display_order"`
+	DisplayOrder *int   `json:"display_order,omitempty"`
+	PhotoDetails
 }
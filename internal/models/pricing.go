@@ -0,0 +1,29 @@
+package models
+
+import "smartbooking/internal/money"
+
+// PricingRule тариф ресурса: цена Price за DurationMinutes минут в день недели
+// DayOfWeek (nil — любой день) с TimeFrom до TimeTo по местному времени
+// ресурса (nil — весь день)
+type PricingRule struct {
+	ID              int64        `json:"id"`
+	ResourceID      int64        `json:"resource_id"`
+	Name            string       `json:"name"`
+	Price           money.Amount `json:"price"`
+	DurationMinutes int          `json:"duration_minutes"`
+	DayOfWeek       *int         `json:"day_of_week,omitempty"`
+	TimeFrom        *string      `json:"time_from,omitempty"` // HH:MM
+	TimeTo          *string      `json:"time_to,omitempty"`   // HH:MM
+}
+
+// PriceItem позиция расчета цены бронирования: Quantity единиц по UnitMinutes
+// минут по цене UnitPrice. Amount всегда равна UnitPrice × Quantity с
+// округлением до минимальной единицы валюты.
+type PriceItem struct {
+	PricingID   *int64       `json:"pricing_id,omitempty"`
+	Name        string       `json:"name"`
+	UnitMinutes int          `json:"unit_minutes"`
+	Quantity    float64      `json:"quantity"`
+	UnitPrice   money.Amount `json:"unit_price"`
+	Amount      money.Amount `json:"amount"`
+}
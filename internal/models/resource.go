@@ -2,77 +2,122 @@ package models
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
+
+	"smartbooking/internal/money"
 )
 
 // Resource представляет бронируемый ресурс
 type Resource struct {
-	ID           int64            `json:"id"`
-	Name         string           `json:"name"`
-	Description  string           `json:"description"`
-	Capacity     int              `json:"capacity"`
-	OwnerID      *int64           `json:"owner_id,omitempty"`
-	CategoryID   *int64           `json:"category_id,omitempty"`
-	Address      string           `json:"address,omitempty"`
-	City         string           `json:"city,omitempty"`
-	Latitude     *float64         `json:"latitude,omitempty"`
-	Longitude    *float64         `json:"longitude,omitempty"`
-	Amenities    []string         `json:"amenities,omitempty"`
-	Rules        string           `json:"rules,omitempty"`
-	PricePerHour *float64         `json:"price_per_hour,omitempty"`
-	IsActive     bool             `json:"is_active"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
+	ID                 int64         `json:"id"`
+	Name               string        `json:"name"`
+	Description        string        `json:"description"`
+	Capacity           int           `json:"capacity"`
+	OwnerID            *int64        `json:"owner_id,omitempty"`
+	CategoryID         *int64        `json:"category_id,omitempty"`
+	Address            string        `json:"address,omitempty"`
+	City               string        `json:"city,omitempty"`
+	Latitude           *float64      `json:"latitude,omitempty"`
+	Longitude          *float64      `json:"longitude,omitempty"`
+	Amenities          []string      `json:"amenities,omitempty"`
+	Rules              string        `json:"rules,omitempty"`
+	PricePerHour       *money.Amount `json:"price_per_hour,omitempty"`
+	Currency           string        `json:"currency"`
+	IsActive           bool          `json:"is_active"`
+	RequiresPrepayment bool          `json:"requires_prepayment"`
+	DepositPercent     *float64      `json:"deposit_percent,omitempty"`
+	BalanceDueHours    int           `json:"balance_due_hours"`
+	Timezone           string        `json:"timezone"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
 
 	// Для JOIN запросов
-	CategoryName string            `json:"category_name,omitempty"`
-	OwnerName    string            `json:"owner_name,omitempty"`
-	Photos       []ResourcePhoto   `json:"photos,omitempty"`
-	Rating       float64           `json:"rating,omitempty"`
-	ReviewsCount int               `json:"reviews_count,omitempty"`
+	CategoryName string          `json:"category_name,omitempty"`
+	OwnerName    string          `json:"owner_name,omitempty"`
+	Photos       []ResourcePhoto `json:"photos,omitempty"`
+	Rating       float64         `json:"rating,omitempty"`
+	ReviewsCount int             `json:"reviews_count,omitempty"`
+}
+
+// Location возвращает часовой пояс ресурса
+func (r *Resource) Location() *time.Location {
+	return TimezoneLocation(r.Timezone)
+}
+
+// TimezoneLocation загружает часовой пояс IANA; UTC, если имя пустое или неизвестно
+func TimezoneLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NormalizeTimezone проверяет имя часового пояса IANA; пустое имя означает UTC
+func NormalizeTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "UTC", nil
+	}
+	// "Local" зависит от настроек сервера и не годится для ресурса
+	if name == "Local" {
+		return "", fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", err
+	}
+	return loc.String(), nil
 }
 
 // ResourceCreateRequest для создания ресурса
 type ResourceCreateRequest struct {
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	Capacity     int       `json:"capacity"`
-	OwnerID      *int64    `json:"owner_id"`
-	CategoryID   *int64    `json:"category_id"`
-	Address      string    `json:"address"`
-	City         string    `json:"city"`
-	Latitude     *float64  `json:"latitude"`
-	Longitude    *float64  `json:"longitude"`
-	Amenities    []string  `json:"amenities"`
-	Rules        string    `json:"rules"`
-	PricePerHour *float64  `json:"price_per_hour"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	Capacity     int           `json:"capacity"`
+	OwnerID      *int64        `json:"owner_id"`
+	CategoryID   *int64        `json:"category_id"`
+	Address      string        `json:"address"`
+	City         string        `json:"city"`
+	Latitude     *float64      `json:"latitude"`
+	Longitude    *float64      `json:"longitude"`
+	Amenities    []string      `json:"amenities"`
+	Rules        string        `json:"rules"`
+	PricePerHour *money.Amount `json:"price_per_hour"`
+	Currency     string        `json:"currency"`
 }
 
 // ResourceUpdateRequest для обновления ресурса
 type ResourceUpdateRequest struct {
-	Name         *string   `json:"name,omitempty"`
-	Description  *string   `json:"description,omitempty"`
-	Capacity     *int      `json:"capacity,omitempty"`
-	CategoryID   *int64    `json:"category_id,omitempty"`
-	Address      *string   `json:"address,omitempty"`
-	City         *string   `json:"city,omitempty"`
-	Latitude     *float64  `json:"latitude,omitempty"`
-	Longitude    *float64  `json:"longitude,omitempty"`
-	Amenities    []string  `json:"amenities,omitempty"`
-	Rules        *string   `json:"rules,omitempty"`
-	PricePerHour *float64  `json:"price_per_hour,omitempty"`
-	IsActive     *bool     `json:"is_active,omitempty"`
+	Name         *string       `json:"name,omitempty"`
+	Description  *string       `json:"description,omitempty"`
+	Capacity     *int          `json:"capacity,omitempty"`
+	CategoryID   *int64        `json:"category_id,omitempty"`
+	Address      *string       `json:"address,omitempty"`
+	City         *string       `json:"city,omitempty"`
+	Latitude     *float64      `json:"latitude,omitempty"`
+	Longitude    *float64      `json:"longitude,omitempty"`
+	Amenities    []string      `json:"amenities,omitempty"`
+	Rules        *string       `json:"rules,omitempty"`
+	PricePerHour *money.Amount `json:"price_per_hour,omitempty"`
+	Currency     *string       `json:"currency,omitempty"`
+	IsActive     *bool         `json:"is_active,omitempty"`
 }
 
 // ResourceFilterParams для фильтрации ресурсов
 type ResourceFilterParams struct {
-	CategoryID *int64   `json:"category_id"`
-	City       string   `json:"city"`
-	MinPrice   *float64 `json:"min_price"`
-	MaxPrice   *float64 `json:"max_price"`
-	IsActive   *bool    `json:"is_active"`
-	Limit      int      `json:"limit"`
-	Offset     int      `json:"offset"`
+	CategoryID *int64        `json:"category_id"`
+	City       string        `json:"city"`
+	MinPrice   *money.Amount `json:"min_price"`
+	MaxPrice   *money.Amount `json:"max_price"`
+	IsActive   *bool         `json:"is_active"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
 }
 
 // ScanAmenities помощник для сканирования amenities из БД
@@ -2,22 +2,115 @@ package models
 
 import "time"
 
+// ReviewStatus статус модерации отзыва
+type ReviewStatus string
+
+const (
+	ReviewPublished ReviewStatus = "published"
+	ReviewPending   ReviewStatus = "pending" // ждет решения модератора
+	ReviewHidden    ReviewStatus = "hidden"
+)
+
 // Review представляет отзыв о ресурсе
 type Review struct {
-	ID         int64     `json:"id"`
-	ResourceID int64     `json:"resource_id"`
-	UserID     int64     `json:"user_id"`
-	BookingID  *int64    `json:"booking_id,omitempty"`
-	Rating     int       `json:"rating"`
-	Comment    string    `json:"comment,omitempty"`
-	IsVerified bool      `json:"is_verified"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID           int64        `json:"id"`
+	ResourceID   int64        `json:"resource_id"`
+	UserID       int64        `json:"user_id"`
+	BookingID    *int64       `json:"booking_id,omitempty"`
+	Rating       int          `json:"rating"`
+	Comment      string       `json:"comment,omitempty"`
+	IsVerified   bool         `json:"is_verified"`
+	Status       ReviewStatus `json:"status"`
+	HelpfulCount int          `json:"helpful_count"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+
+	// Необязательные оценки по критериям
+	SubRatings
+
+	// Ответ владельца ресурса и фотографии автора
+	Reply  *ReviewReply   `json:"reply,omitempty"`
+	Photos []*ReviewPhoto `json:"photos"`
 
 	// Для JOIN запросов
 	UserName string `json:"user_name,omitempty"`
 }
 
+// ReviewPage страница списка отзывов. NextCursor пуст на последней странице.
+type ReviewPage struct {
+	Reviews    []*Review `json:"reviews"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// ReviewPhoto фотография, приложенная автором к отзыву
+type ReviewPhoto struct {
+	ID           int64     `json:"id"`
+	ReviewID     int64     `json:"review_id"`
+	URL          string    `json:"url"`
+	StorageKey   string    `json:"-"`
+	FileName     string    `json:"file_name"`
+	FileSize     int64     `json:"file_size"`
+	MimeType     string    `json:"mime_type"`
+	DisplayOrder int       `json:"display_order"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ReviewReply публичный ответ владельца ресурса на отзыв
+type ReviewReply struct {
+	ID        int64     `json:"id"`
+	ReviewID  int64     `json:"review_id"`
+	OwnerID   int64     `json:"owner_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SubRatings необязательные оценки отзыва по отдельным критериям (1–5)
+type SubRatings struct {
+	Cleanliness *int `json:"cleanliness,omitempty"`
+	Value       *int `json:"value,omitempty"`
+	Location    *int `json:"location,omitempty"`
+}
+
+// RatingSummary сводка оценок ресурса по опубликованным отзывам. Средние по
+// критериям равны nil, если такие оценки еще не ставили.
+type RatingSummary struct {
+	ResourceID            int64              `json:"resource_id"`
+	AverageRating         float64            `json:"average_rating"`
+	ReviewCount           int                `json:"review_count"`
+	Histogram             map[int]int        `json:"histogram"`
+	VerifiedAverageRating float64            `json:"verified_average_rating"`
+	VerifiedCount         int                `json:"verified_count"`
+	Cleanliness           *float64           `json:"cleanliness,omitempty"`
+	Value                 *float64           `json:"value,omitempty"`
+	Location              *float64           `json:"location,omitempty"`
+	Trend                 []RatingTrendPoint `json:"trend"`
+}
+
+// RatingTrendPoint средняя оценка отзывов за месяц (в часовом поясе ресурса)
+type RatingTrendPoint struct {
+	Month         string  `json:"month"` // YYYY-MM
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+}
+
+// ReviewReport жалоба пользователя на отзыв. Жалоба закрывается (ResolvedAt),
+// когда модератор одобряет или скрывает отзыв.
+type ReviewReport struct {
+	ID         int64      `json:"id"`
+	ReviewID   int64      `json:"review_id"`
+	UserID     int64      `json:"user_id"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReviewModerationItem отзыв в очереди модерации вместе с открытыми жалобами
+type ReviewModerationItem struct {
+	Review  *Review         `json:"review"`
+	Reports []*ReviewReport `json:"reports"`
+}
+
 // ReviewCreateRequest для создания отзыва
 type ReviewCreateRequest struct {
 	ResourceID int64  `json:"resource_id"`
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription подписка владельца на исходящие вебхуки
+type WebhookSubscription struct {
+	ID         int64     `json:"id"`
+	OwnerID    int64     `json:"owner_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"` // отдается только при создании
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery запись журнала доставки события в подписку
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID int64                 `json:"subscription_id"`
+	EventID        int64                 `json:"event_id"`
+	EventType      string                `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	ResponseStatus *int                  `json:"response_status,omitempty"`
+	ResponseBody   string                `json:"response_body,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+
+	// Заполняются при выборке к отправке
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
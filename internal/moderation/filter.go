@@ -0,0 +1,62 @@
+// Package moderation отбирает подозрительные пользовательские тексты
+// для ручной проверки модератором
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Filter предварительный фильтр: текст подозрителен, если содержит одно из
+// запрещенных слов или совпадает с регулярным выражением. Нулевой Filter
+// пропускает любой текст.
+type Filter struct {
+	words   map[string]bool
+	pattern *regexp.Regexp
+}
+
+// NewFilter создает фильтр. Слова сравниваются целиком без учета регистра,
+// для фраз и частей слов используется pattern (синтаксис RE2, можно с флагом (?i)).
+func NewFilter(words []string, pattern string) (*Filter, error) {
+	f := &Filter{words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.words[w] = true
+		}
+	}
+
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderation pattern: %w", err)
+		}
+		f.pattern = re
+	}
+
+	return f, nil
+}
+
+// Suspicious сообщает, нужно ли отправить текст на модерацию
+func (f *Filter) Suspicious(text string) bool {
+	if f == nil || text == "" {
+		return false
+	}
+	if f.pattern != nil && f.pattern.MatchString(text) {
+		return true
+	}
+	if len(f.words) == 0 {
+		return false
+	}
+
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, t := range tokens {
+		if f.words[t] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+// Package money provides an exact representation of monetary amounts and
+// conversion between currencies.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for resources and bookings created without an explicit currency
+const DefaultCurrency = "KZT"
+
+var (
+	ErrInvalidAmount   = errors.New("invalid money amount")
+	ErrInvalidCurrency = errors.New("invalid currency code")
+)
+
+// Amount is a monetary amount in minor units (hundredths of the currency unit,
+// e.g. tiyn for KZT or cents for USD). All DECIMAL(10, 2) columns map to it
+// without rounding errors.
+type Amount int64
+
+// FromMinor returns an amount of v minor units
+func FromMinor(v int64) Amount {
+	return Amount(v)
+}
+
+// FromFloat converts a float value in major units, rounding half away from zero
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * 100))
+}
+
+// Parse parses a decimal string in major units such as "12500", "12500.5" or "-3.25".
+// More than two fractional digits are rejected instead of being rounded.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	if s[0] == '-' || s[0] == '+' {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+	if !isDigits(intPart) || (hasFrac && (!isDigits(fracPart) || len(fracPart) > 2)) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	major, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || major > math.MaxInt64/100 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	var minor int64
+	if hasFrac {
+		for len(fracPart) < 2 {
+			fracPart += "0"
+		}
+		minor, _ = strconv.ParseInt(fracPart, 10, 64)
+	}
+
+	v := major*100 + minor
+	if negative {
+		v = -v
+	}
+	return Amount(v), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor returns the amount in minor units
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// Float64 returns the amount in major units. Use only for display and ratios.
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// String formats the amount in major units with two decimals, e.g. "12500.50"
+func (a Amount) String() string {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// MulFloat multiplies the amount by f (e.g. hours), rounding to the nearest minor unit
+func (a Amount) MulFloat(f float64) Amount {
+	return Amount(math.Round(float64(a) * f))
+}
+
+// Percent returns p percent of the amount, rounded to the nearest minor unit
+func (a Amount) Percent(p float64) Amount {
+	return a.MulFloat(p / 100)
+}
+
+// MarshalJSON encodes the amount as a JSON number in major units, e.g. 12500.50
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a quoted decimal string in major units
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Scan implements sql.Scanner for DECIMAL columns. NULL is scanned as zero;
+// use *Amount for nullable columns.
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	case int64:
+		*a = Amount(v * 100)
+	case float64:
+		*a = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanString(s string) error {
+	// SUM() по DECIMAL может вернуть больше двух знаков после запятой
+	if intPart, frac, ok := strings.Cut(s, "."); ok && len(frac) > 2 {
+		frac = strings.TrimRight(frac, "0")
+		if len(frac) > 2 {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return err
+			}
+			*a = FromFloat(f)
+			return nil
+		}
+		s = intPart
+		if frac != "" {
+			s += "." + frac
+		}
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value implements driver.Valuer, storing the amount as a decimal string
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// NormalizeCurrency validates an ISO 4217 code and returns it upper-cased.
+// An empty code yields DefaultCurrency.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	if len(code) != 3 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+		}
+	}
+	return code, nil
+}
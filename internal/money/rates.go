@@ -0,0 +1,43 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownRate = errors.New("no exchange rate for currency")
+
+// Rates converts amounts into a base currency using a fixed rate table
+type Rates struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewRates creates a rate table. rates maps a currency code to the number of
+// base currency units per one unit of that currency, e.g. {"USD": 470.5} for KZT.
+func NewRates(base string, rates map[string]float64) *Rates {
+	table := make(map[string]float64, len(rates)+1)
+	for code, rate := range rates {
+		table[code] = rate
+	}
+	table[base] = 1
+
+	return &Rates{
+		base:  base,
+		rates: table,
+	}
+}
+
+// Base returns the currency all amounts are converted into
+func (r *Rates) Base() string {
+	return r.base
+}
+
+// ToBase converts an amount in currency into the base currency
+func (r *Rates) ToBase(a Amount, currency string) (Amount, error) {
+	rate, ok := r.rates[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownRate, currency)
+	}
+	return a.MulFloat(rate), nil
+}
@@ -0,0 +1,192 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"smartbooking/internal/money"
+)
+
+// FakeProvider платежный провайдер, работающий в памяти процесса.
+// Используется для локальной разработки и тестов: вместо реального
+// клиента оплату подтверждает метод Simulate, который возвращает
+// подписанный вебхук так же, как это сделал бы внешний шлюз.
+type FakeProvider struct {
+	mu            sync.Mutex
+	webhookSecret string
+	intents       map[string]*Intent
+	idempotency   map[string]string
+	now           func() time.Time
+}
+
+// NewFakeProvider создает in-process провайдер с секретом для подписи вебхуков
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		webhookSecret: webhookSecret,
+		intents:       make(map[string]*Intent),
+		idempotency:   make(map[string]string),
+		now:           time.Now,
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateIntent(ctx context.Context, params IntentParams) (*Intent, error) {
+	if params.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if params.IdempotencyKey != "" {
+		if id, ok := p.idempotency[params.IdempotencyKey]; ok {
+			intent := *p.intents[id]
+			return &intent, nil
+		}
+	}
+
+	captureMethod := params.CaptureMethod
+	if captureMethod == "" {
+		captureMethod = CaptureAutomatic
+	}
+
+	id := "pi_fake_" + uuid.New().String()
+	intent := &Intent{
+		ID:            id,
+		Amount:        params.Amount,
+		Currency:      params.Currency,
+		Status:        StatusRequiresPayment,
+		CaptureMethod: captureMethod,
+		ClientSecret:  id + "_secret_" + uuid.New().String(),
+		Metadata:      params.Metadata,
+		CreatedAt:     p.now(),
+	}
+	p.intents[id] = intent
+	if params.IdempotencyKey != "" {
+		p.idempotency[params.IdempotencyKey] = id
+	}
+
+	result := *intent
+	return &result, nil
+}
+
+func (p *FakeProvider) CaptureIntent(ctx context.Context, intentID string, amount money.Amount) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != StatusRequiresCapture {
+		return nil, ErrInvalidState
+	}
+	if amount == 0 {
+		amount = intent.Amount
+	}
+	if amount < 0 || amount > intent.Amount {
+		return nil, ErrInvalidAmount
+	}
+
+	intent.AmountCaptured = amount
+	intent.Status = StatusSucceeded
+
+	result := *intent
+	return &result, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, intentID string, amount money.Amount) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != StatusSucceeded {
+		return nil, ErrInvalidState
+	}
+
+	refundable := intent.AmountCaptured - intent.AmountRefunded
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount <= 0 || amount > refundable {
+		return nil, ErrInvalidAmount
+	}
+
+	intent.AmountRefunded = intent.AmountRefunded + amount
+
+	return &Refund{
+		ID:        "re_fake_" + uuid.New().String(),
+		IntentID:  intentID,
+		Amount:    amount,
+		CreatedAt: p.now(),
+	}, nil
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, signatureHeader string) (*WebhookEvent, error) {
+	if err := VerifySignature(p.webhookSecret, payload, signatureHeader, p.now()); err != nil {
+		return nil, err
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return &event, nil
+}
+
+// Simulate имитирует действие покупателя: успешную или отклоненную оплату.
+// Возвращает тело вебхука и заголовок подписи для передачи в обработчик.
+func (p *FakeProvider) Simulate(intentID string, succeed bool) ([]byte, string, error) {
+	p.mu.Lock()
+	intent, ok := p.intents[intentID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, "", ErrIntentNotFound
+	}
+	if intent.Status != StatusRequiresPayment {
+		p.mu.Unlock()
+		return nil, "", ErrInvalidState
+	}
+
+	event := WebhookEvent{
+		ID:        "evt_fake_" + uuid.New().String(),
+		IntentID:  intent.ID,
+		Amount:    intent.Amount,
+		CreatedAt: p.now(),
+	}
+
+	switch {
+	case !succeed:
+		intent.Status = StatusFailed
+		event.Type = EventPaymentFailed
+		event.Reason = "card_declined"
+	case intent.CaptureMethod == CaptureManual:
+		intent.Status = StatusRequiresCapture
+		event.Type = EventPaymentAuthorized
+	default:
+		intent.Status = StatusSucceeded
+		intent.AmountCaptured = intent.Amount
+		event.Type = EventPaymentSucceeded
+	}
+	event.Status = intent.Status
+	p.mu.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, SignPayload(p.webhookSecret, payload, p.now()), nil
+}
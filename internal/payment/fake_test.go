@@ -0,0 +1,124 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smartbooking/internal/money"
+)
+
+// newTestProvider возвращает провайдер с управляемыми часами
+func newTestProvider() (*FakeProvider, *time.Time) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	p := NewFakeProvider("whsec_fake")
+	p.now = func() time.Time { return now }
+	return p, &now
+}
+
+func TestFakeProviderIdempotentIntent(t *testing.T) {
+	p, _ := newTestProvider()
+	ctx := context.Background()
+	params := IntentParams{Amount: money.FromMinor(10_000), Currency: "KZT", IdempotencyKey: "booking-1-installment-1-attempt-0"}
+
+	first, err := p.CreateIntent(ctx, params)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	again, err := p.CreateIntent(ctx, params)
+	if err != nil || again.ID != first.ID {
+		t.Errorf("repeated CreateIntent = %v, %v; want intent %s", again, err, first.ID)
+	}
+
+	params.IdempotencyKey = "booking-1-installment-1-attempt-1"
+	if other, _ := p.CreateIntent(ctx, params); other.ID == first.ID {
+		t.Error("new idempotency key returned the same intent")
+	}
+
+	if _, err := p.CreateIntent(ctx, IntentParams{Amount: 0}); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("CreateIntent with zero amount = %v, want ErrInvalidAmount", err)
+	}
+}
+
+func TestFakeProviderWebhook(t *testing.T) {
+	p, now := newTestProvider()
+	ctx := context.Background()
+
+	intent, _ := p.CreateIntent(ctx, IntentParams{Amount: money.FromMinor(10_000), Currency: "KZT"})
+	payload, signature, err := p.Simulate(intent.ID, true)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	event, err := p.ParseWebhook(payload, signature)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventPaymentSucceeded || event.IntentID != intent.ID || event.Amount != intent.Amount {
+		t.Errorf("event = %+v", event)
+	}
+
+	// Оплата уже прошла, второй раз ее не сымитировать
+	if _, _, err := p.Simulate(intent.ID, true); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("second Simulate = %v, want ErrInvalidState", err)
+	}
+
+	// Повтор того же вебхука в пределах допуска принимается (дубли отсекает
+	// обработчик), а записанный и переотправленный позже — нет
+	if _, err := p.ParseWebhook(payload, signature); err != nil {
+		t.Errorf("replay within tolerance: %v", err)
+	}
+	*now = now.Add(SignatureTolerance + time.Second)
+	if _, err := p.ParseWebhook(payload, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("replay after tolerance = %v, want ErrInvalidSignature", err)
+	}
+
+	if _, err := p.ParseWebhook([]byte(`{"id":"evt_forged"}`), signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("forged payload = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestFakeProviderCaptureAndRefund(t *testing.T) {
+	p, _ := newTestProvider()
+	ctx := context.Background()
+	amount := money.FromMinor(10_000)
+
+	intent, _ := p.CreateIntent(ctx, IntentParams{Amount: amount, Currency: "KZT", CaptureMethod: CaptureManual})
+	if _, err := p.Refund(ctx, intent.ID, 0); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("refund before capture = %v, want ErrInvalidState", err)
+	}
+
+	payload, signature, _ := p.Simulate(intent.ID, true)
+	if event, _ := p.ParseWebhook(payload, signature); event.Type != EventPaymentAuthorized {
+		t.Fatalf("manual capture intent sent %s, want %s", event.Type, EventPaymentAuthorized)
+	}
+	if _, err := p.CaptureIntent(ctx, intent.ID, 0); err != nil {
+		t.Fatalf("CaptureIntent: %v", err)
+	}
+
+	if _, err := p.Refund(ctx, intent.ID, money.FromMinor(6_000)); err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if _, err := p.Refund(ctx, intent.ID, money.FromMinor(6_000)); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("refund above the remainder = %v, want ErrInvalidAmount", err)
+	}
+	refund, err := p.Refund(ctx, intent.ID, 0)
+	if err != nil || refund.Amount != money.FromMinor(4_000) {
+		t.Errorf("refund of the remainder = %v, %v; want 40.00", refund, err)
+	}
+}
+
+func TestFakeProviderDeclined(t *testing.T) {
+	p, _ := newTestProvider()
+	intent, _ := p.CreateIntent(context.Background(), IntentParams{Amount: money.FromMinor(10_000)})
+
+	payload, signature, _ := p.Simulate(intent.ID, false)
+	event, err := p.ParseWebhook(payload, signature)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventPaymentFailed || event.Reason == "" {
+		t.Errorf("event = %+v, want a failed payment with a reason", event)
+	}
+}
@@ -0,0 +1,95 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"smartbooking/internal/money"
+)
+
+var (
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrInvalidState     = errors.New("payment intent is not in a valid state for this operation")
+	ErrInvalidAmount    = errors.New("invalid payment amount")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// IntentStatus статус платежного намерения у провайдера
+type IntentStatus string
+
+const (
+	StatusRequiresPayment IntentStatus = "requires_payment"
+	StatusRequiresCapture IntentStatus = "requires_capture"
+	StatusSucceeded       IntentStatus = "succeeded"
+	StatusFailed          IntentStatus = "failed"
+	StatusCanceled        IntentStatus = "canceled"
+)
+
+// CaptureMethod определяет, списываются ли средства сразу после авторизации
+type CaptureMethod string
+
+const (
+	CaptureAutomatic CaptureMethod = "automatic"
+	CaptureManual    CaptureMethod = "manual"
+)
+
+// Типы событий, которые провайдер присылает в вебхуках
+const (
+	EventPaymentAuthorized = "payment.authorized"
+	EventPaymentSucceeded  = "payment.succeeded"
+	EventPaymentFailed     = "payment.failed"
+	EventPaymentRefunded   = "payment.refunded"
+)
+
+// PaymentProvider абстракция над платежным шлюзом
+type PaymentProvider interface {
+	Name() string
+	CreateIntent(ctx context.Context, params IntentParams) (*Intent, error)
+	CaptureIntent(ctx context.Context, intentID string, amount money.Amount) (*Intent, error)
+	Refund(ctx context.Context, intentID string, amount money.Amount) (*Refund, error)
+	ParseWebhook(payload []byte, signatureHeader string) (*WebhookEvent, error)
+}
+
+// IntentParams параметры создания платежного намерения
+type IntentParams struct {
+	Amount         money.Amount
+	Currency       string
+	Description    string
+	CaptureMethod  CaptureMethod
+	IdempotencyKey string
+	Metadata       map[string]string
+}
+
+// Intent платежное намерение у провайдера
+type Intent struct {
+	ID             string            `json:"id"`
+	Amount         money.Amount      `json:"amount"`
+	AmountCaptured money.Amount      `json:"amount_captured"`
+	AmountRefunded money.Amount      `json:"amount_refunded"`
+	Currency       string            `json:"currency"`
+	Status         IntentStatus      `json:"status"`
+	CaptureMethod  CaptureMethod     `json:"capture_method"`
+	ClientSecret   string            `json:"client_secret,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// Refund возврат средств по платежу
+type Refund struct {
+	ID        string       `json:"id"`
+	IntentID  string       `json:"intent_id"`
+	Amount    money.Amount `json:"amount"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// WebhookEvent событие от провайдера о смене статуса платежа
+type WebhookEvent struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`
+	IntentID  string       `json:"intent_id"`
+	Status    IntentStatus `json:"status"`
+	Amount    money.Amount `json:"amount"`
+	Reason    string       `json:"reason,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
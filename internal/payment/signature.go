@@ -0,0 +1,23 @@
+package payment
+
+import (
+	"time"
+
+	"smartbooking/internal/signature"
+)
+
+// SignatureTolerance максимальный возраст подписи вебхука
+const SignatureTolerance = 5 * time.Minute
+
+// SignPayload формирует заголовок подписи вебхука провайдера, см. signature.Sign
+func SignPayload(secret string, payload []byte, timestamp time.Time) string {
+	return signature.Sign(secret, payload, timestamp)
+}
+
+// VerifySignature проверяет подпись и свежесть вебхука
+func VerifySignature(secret string, payload []byte, header string, now time.Time) error {
+	if err := signature.Verify(secret, payload, header, now, SignatureTolerance); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
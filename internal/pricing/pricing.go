@@ -0,0 +1,252 @@
+// Package pricing считает стоимость бронирования по тарифам ресурса и
+// проверяет часы работы. Окна тарифов и расписания заданы в местном времени
+// ресурса, поэтому интервал бронирования делится по границам местных суток и
+// окон, а длительность частей берется по реальному времени: в день перехода на
+// летнее время сутки длятся 23 часа, в день возврата — 25.
+package pricing
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+)
+
+// BaseRateName название позиции, посчитанной по базовой цене за час
+const BaseRateName = "Базовый тариф"
+
+const minutesPerDay = 24 * 60
+
+// window тариф с разобранным окном в минутах от местной полуночи
+type window struct {
+	rule     *models.PricingRule
+	from, to int
+	allDay   bool
+}
+
+// Calculate делит интервал [start, end) между тарифами и возвращает позиции
+// расчета и их сумму. Части интервала, не попавшие ни в один тариф, считаются
+// по базовой цене за час; если ее нет, они бесплатны. Тарифы сравниваются по
+// точности: тариф на конкретный день недели важнее тарифа на любой день, тариф
+// с окном времени важнее тарифа на весь день; при равенстве побеждает первый.
+func Calculate(rules []*models.PricingRule, base *money.Amount, loc *time.Location, start, end time.Time) ([]*models.PriceItem, money.Amount) {
+	windows := parseWindows(rules)
+
+	// Время по каждому тарифу в порядке первого появления; nil — базовая цена
+	var order []*models.PricingRule
+	elapsed := make(map[*models.PricingRule]time.Duration)
+
+	for day := startOfDay(start, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		cuts := dayCuts(day, windows, start, end)
+		for i := 0; i+1 < len(cuts); i++ {
+			from, to := cuts[i], cuts[i+1]
+			rule := match(windows, from.In(loc))
+			if _, seen := elapsed[rule]; !seen {
+				order = append(order, rule)
+			}
+			elapsed[rule] += to.Sub(from)
+		}
+	}
+
+	var items []*models.PriceItem
+	var total money.Amount
+	for _, rule := range order {
+		item := &models.PriceItem{Name: BaseRateName, UnitMinutes: 60}
+		if rule != nil {
+			item.PricingID = &rule.ID
+			item.Name = rule.Name
+			item.UnitMinutes = rule.DurationMinutes
+			item.UnitPrice = rule.Price
+		} else if base != nil {
+			item.UnitPrice = *base
+		} else {
+			continue
+		}
+
+		item.Quantity = roundQuantity(elapsed[rule].Minutes() / float64(item.UnitMinutes))
+		item.Amount = item.UnitPrice.MulFloat(item.Quantity)
+		items = append(items, item)
+		total += item.Amount
+	}
+
+	return items, total
+}
+
+// WithinSchedule проверяет, что интервал [start, end) целиком приходится на
+// часы работы. День без записи в расписании не ограничен, как и ресурс без
+// расписания вовсе.
+func WithinSchedule(schedules []*models.ResourceSchedule, loc *time.Location, start, end time.Time) bool {
+	if len(schedules) == 0 {
+		return true
+	}
+	byDay := make(map[int]*models.ResourceSchedule, len(schedules))
+	for _, sc := range schedules {
+		byDay[sc.DayOfWeek] = sc
+	}
+
+	for day := startOfDay(start, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		sc := byDay[int(day.Weekday())]
+		if sc == nil {
+			continue
+		}
+		if sc.IsClosed {
+			return false
+		}
+		open, okOpen := parseClock(sc.OpenTime, false)
+		closeAt, okClose := parseClock(sc.CloseTime, true)
+		if !okOpen || !okClose {
+			return false
+		}
+
+		from, to := maxTime(start, day), minTime(end, day.AddDate(0, 0, 1))
+		if from.Before(at(day, open)) || to.After(at(day, closeAt)) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseWindows(rules []*models.PricingRule) []window {
+	windows := make([]window, 0, len(rules))
+	for _, rule := range rules {
+		if rule.DurationMinutes <= 0 {
+			continue
+		}
+		w := window{rule: rule, allDay: rule.TimeFrom == nil && rule.TimeTo == nil}
+		if !w.allDay {
+			w.to = minutesPerDay
+			ok := true
+			if rule.TimeFrom != nil {
+				w.from, ok = parseClock(*rule.TimeFrom, false)
+			}
+			if ok && rule.TimeTo != nil {
+				w.to, ok = parseClock(*rule.TimeTo, true)
+			}
+			if !ok || w.from == w.to {
+				continue
+			}
+		}
+		windows = append(windows, w)
+	}
+	return windows
+}
+
+// dayCuts возвращает границы частей интервала внутри местных суток day:
+// начало и конец суток, границы окон тарифов и сам интервал
+func dayCuts(day time.Time, windows []window, start, end time.Time) []time.Time {
+	from, to := maxTime(start, day), minTime(end, day.AddDate(0, 0, 1))
+	cuts := []time.Time{from, to}
+	for _, w := range windows {
+		if w.allDay {
+			continue
+		}
+		for _, m := range []int{w.from, w.to} {
+			if t := at(day, m); t.After(from) && t.Before(to) {
+				cuts = append(cuts, t)
+			}
+		}
+	}
+
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+	unique := cuts[:1]
+	for _, t := range cuts[1:] {
+		if t.After(unique[len(unique)-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// match выбирает самый точный тариф для местного момента t или nil
+func match(windows []window, t time.Time) *models.PricingRule {
+	clock := t.Hour()*60 + t.Minute()
+	weekday := int(t.Weekday())
+
+	var best *models.PricingRule
+	bestScore := -1
+	for _, w := range windows {
+		if w.rule.DayOfWeek != nil && *w.rule.DayOfWeek != weekday {
+			continue
+		}
+		if !w.allDay && !w.contains(clock) {
+			continue
+		}
+
+		score := 0
+		if w.rule.DayOfWeek != nil {
+			score += 2
+		}
+		if !w.allDay {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = w.rule, score
+		}
+	}
+	return best
+}
+
+// contains учитывает окна через полночь, например 22:00–02:00
+func (w window) contains(clock int) bool {
+	if w.from < w.to {
+		return clock >= w.from && clock < w.to
+	}
+	return clock >= w.from || clock < w.to
+}
+
+// parseClock разбирает время HH:MM или HH:MM:SS в минуты от полуночи. Для
+// конца окна 23:59 и 24:00 означают конец суток: так конец дня записан в
+// расписании и тарифах.
+func parseClock(value string, end bool) (int, bool) {
+	if end && (value == "24:00" || value == "24:00:00") {
+		return minutesPerDay, true
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		t, err = time.Parse("15:04:05", value)
+	}
+	if err != nil {
+		return 0, false
+	}
+
+	m := t.Hour()*60 + t.Minute()
+	if end && m == minutesPerDay-1 {
+		return minutesPerDay, true
+	}
+	return m, true
+}
+
+// at момент, когда в местных сутках day наступает minutes минут от полуночи.
+// Конец суток — полночь следующего дня, а не day + 24 часа.
+func at(day time.Time, minutes int) time.Time {
+	if minutes >= minutesPerDay {
+		return day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// roundQuantity округляет количество до сотых, как оно хранится в счете
+func roundQuantity(q float64) float64 {
+	return math.Round(q*100) / 100
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,168 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+)
+
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load Europe/Berlin: %v", err)
+	}
+	return loc
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCalculateAcrossDST(t *testing.T) {
+	loc := berlin(t)
+	base := money.FromMinor(100_000) // 1000.00 в час
+
+	// Ночной тариф 00:00–06:00 по местному времени, днем базовая цена
+	night := []*models.PricingRule{{
+		ID: 1, Name: "Ночь", Price: money.FromMinor(50_000), DurationMinutes: 60,
+		TimeFrom: ptr("00:00"), TimeTo: ptr("06:00"),
+	}}
+
+	day := func(y int, m time.Month, d int) (time.Time, time.Time) {
+		start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 0, 1)
+	}
+
+	tests := []struct {
+		name      string
+		rules     []*models.PricingRule
+		year      int
+		month     time.Month
+		day       int
+		wantTotal money.Amount
+		wantHours []float64
+	}{
+		{"regular day, base rate", nil, 2026, time.March, 28, 2_400_000, []float64{24}},
+		{"spring forward is 23h", nil, 2026, time.March, 29, 2_300_000, []float64{23}},
+		{"fall back is 25h", nil, 2026, time.October, 25, 2_500_000, []float64{25}},
+		// 00:00–06:00 длится 5 часов весной и 7 часов осенью
+		{"regular day, night window", night, 2026, time.March, 28, 6*50_000 + 18*100_000, []float64{6, 18}},
+		{"spring forward, night window", night, 2026, time.March, 29, 5*50_000 + 18*100_000, []float64{5, 18}},
+		{"fall back, night window", night, 2026, time.October, 25, 7*50_000 + 18*100_000, []float64{7, 18}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := day(tt.year, tt.month, tt.day)
+			items, total := Calculate(tt.rules, &base, loc, start, end)
+
+			if total != tt.wantTotal {
+				t.Errorf("total = %s, want %s", total, tt.wantTotal)
+			}
+			if len(items) != len(tt.wantHours) {
+				t.Fatalf("got %d items, want %d", len(items), len(tt.wantHours))
+			}
+			var sum money.Amount
+			for i, item := range items {
+				if item.Quantity != tt.wantHours[i] {
+					t.Errorf("item %d (%s) quantity = %v, want %v", i, item.Name, item.Quantity, tt.wantHours[i])
+				}
+				if item.Amount != item.UnitPrice.MulFloat(item.Quantity) {
+					t.Errorf("item %d amount %s != %s × %v", i, item.Amount, item.UnitPrice, item.Quantity)
+				}
+				sum += item.Amount
+			}
+			if sum != total {
+				t.Errorf("items sum to %s, total is %s", sum, total)
+			}
+		})
+	}
+}
+
+func TestCalculateUsesLocalWeekday(t *testing.T) {
+	loc := berlin(t)
+	base := money.FromMinor(100_000)
+	rules := []*models.PricingRule{
+		{ID: 1, Name: "Будни", Price: money.FromMinor(100_000), DurationMinutes: 60},
+		{ID: 2, Name: "Воскресенье", Price: money.FromMinor(150_000), DurationMinutes: 60, DayOfWeek: ptr(int(time.Sunday))},
+	}
+
+	// Суббота 23:30 UTC — уже воскресенье 00:30 в Берлине
+	start := time.Date(2026, time.January, 10, 23, 30, 0, 0, time.UTC)
+	items, total := Calculate(rules, &base, loc, start, start.Add(2*time.Hour))
+
+	if len(items) != 1 || items[0].Name != "Воскресенье" {
+		t.Fatalf("items = %+v, want one Sunday item", items)
+	}
+	if total != 300_000 {
+		t.Errorf("total = %s, want 3000.00", total)
+	}
+}
+
+func TestCalculateWithoutBaseRate(t *testing.T) {
+	loc := berlin(t)
+	rules := []*models.PricingRule{{
+		ID: 1, Name: "Вечер", Price: money.FromMinor(60_000), DurationMinutes: 30,
+		TimeFrom: ptr("18:00"), TimeTo: ptr("23:59"),
+	}}
+
+	start := time.Date(2026, time.June, 1, 17, 0, 0, 0, loc)
+	items, total := Calculate(rules, nil, loc, start, start.Add(8*time.Hour))
+
+	// 17:00–18:00 без тарифа и базовой цены бесплатно; 18:00–24:00 — 12 получасов
+	if len(items) != 1 || items[0].Quantity != 12 {
+		t.Fatalf("items = %+v, want 12 half-hours of the evening rate", items)
+	}
+	if total != 12*60_000 {
+		t.Errorf("total = %s, want 7200.00", total)
+	}
+}
+
+func TestWithinSchedule(t *testing.T) {
+	loc := berlin(t)
+
+	weekly := make([]*models.ResourceSchedule, 0, 7)
+	for d := 0; d < 7; d++ {
+		weekly = append(weekly, &models.ResourceSchedule{DayOfWeek: d, OpenTime: "09:00", CloseTime: "18:00"})
+	}
+	allDay := make([]*models.ResourceSchedule, 0, 7)
+	for d := 0; d < 7; d++ {
+		allDay = append(allDay, &models.ResourceSchedule{DayOfWeek: d, OpenTime: "00:00", CloseTime: "23:59"})
+	}
+	closedSunday := []*models.ResourceSchedule{{DayOfWeek: int(time.Sunday), IsClosed: true}}
+
+	local := func(m time.Month, d, h int) time.Time {
+		return time.Date(2026, m, d, h, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name       string
+		schedules  []*models.ResourceSchedule
+		start, end time.Time
+		want       bool
+	}{
+		{"no schedule", nil, local(time.March, 28, 3), local(time.March, 28, 5), true},
+		{"inside opening hours", weekly, local(time.March, 28, 9), local(time.March, 28, 18), true},
+		{"opens later", weekly, local(time.March, 28, 8), local(time.March, 28, 10), false},
+		// Открытие в 09:00 по Берлину — это 08:00 UTC зимой и 07:00 UTC летом
+		{"winter UTC 08:00 is 09:00 local", weekly, time.Date(2026, time.March, 28, 8, 0, 0, 0, time.UTC), time.Date(2026, time.March, 28, 9, 0, 0, 0, time.UTC), true},
+		{"summer UTC 07:00 is 09:00 local", weekly, time.Date(2026, time.March, 30, 7, 0, 0, 0, time.UTC), time.Date(2026, time.March, 30, 8, 0, 0, 0, time.UTC), true},
+		{"summer UTC 06:00 is before opening", weekly, time.Date(2026, time.March, 30, 6, 0, 0, 0, time.UTC), time.Date(2026, time.March, 30, 7, 0, 0, 0, time.UTC), false},
+		{"whole 23h day", allDay, local(time.March, 29, 0), local(time.March, 30, 0), true},
+		{"whole 25h day", allDay, local(time.October, 25, 0), local(time.October, 26, 0), true},
+		{"overnight outside hours", weekly, local(time.March, 28, 17), local(time.March, 29, 10), false},
+		{"closed day", closedSunday, local(time.March, 29, 10), local(time.March, 29, 11), false},
+		{"day without schedule", closedSunday, local(time.March, 28, 10), local(time.March, 28, 11), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinSchedule(tt.schedules, loc, tt.start, tt.end); got != tt.want {
+				t.Errorf("WithinSchedule = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
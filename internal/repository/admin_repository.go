@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"smartbooking/internal/money"
 )
 
 // AdminRepository defines the interface for admin-specific statistics and operations
@@ -13,19 +15,36 @@ type AdminRepository interface {
 	GetResourcesByCategory(ctx context.Context) ([]CategoryResourceCount, error)
 	GetRevenueByMonth(ctx context.Context, months int) ([]MonthlyRevenue, error)
 	GetBookingsByDay(ctx context.Context, days int) ([]DailyBookings, error)
+	GetCouponUsage(ctx context.Context) ([]CouponUsage, error)
 }
 
 // AdminStatistics represents system-wide statistics
 type AdminStatistics struct {
-	TotalUsers         int     `json:"total_users"`
-	TotalResources     int     `json:"total_resources"`
-	TotalBookings      int     `json:"total_bookings"`
-	ActiveBookings     int     `json:"active_bookings"`
-	CancelledBookings  int     `json:"cancelled_bookings"`
-	TotalRevenue       float64 `json:"total_revenue"`
-	TotalReviews       int     `json:"total_reviews"`
-	AverageRating      float64 `json:"average_rating"`
-	TotalCategories    int     `json:"total_categories"`
+	TotalUsers        int     `json:"total_users"`
+	TotalResources    int     `json:"total_resources"`
+	TotalBookings     int     `json:"total_bookings"`
+	ActiveBookings    int     `json:"active_bookings"`
+	CancelledBookings int     `json:"cancelled_bookings"`
+	TotalReviews      int     `json:"total_reviews"`
+	AverageRating     float64 `json:"average_rating"`
+	TotalCategories   int     `json:"total_categories"`
+	TotalRedemptions  int     `json:"total_redemptions"`
+
+	// Суммы по каждой валюте и итоги, пересчитанные в базовую валюту Currency
+	RevenueByCurrency     []CurrencyRevenue `json:"revenue_by_currency"`
+	Currency              string            `json:"currency"`
+	TotalRevenue          money.Amount      `json:"total_revenue"`
+	TotalDiscounts        money.Amount      `json:"total_discounts"`
+	UnconvertedCurrencies []string          `json:"unconverted_currencies,omitempty"`
+}
+
+// CurrencyRevenue represents money totals in a single currency
+type CurrencyRevenue struct {
+	Currency    string       `json:"currency"`
+	Revenue     money.Amount `json:"revenue"`
+	Collected   money.Amount `json:"collected"`
+	Outstanding money.Amount `json:"outstanding"`
+	Discounts   money.Amount `json:"discounts"`
 }
 
 // BookingStatusCount represents booking count by status
@@ -40,10 +59,12 @@ type CategoryResourceCount struct {
 	Count        int    `json:"count"`
 }
 
-// MonthlyRevenue represents revenue aggregated by month
+// MonthlyRevenue represents revenue aggregated by month. Revenue is converted
+// into the base currency; ByCurrency keeps the original amounts.
 type MonthlyRevenue struct {
-	Month   string  `json:"month"`
-	Revenue float64 `json:"revenue"`
+	Month      string                  `json:"month"`
+	Revenue    money.Amount            `json:"revenue"`
+	ByCurrency map[string]money.Amount `json:"by_currency,omitempty"`
 }
 
 // DailyBookings represents booking count aggregated by day
@@ -52,6 +73,15 @@ type DailyBookings struct {
 	Count int    `json:"count"`
 }
 
+// CouponUsage represents redemption count and discount total per coupon
+type CouponUsage struct {
+	CouponID           int64                   `json:"coupon_id"`
+	Code               string                  `json:"code"`
+	Redemptions        int                     `json:"redemptions"`
+	TotalDiscount      money.Amount            `json:"total_discount"`
+	DiscountByCurrency map[string]money.Amount `json:"discount_by_currency,omitempty"`
+}
+
 // adminRepository implements AdminRepository interface with PostgreSQL storage
 type adminRepository struct {
 	db *sql.DB
@@ -76,10 +106,12 @@ func (r *adminRepository) GetSystemStatistics(ctx context.Context) (*AdminStatis
 			(SELECT COUNT(*) FROM bookings) as total_bookings,
 			(SELECT COUNT(*) FROM bookings WHERE status IN ('pending', 'confirmed')) as active_bookings,
 			(SELECT COUNT(*) FROM bookings WHERE status = 'cancelled') as cancelled_bookings,
-			(SELECT COALESCE(SUM(total_price), 0) FROM bookings WHERE status IN ('pending', 'confirmed')) as total_revenue,
 			(SELECT COUNT(*) FROM reviews) as total_reviews,
-			(SELECT COALESCE(AVG(rating), 0) FROM reviews) as average_rating,
-			(SELECT COUNT(*) FROM resource_categories) as total_categories
+			(SELECT COALESCE(SUM(rating_sum)::float / NULLIF(SUM(review_count), 0), 0) FROM resource_rating_stats) as average_rating,
+			(SELECT COUNT(*) FROM resource_categories) as total_categories,
+			(SELECT COUNT(*) FROM coupon_redemptions cr
+				INNER JOIN bookings b ON cr.booking_id = b.id
+				WHERE b.status IN ('pending', 'confirmed')) as total_redemptions
 	`
 
 	err := r.db.QueryRowContext(ctx, query).Scan(
@@ -88,15 +120,34 @@ func (r *adminRepository) GetSystemStatistics(ctx context.Context) (*AdminStatis
 		&stats.TotalBookings,
 		&stats.ActiveBookings,
 		&stats.CancelledBookings,
-		&stats.TotalRevenue,
 		&stats.TotalReviews,
 		&stats.AverageRating,
 		&stats.TotalCategories,
+		&stats.TotalRedemptions,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Денежные итоги считаем отдельно по каждой валюте
+	revenueQuery := `
+		SELECT
+			b.currency,
+			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN b.total_price ELSE 0 END), 0) as revenue,
+			COALESCE(SUM(b.amount_paid), 0) as collected,
+			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed')
+				THEN GREATEST(b.total_price - b.amount_paid, 0) ELSE 0 END), 0) as outstanding,
+			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN b.discount_amount ELSE 0 END), 0) as discounts
+		FROM bookings b
+		GROUP BY b.currency
+		ORDER BY b.currency
+	`
+
+	stats.RevenueByCurrency, err = queryCurrencyRevenue(ctx, r.db, revenueQuery)
+	if err != nil {
+		return nil, err
+	}
+
 	return stats, nil
 }
 
@@ -157,17 +208,20 @@ func (r *adminRepository) GetResourcesByCategory(ctx context.Context) ([]Categor
 	return result, rows.Err()
 }
 
-// GetRevenueByMonth retrieves revenue aggregated by month for the last N months
+// GetRevenueByMonth retrieves revenue aggregated by month for the last N months.
+// Бронирование относится к месяцу по местному времени своего ресурса.
 func (r *adminRepository) GetRevenueByMonth(ctx context.Context, months int) ([]MonthlyRevenue, error) {
 	query := `
 		SELECT
-			TO_CHAR(DATE_TRUNC('month', created_at), 'Mon YYYY') as month,
-			COALESCE(SUM(total_price), 0) as revenue
-		FROM bookings
-		WHERE status IN ('pending', 'confirmed')
-			AND created_at >= NOW() - INTERVAL '1 month' * $1
-		GROUP BY DATE_TRUNC('month', created_at)
-		ORDER BY DATE_TRUNC('month', created_at)
+			TO_CHAR(DATE_TRUNC('month', b.created_at AT TIME ZONE r.timezone), 'Mon YYYY') as month,
+			b.currency,
+			COALESCE(SUM(b.total_price), 0) as revenue
+		FROM bookings b
+		INNER JOIN resources r ON b.resource_id = r.id
+		WHERE b.status IN ('pending', 'confirmed')
+			AND b.created_at >= NOW() - INTERVAL '1 month' * $1
+		GROUP BY DATE_TRUNC('month', b.created_at AT TIME ZONE r.timezone), b.currency
+		ORDER BY DATE_TRUNC('month', b.created_at AT TIME ZONE r.timezone), b.currency
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, months)
@@ -178,11 +232,15 @@ func (r *adminRepository) GetRevenueByMonth(ctx context.Context, months int) ([]
 
 	var result []MonthlyRevenue
 	for rows.Next() {
-		var item MonthlyRevenue
-		if err := rows.Scan(&item.Month, &item.Revenue); err != nil {
+		var month, currency string
+		var revenue money.Amount
+		if err := rows.Scan(&month, &currency, &revenue); err != nil {
 			return nil, err
 		}
-		result = append(result, item)
+		if len(result) == 0 || result[len(result)-1].Month != month {
+			result = append(result, MonthlyRevenue{Month: month, ByCurrency: map[string]money.Amount{}})
+		}
+		result[len(result)-1].ByCurrency[currency] = revenue
 	}
 
 	// If no data, fill with zeros for the last N months
@@ -200,16 +258,18 @@ func (r *adminRepository) GetRevenueByMonth(ctx context.Context, months int) ([]
 	return result, rows.Err()
 }
 
-// GetBookingsByDay retrieves booking count aggregated by day for the last N days
+// GetBookingsByDay retrieves booking count aggregated by day for the last N days.
+// Бронирование относится к дню по местному времени своего ресурса.
 func (r *adminRepository) GetBookingsByDay(ctx context.Context, days int) ([]DailyBookings, error) {
 	query := `
 		SELECT
-			TO_CHAR(DATE_TRUNC('day', created_at), 'Mon DD') as day,
+			TO_CHAR(DATE_TRUNC('day', b.created_at AT TIME ZONE r.timezone), 'Mon DD') as day,
 			COUNT(*) as count
-		FROM bookings
-		WHERE created_at >= NOW() - INTERVAL '1 day' * $1
-		GROUP BY DATE_TRUNC('day', created_at)
-		ORDER BY DATE_TRUNC('day', created_at)
+		FROM bookings b
+		INNER JOIN resources r ON b.resource_id = r.id
+		WHERE b.created_at >= NOW() - INTERVAL '1 day' * $1
+		GROUP BY DATE_TRUNC('day', b.created_at AT TIME ZONE r.timezone)
+		ORDER BY DATE_TRUNC('day', b.created_at AT TIME ZONE r.timezone)
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, days)
@@ -241,3 +301,71 @@ func (r *adminRepository) GetBookingsByDay(ctx context.Context, days int) ([]Dai
 
 	return result, rows.Err()
 }
+
+// GetCouponUsage retrieves redemption count and discount total for every coupon.
+// Redemptions of cancelled bookings are not counted.
+func (r *adminRepository) GetCouponUsage(ctx context.Context) ([]CouponUsage, error) {
+	query := `
+		SELECT
+			c.id,
+			c.code,
+			b.currency,
+			COUNT(b.id) as redemptions,
+			COALESCE(SUM(cr.discount_amount) FILTER (WHERE b.id IS NOT NULL), 0) as total_discount
+		FROM coupons c
+		LEFT JOIN coupon_redemptions cr ON cr.coupon_id = c.id
+		LEFT JOIN bookings b ON cr.booking_id = b.id AND b.status IN ('pending', 'confirmed')
+		GROUP BY c.id, c.code, b.currency
+		ORDER BY c.code, b.currency
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Строки приходят по паре (купон, валюта); собираем их в одну запись на купон
+	var result []CouponUsage
+	for rows.Next() {
+		var couponID int64
+		var code string
+		var currency sql.NullString
+		var redemptions int
+		var discount money.Amount
+		if err := rows.Scan(&couponID, &code, &currency, &redemptions, &discount); err != nil {
+			return nil, err
+		}
+		if len(result) == 0 || result[len(result)-1].CouponID != couponID {
+			result = append(result, CouponUsage{CouponID: couponID, Code: code, DiscountByCurrency: map[string]money.Amount{}})
+		}
+		item := &result[len(result)-1]
+		item.Redemptions += redemptions
+		if currency.Valid {
+			item.DiscountByCurrency[currency.String] += discount
+		}
+	}
+
+	return result, rows.Err()
+}
+
+// queryCurrencyRevenue выполняет запрос, возвращающий строки
+// (currency, revenue, collected, outstanding, discounts)
+func queryCurrencyRevenue(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]CurrencyRevenue, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]CurrencyRevenue, 0)
+	for rows.Next() {
+		var item CurrencyRevenue
+		if err := rows.Scan(&item.Currency, &item.Revenue, &item.Collected, &item.Outstanding, &item.Discounts); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
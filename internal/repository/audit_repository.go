@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"smartbooking/internal/models"
+)
+
+// AuditRepository defines the interface for the audit log
+type AuditRepository interface {
+	Append(ctx context.Context, entry *models.AuditLog) error
+	List(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error)
+}
+
+// auditRepository implements AuditRepository interface with PostgreSQL storage
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new instance of AuditRepository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// Append записывает запись журнала; внутри Transactor.WithinTx — в той же транзакции, что и изменение
+func (r *auditRepository) Append(ctx context.Context, entry *models.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (user_id, action, entity_type, entity_id, old_value, new_value, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::inet, $8, $9)
+		RETURNING id
+	`
+
+	entry.CreatedAt = time.Now()
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		entry.UserID,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		nullJSON(entry.OldValue),
+		nullJSON(entry.NewValue),
+		nullString(entry.IPAddress),
+		nullString(entry.UserAgent),
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// List возвращает записи журнала по фильтру, новые первыми. Пустые поля фильтра не ограничивают выборку.
+func (r *auditRepository) List(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error) {
+	query := `
+		SELECT id, user_id, action, entity_type, entity_id, old_value, new_value,
+			host(ip_address), user_agent, created_at
+		FROM audit_logs
+		WHERE ($1::text IS NULL OR entity_type = $1)
+			AND ($2::int IS NULL OR entity_id = $2)
+			AND ($3::int IS NULL OR user_id = $3)
+			AND ($4::text IS NULL OR action = $4)
+			AND ($5::timestamptz IS NULL OR created_at >= $5)
+			AND ($6::timestamptz IS NULL OR created_at < $6)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		nullString(filter.EntityType),
+		filter.EntityID,
+		filter.UserID,
+		nullString(filter.Action),
+		filter.From,
+		filter.To,
+		filter.Limit,
+		filter.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.AuditLog, 0)
+	for rows.Next() {
+		var e models.AuditLog
+		var userID, entityID sql.NullInt64
+		var oldValue, newValue []byte
+		var ip, userAgent sql.NullString
+
+		if err := rows.Scan(&e.ID, &userID, &e.Action, &e.EntityType, &entityID,
+			&oldValue, &newValue, &ip, &userAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		e.UserID = models.NullInt64ToPtr(userID)
+		e.EntityID = models.NullInt64ToPtr(entityID)
+		e.OldValue = oldValue
+		e.NewValue = newValue
+		e.IPAddress = ip.String
+		e.UserAgent = userAgent.String
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// nullJSON передает пустой JSON как NULL
+func nullJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
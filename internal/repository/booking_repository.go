@@ -23,8 +23,12 @@ type BookingRepository interface {
 	ListByResource(ctx context.Context, resourceID int64) ([]*models.Booking, error)
 	ListAll(ctx context.Context) ([]*models.Booking, error)
 	CheckOverlap(ctx context.Context, resourceID int64, startTime, endTime time.Time) (bool, error)
+	GetPriceItems(ctx context.Context, bookingID int64) ([]*models.PriceItem, error)
 }
 
+const bookingColumns = `id, user_id, resource_id, start_time, end_time, status,
+		total_price, currency, discount_amount, amount_paid, notes, created_at, updated_at`
+
 // bookingRepository implements BookingRepository interface with PostgreSQL storage
 type bookingRepository struct {
 	db *sql.DB
@@ -37,10 +41,18 @@ func NewBookingRepository(db *sql.DB) BookingRepository {
 	}
 }
 
+// Create сохраняет бронирование вместе с расчетом цены (PriceItems)
 func (r *bookingRepository) Create(ctx context.Context, booking *models.Booking) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO bookings (user_id, resource_id, start_time, end_time, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO bookings (user_id, resource_id, start_time, end_time, status, total_price, currency,
+			discount_amount, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 
@@ -48,12 +60,16 @@ func (r *bookingRepository) Create(ctx context.Context, booking *models.Booking)
 	booking.CreatedAt = now
 	booking.UpdatedAt = now
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		booking.UserID,
 		booking.ResourceID,
 		booking.StartTime,
 		booking.EndTime,
 		booking.Status,
+		booking.TotalPrice,
+		booking.Currency,
+		booking.DiscountAmount,
+		booking.Notes,
 		booking.CreatedAt,
 		booking.UpdatedAt,
 	).Scan(&booking.ID)
@@ -62,36 +78,70 @@ func (r *bookingRepository) Create(ctx context.Context, booking *models.Booking)
 		return err
 	}
 
-	return nil
+	itemQuery := `
+		INSERT INTO booking_price_items (booking_id, position, pricing_id, name, unit_minutes,
+			quantity, unit_price, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for i, item := range booking.PriceItems {
+		_, err := tx.ExecContext(ctx, itemQuery,
+			booking.ID,
+			i+1,
+			item.PricingID,
+			item.Name,
+			item.UnitMinutes,
+			item.Quantity,
+			item.UnitPrice,
+			item.Amount,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (r *bookingRepository) GetByID(ctx context.Context, id int64) (*models.Booking, error) {
+// GetPriceItems returns the price breakdown saved with a booking; empty for
+// bookings created before pricing rules were applied
+func (r *bookingRepository) GetPriceItems(ctx context.Context, bookingID int64) ([]*models.PriceItem, error) {
 	query := `
-		SELECT id, user_id, resource_id, start_time, end_time, status, created_at, updated_at
-		FROM bookings
-		WHERE id = $1
+		SELECT pricing_id, name, unit_minutes, quantity, unit_price, amount
+		FROM booking_price_items
+		WHERE booking_id = $1
+		ORDER BY position
 	`
 
-	booking := &models.Booking{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&booking.ID,
-		&booking.UserID,
-		&booking.ResourceID,
-		&booking.StartTime,
-		&booking.EndTime,
-		&booking.Status,
-		&booking.CreatedAt,
-		&booking.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, ErrBookingNotFound
-	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, bookingID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return booking, nil
+	items := make([]*models.PriceItem, 0)
+	for rows.Next() {
+		item := &models.PriceItem{}
+		var pricingID sql.NullInt64
+		err := rows.Scan(&pricingID, &item.Name, &item.UnitMinutes, &item.Quantity, &item.UnitPrice, &item.Amount)
+		if err != nil {
+			return nil, err
+		}
+		item.PricingID = models.NullInt64ToPtr(pricingID)
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *bookingRepository) GetByID(ctx context.Context, id int64) (*models.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE id = $1
+	`
+
+	return scanBooking(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 }
 
 func (r *bookingRepository) Update(ctx context.Context, booking *models.Booking) error {
@@ -103,7 +153,7 @@ func (r *bookingRepository) Update(ctx context.Context, booking *models.Booking)
 
 	booking.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
 		booking.UserID,
 		booking.ResourceID,
 		booking.StartTime,
@@ -132,7 +182,7 @@ func (r *bookingRepository) Update(ctx context.Context, booking *models.Booking)
 func (r *bookingRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM bookings WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -151,7 +201,7 @@ func (r *bookingRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *bookingRepository) ListByUser(ctx context.Context, userID int64) ([]*models.Booking, error) {
 	query := `
-		SELECT id, user_id, resource_id, start_time, end_time, status, created_at, updated_at
+		SELECT ` + bookingColumns + `
 		FROM bookings
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -165,17 +215,7 @@ func (r *bookingRepository) ListByUser(ctx context.Context, userID int64) ([]*mo
 
 	bookings := make([]*models.Booking, 0)
 	for rows.Next() {
-		booking := &models.Booking{}
-		err := rows.Scan(
-			&booking.ID,
-			&booking.UserID,
-			&booking.ResourceID,
-			&booking.StartTime,
-			&booking.EndTime,
-			&booking.Status,
-			&booking.CreatedAt,
-			&booking.UpdatedAt,
-		)
+		booking, err := scanBooking(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -191,7 +231,7 @@ func (r *bookingRepository) ListByUser(ctx context.Context, userID int64) ([]*mo
 
 func (r *bookingRepository) ListByResource(ctx context.Context, resourceID int64) ([]*models.Booking, error) {
 	query := `
-		SELECT id, user_id, resource_id, start_time, end_time, status, created_at, updated_at
+		SELECT ` + bookingColumns + `
 		FROM bookings
 		WHERE resource_id = $1
 		ORDER BY created_at DESC
@@ -205,17 +245,7 @@ func (r *bookingRepository) ListByResource(ctx context.Context, resourceID int64
 
 	bookings := make([]*models.Booking, 0)
 	for rows.Next() {
-		booking := &models.Booking{}
-		err := rows.Scan(
-			&booking.ID,
-			&booking.UserID,
-			&booking.ResourceID,
-			&booking.StartTime,
-			&booking.EndTime,
-			&booking.Status,
-			&booking.CreatedAt,
-			&booking.UpdatedAt,
-		)
+		booking, err := scanBooking(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -231,7 +261,7 @@ func (r *bookingRepository) ListByResource(ctx context.Context, resourceID int64
 
 func (r *bookingRepository) ListAll(ctx context.Context) ([]*models.Booking, error) {
 	query := `
-		SELECT id, user_id, resource_id, start_time, end_time, status, created_at, updated_at
+		SELECT ` + bookingColumns + `
 		FROM bookings
 		ORDER BY created_at DESC
 	`
@@ -244,17 +274,7 @@ func (r *bookingRepository) ListAll(ctx context.Context) ([]*models.Booking, err
 
 	bookings := make([]*models.Booking, 0)
 	for rows.Next() {
-		booking := &models.Booking{}
-		err := rows.Scan(
-			&booking.ID,
-			&booking.UserID,
-			&booking.ResourceID,
-			&booking.StartTime,
-			&booking.EndTime,
-			&booking.Status,
-			&booking.CreatedAt,
-			&booking.UpdatedAt,
-		)
+		booking, err := scanBooking(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -268,21 +288,64 @@ func (r *bookingRepository) ListAll(ctx context.Context) ([]*models.Booking, err
 	return bookings, nil
 }
 
+// CheckOverlap сообщает, занят ли ресурс в интервале: активным бронированием
+// или блокировкой из внешнего календаря
 func (r *bookingRepository) CheckOverlap(ctx context.Context, resourceID int64, startTime, endTime time.Time) (bool, error) {
 	query := `
-		SELECT COUNT(*)
-		FROM bookings
-		WHERE resource_id = $1
-			AND status != 'cancelled'
-			AND start_time < $3
-			AND end_time > $2
+		SELECT EXISTS (
+			SELECT 1 FROM bookings
+			WHERE resource_id = $1
+				AND status != 'cancelled'
+				AND start_time < $3
+				AND end_time > $2
+		) OR EXISTS (
+			SELECT 1 FROM resource_blocks
+			WHERE resource_id = $1
+				AND start_time < $3
+				AND end_time > $2
+		)
 	`
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, resourceID, startTime, endTime).Scan(&count)
+	var overlaps bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, resourceID, startTime, endTime).Scan(&overlaps)
 	if err != nil {
 		return false, err
 	}
 
-	return count > 0, nil
+	return overlaps, nil
+}
+
+func scanBooking(row rowScanner) (*models.Booking, error) {
+	booking := &models.Booking{}
+	var notes sql.NullString
+
+	err := row.Scan(
+		&booking.ID,
+		&booking.UserID,
+		&booking.ResourceID,
+		&booking.StartTime,
+		&booking.EndTime,
+		&booking.Status,
+		&booking.TotalPrice,
+		&booking.Currency,
+		&booking.DiscountAmount,
+		&booking.AmountPaid,
+		&notes,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if notes.Valid {
+		booking.Notes = notes.String
+	}
+	booking.Balance = booking.TotalPrice - booking.AmountPaid
+
+	return booking, nil
 }
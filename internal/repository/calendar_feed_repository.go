@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Области действия токенов календарных лент
+const (
+	CalendarFeedUser     = "user"
+	CalendarFeedResource = "resource"
+)
+
+// CalendarFeedRepository defines the interface for calendar feed tokens
+type CalendarFeedRepository interface {
+	SetToken(ctx context.Context, scope string, entityID int64, tokenHash string) error
+	HasToken(ctx context.Context, scope string, entityID int64, tokenHash string) (bool, error)
+}
+
+// calendarFeedRepository implements CalendarFeedRepository interface with PostgreSQL storage
+type calendarFeedRepository struct {
+	db *sql.DB
+}
+
+// NewCalendarFeedRepository creates a new instance of CalendarFeedRepository
+func NewCalendarFeedRepository(db *sql.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{
+		db: db,
+	}
+}
+
+// SetToken сохраняет токен ленты, заменяя прежний; старые ссылки перестают работать
+func (r *calendarFeedRepository) SetToken(ctx context.Context, scope string, entityID int64, tokenHash string) error {
+	query := `
+		INSERT INTO calendar_feed_tokens (scope, entity_id, token_hash, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (scope, entity_id)
+		DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, scope, entityID, tokenHash)
+	return err
+}
+
+func (r *calendarFeedRepository) HasToken(ctx context.Context, scope string, entityID int64, tokenHash string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM calendar_feed_tokens
+			WHERE scope = $1 AND entity_id = $2 AND token_hash = $3
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, scope, entityID, tokenHash).Scan(&exists)
+	return exists, err
+}
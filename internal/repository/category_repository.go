@@ -37,7 +37,7 @@ func (r *categoryRepository) Create(ctx context.Context, category *models.Resour
 		RETURNING id
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		category.Name,
 		category.Slug,
 		category.Description,
@@ -56,7 +56,7 @@ func (r *categoryRepository) GetByID(ctx context.Context, id int64) (*models.Res
 
 	category := &models.ResourceCategory{}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&category.ID,
 		&category.Name,
 		&category.Slug,
@@ -81,7 +81,7 @@ func (r *categoryRepository) List(ctx context.Context) ([]*models.ResourceCatego
 		ORDER BY name ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +115,7 @@ func (r *categoryRepository) Update(ctx context.Context, category *models.Resour
 		WHERE id = $5
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
 		category.Name,
 		category.Slug,
 		category.Description,
@@ -142,7 +142,7 @@ func (r *categoryRepository) Update(ctx context.Context, category *models.Resour
 func (r *categoryRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM resource_categories WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
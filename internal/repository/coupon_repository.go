@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"smartbooking/internal/models"
+)
+
+var (
+	ErrCouponNotFound   = errors.New("coupon not found")
+	ErrCouponUsageLimit = errors.New("coupon usage limit reached")
+	ErrCouponCodeExists = errors.New("coupon code already exists")
+)
+
+// CouponRepository defines the interface for coupon data operations
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *models.Coupon) error
+	GetByID(ctx context.Context, id int64) (*models.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*models.Coupon, error)
+	List(ctx context.Context) ([]*models.Coupon, error)
+	Update(ctx context.Context, coupon *models.Coupon) error
+	Delete(ctx context.Context, id int64) error
+	CountUserRedemptions(ctx context.Context, couponID, userID int64) (int, error)
+	Redeem(ctx context.Context, coupon *models.Coupon, redemption *models.CouponRedemption) error
+}
+
+// couponRepository implements CouponRepository interface with PostgreSQL storage
+type couponRepository struct {
+	db *sql.DB
+}
+
+// NewCouponRepository creates a new instance of CouponRepository
+func NewCouponRepository(db *sql.DB) CouponRepository {
+	return &couponRepository{
+		db: db,
+	}
+}
+
+const couponColumns = `
+	c.id, c.code, c.description, c.discount_type, c.discount_value, c.currency,
+	c.valid_from, c.valid_until, c.max_uses, c.max_uses_per_user, c.is_active,
+	c.created_at, c.updated_at,
+	ARRAY(SELECT category_id FROM coupon_categories WHERE coupon_id = c.id ORDER BY category_id) as category_ids,
+	ARRAY(SELECT resource_id FROM coupon_resources WHERE coupon_id = c.id ORDER BY resource_id) as resource_ids,
+	(SELECT COUNT(*) FROM coupon_redemptions cr
+		INNER JOIN bookings b ON cr.booking_id = b.id
+		WHERE cr.coupon_id = c.id AND b.status != 'cancelled') as times_used
+`
+
+func (r *couponRepository) Create(ctx context.Context, coupon *models.Coupon) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO coupons (code, description, discount_type, discount_value, currency, valid_from, valid_until,
+			max_uses, max_uses_per_user, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+
+	now := time.Now()
+	coupon.CreatedAt = now
+	coupon.UpdatedAt = now
+
+	err = tx.QueryRowContext(ctx, query,
+		coupon.Code,
+		coupon.Description,
+		coupon.DiscountType,
+		coupon.DiscountValue,
+		nullString(coupon.Currency),
+		coupon.ValidFrom,
+		coupon.ValidUntil,
+		coupon.MaxUses,
+		coupon.MaxUsesPerUser,
+		coupon.IsActive,
+		coupon.CreatedAt,
+		coupon.UpdatedAt,
+	).Scan(&coupon.ID)
+	if err != nil {
+		return mapCouponError(err)
+	}
+
+	if err := replaceCouponRestrictions(ctx, tx, coupon); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *couponRepository) GetByID(ctx context.Context, id int64) (*models.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.id = $1`
+	return scanCoupon(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *couponRepository) GetByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE UPPER(c.code) = UPPER($1)`
+	return scanCoupon(r.db.QueryRowContext(ctx, query, strings.TrimSpace(code)))
+}
+
+func (r *couponRepository) List(ctx context.Context) ([]*models.Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c ORDER BY c.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons := make([]*models.Coupon, 0)
+	for rows.Next() {
+		coupon, err := scanCoupon(rows)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, coupon)
+	}
+
+	return coupons, rows.Err()
+}
+
+func (r *couponRepository) Update(ctx context.Context, coupon *models.Coupon) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE coupons
+		SET code = $1, description = $2, discount_type = $3, discount_value = $4, currency = $5,
+			valid_from = $6, valid_until = $7, max_uses = $8, max_uses_per_user = $9, is_active = $10,
+			updated_at = $11
+		WHERE id = $12
+	`
+
+	coupon.UpdatedAt = time.Now()
+
+	result, err := tx.ExecContext(ctx, query,
+		coupon.Code,
+		coupon.Description,
+		coupon.DiscountType,
+		coupon.DiscountValue,
+		nullString(coupon.Currency),
+		coupon.ValidFrom,
+		coupon.ValidUntil,
+		coupon.MaxUses,
+		coupon.MaxUsesPerUser,
+		coupon.IsActive,
+		coupon.UpdatedAt,
+		coupon.ID,
+	)
+	if err != nil {
+		return mapCouponError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCouponNotFound
+	}
+
+	if err := replaceCouponRestrictions(ctx, tx, coupon); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *couponRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM coupons WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrCouponNotFound
+	}
+
+	return nil
+}
+
+func (r *couponRepository) CountUserRedemptions(ctx context.Context, couponID, userID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM coupon_redemptions cr
+		INNER JOIN bookings b ON cr.booking_id = b.id
+		WHERE cr.coupon_id = $1 AND cr.user_id = $2 AND b.status != 'cancelled'
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, couponID, userID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Redeem атомарно проверяет лимиты купона и записывает его применение.
+// Строка купона блокируется, чтобы параллельные бронирования не превысили лимит.
+func (r *couponRepository) Redeem(ctx context.Context, coupon *models.Coupon, redemption *models.CouponRedemption) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var locked int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM coupons WHERE id = $1 FOR UPDATE`, coupon.ID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return ErrCouponNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var totalUses, userUses int
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE cr.user_id = $2)
+		FROM coupon_redemptions cr
+		INNER JOIN bookings b ON cr.booking_id = b.id
+		WHERE cr.coupon_id = $1 AND b.status != 'cancelled'
+	`, coupon.ID, redemption.UserID).Scan(&totalUses, &userUses)
+	if err != nil {
+		return err
+	}
+
+	if coupon.MaxUses != nil && totalUses >= *coupon.MaxUses {
+		return ErrCouponUsageLimit
+	}
+	if coupon.MaxUsesPerUser != nil && userUses >= *coupon.MaxUsesPerUser {
+		return ErrCouponUsageLimit
+	}
+
+	redemption.CouponID = coupon.ID
+	redemption.CreatedAt = time.Now()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO coupon_redemptions (coupon_id, user_id, booking_id, discount_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`,
+		redemption.CouponID,
+		redemption.UserID,
+		redemption.BookingID,
+		redemption.DiscountAmount,
+		redemption.CreatedAt,
+	).Scan(&redemption.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaceCouponRestrictions перезаписывает списки категорий и ресурсов купона
+func replaceCouponRestrictions(ctx context.Context, tx *sql.Tx, coupon *models.Coupon) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM coupon_categories WHERE coupon_id = $1`, coupon.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM coupon_resources WHERE coupon_id = $1`, coupon.ID); err != nil {
+		return err
+	}
+
+	for _, categoryID := range coupon.CategoryIDs {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO coupon_categories (coupon_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			coupon.ID, categoryID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, resourceID := range coupon.ResourceIDs {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO coupon_resources (coupon_id, resource_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			coupon.ID, resourceID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCoupon(row rowScanner) (*models.Coupon, error) {
+	coupon := &models.Coupon{}
+	var description, currency sql.NullString
+	var validFrom, validUntil sql.NullTime
+	var maxUses, maxUsesPerUser sql.NullInt64
+	var categoryIDs, resourceIDs pq.Int64Array
+
+	err := row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&description,
+		&coupon.DiscountType,
+		&coupon.DiscountValue,
+		&currency,
+		&validFrom,
+		&validUntil,
+		&maxUses,
+		&maxUsesPerUser,
+		&coupon.IsActive,
+		&coupon.CreatedAt,
+		&coupon.UpdatedAt,
+		&categoryIDs,
+		&resourceIDs,
+		&coupon.TimesUsed,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrCouponNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		coupon.Description = description.String
+	}
+	if currency.Valid {
+		coupon.Currency = currency.String
+	}
+	if validFrom.Valid {
+		coupon.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		coupon.ValidUntil = &validUntil.Time
+	}
+	if maxUses.Valid {
+		v := int(maxUses.Int64)
+		coupon.MaxUses = &v
+	}
+	if maxUsesPerUser.Valid {
+		v := int(maxUsesPerUser.Int64)
+		coupon.MaxUsesPerUser = &v
+	}
+	coupon.CategoryIDs = []int64(categoryIDs)
+	coupon.ResourceIDs = []int64(resourceIDs)
+	if coupon.CategoryIDs == nil {
+		coupon.CategoryIDs = []int64{}
+	}
+	if coupon.ResourceIDs == nil {
+		coupon.ResourceIDs = []int64{}
+	}
+
+	return coupon, nil
+}
+
+func mapCouponError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrCouponCodeExists
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"smartbooking/internal/models"
+)
+
+// EmailOutboxRepository defines the interface for the outgoing email queue
+type EmailOutboxRepository interface {
+	Enqueue(ctx context.Context, email *models.OutboxEmail) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEmail, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt *time.Time) error
+}
+
+// emailOutboxRepository implements EmailOutboxRepository interface with PostgreSQL storage
+type emailOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewEmailOutboxRepository creates a new instance of EmailOutboxRepository
+func NewEmailOutboxRepository(db *sql.DB) EmailOutboxRepository {
+	return &emailOutboxRepository{
+		db: db,
+	}
+}
+
+// Enqueue ставит письмо в очередь. Повтор письма по тому же событию с тем же
+// шаблоном и адресатом игнорируется, поэтому повторная доставка события безопасна.
+func (r *emailOutboxRepository) Enqueue(ctx context.Context, email *models.OutboxEmail) error {
+	query := `
+		INSERT INTO email_outbox (to_address, subject, text_body, html_body, template, event_id, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (event_id, template, to_address) DO NOTHING
+		RETURNING id
+	`
+
+	now := time.Now()
+	email.Status = models.EmailPending
+	email.NextAttemptAt = now
+	email.CreatedAt = now
+
+	err := r.db.QueryRowContext(ctx, query,
+		email.To,
+		email.Subject,
+		email.TextBody,
+		email.HTMLBody,
+		email.Template,
+		email.EventID,
+		email.Status,
+		now,
+	).Scan(&email.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// ClaimDue забирает письма, срок отправки которых наступил, и откладывает их
+// next_attempt_at на lease. Пока воркер отправляет письмо, другие экземпляры
+// приложения его не возьмут; если воркер упадет, письмо вернется в очередь
+// по истечении lease.
+func (r *emailOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEmail, error) {
+	query := `
+		UPDATE email_outbox
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, to_address, subject, text_body, html_body, template, status, attempts, next_attempt_at, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := make([]*models.OutboxEmail, 0)
+	for rows.Next() {
+		var e models.OutboxEmail
+		if err := rows.Scan(&e.ID, &e.To, &e.Subject, &e.TextBody, &e.HTMLBody, &e.Template,
+			&e.Status, &e.Attempts, &e.NextAttemptAt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		emails = append(emails, &e)
+	}
+
+	return emails, rows.Err()
+}
+
+func (r *emailOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'sent', attempts = attempts + 1, sent_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkFailed записывает ошибку отправки. Если nextAttemptAt == nil, попытки
+// исчерпаны и письмо переводится в статус failed.
+func (r *emailOutboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt *time.Time) error {
+	query := `
+		UPDATE email_outbox
+		SET attempts = attempts + 1,
+			last_error = $2,
+			status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			next_attempt_at = COALESCE($3, next_attempt_at)
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, lastError, nextAttemptAt)
+	return err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"smartbooking/internal/models"
+)
+
+// EventOutboxRepository defines the interface for the domain event outbox
+type EventOutboxRepository interface {
+	Append(ctx context.Context, event *models.OutboxEvent) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, completedHandlers []string, lastError string, nextAttemptAt *time.Time) error
+}
+
+// eventOutboxRepository implements EventOutboxRepository interface with PostgreSQL storage
+type eventOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewEventOutboxRepository creates a new instance of EventOutboxRepository
+func NewEventOutboxRepository(db *sql.DB) EventOutboxRepository {
+	return &eventOutboxRepository{
+		db: db,
+	}
+}
+
+// Append записывает событие; внутри Transactor.WithinTx — в той же транзакции, что и изменение
+func (r *eventOutboxRepository) Append(ctx context.Context, event *models.OutboxEvent) error {
+	query := `
+		INSERT INTO event_outbox (event_type, payload, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	event.CreatedAt = time.Now()
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		event.Type,
+		[]byte(event.Payload),
+		event.CreatedAt,
+	).Scan(&event.ID)
+}
+
+// ClaimDue забирает события к доставке в порядке записи и откладывает их на lease,
+// чтобы параллельные диспетчеры не обработали одно событие одновременно
+func (r *eventOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	query := `
+		UPDATE event_outbox
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM event_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, payload, attempts, completed_handlers, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	for rows.Next() {
+		var e models.OutboxEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, &payload, &e.Attempts,
+			pq.Array(&e.CompletedHandlers), &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *eventOutboxRepository) MarkDispatched(ctx context.Context, id int64) error {
+	query := `
+		UPDATE event_outbox
+		SET status = 'dispatched', attempts = attempts + 1, dispatched_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkFailed сохраняет подписчиков, уже обработавших событие, и время следующей
+// попытки. nextAttemptAt == nil означает, что попытки исчерпаны.
+func (r *eventOutboxRepository) MarkFailed(ctx context.Context, id int64, completedHandlers []string, lastError string, nextAttemptAt *time.Time) error {
+	query := `
+		UPDATE event_outbox
+		SET attempts = attempts + 1,
+			completed_handlers = $2,
+			last_error = $3,
+			status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			next_attempt_at = COALESCE($4, next_attempt_at)
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, pq.Array(completedHandlers), lastError, nextAttemptAt)
+	return err
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"smartbooking/internal/models"
+)
+
+var (
+	ErrExternalCalendarNotFound = errors.New("external calendar not found")
+)
+
+// ExternalCalendarRepository defines the interface for imported calendars and the resource blocks they produce
+type ExternalCalendarRepository interface {
+	Create(ctx context.Context, cal *models.ExternalCalendar) error
+	GetByID(ctx context.Context, id int64) (*models.ExternalCalendar, error)
+	ListByResource(ctx context.Context, resourceID int64) ([]*models.ExternalCalendar, error)
+	Delete(ctx context.Context, id int64) error
+	ClaimDueSync(ctx context.Context, limit int, lease time.Duration) ([]*models.ExternalCalendar, error)
+	MarkSynced(ctx context.Context, id int64, syncErr string, nextSyncAt time.Time) error
+
+	ListBlocks(ctx context.Context, calendarID int64) ([]*models.ResourceBlock, error)
+	ListBlocksByResource(ctx context.Context, resourceID int64, from, to time.Time) ([]*models.ResourceBlock, error)
+	CreateBlock(ctx context.Context, block *models.ResourceBlock) error
+	UpdateBlock(ctx context.Context, block *models.ResourceBlock) error
+	DeleteBlocks(ctx context.Context, ids []int64) error
+}
+
+// externalCalendarRepository implements ExternalCalendarRepository interface with PostgreSQL storage
+type externalCalendarRepository struct {
+	db *sql.DB
+}
+
+// NewExternalCalendarRepository creates a new instance of ExternalCalendarRepository
+func NewExternalCalendarRepository(db *sql.DB) ExternalCalendarRepository {
+	return &externalCalendarRepository{
+		db: db,
+	}
+}
+
+const externalCalendarColumns = `id, resource_id, name, source, url, next_sync_at, last_synced_at,
+		last_error, created_at, updated_at`
+
+func scanExternalCalendar(row rowScanner) (*models.ExternalCalendar, error) {
+	var c models.ExternalCalendar
+	var url, lastError sql.NullString
+	var lastSyncedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.ResourceID, &c.Name, &c.Source, &url, &c.NextSyncAt, &lastSyncedAt,
+		&lastError, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	c.URL = url.String
+	c.LastError = lastError.String
+	if lastSyncedAt.Valid {
+		c.LastSyncedAt = &lastSyncedAt.Time
+	}
+	return &c, nil
+}
+
+func (r *externalCalendarRepository) Create(ctx context.Context, cal *models.ExternalCalendar) error {
+	query := `
+		INSERT INTO external_calendars (resource_id, name, source, url, next_sync_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	now := time.Now()
+	cal.CreatedAt = now
+	cal.UpdatedAt = now
+	if cal.NextSyncAt.IsZero() {
+		cal.NextSyncAt = now
+	}
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		cal.ResourceID,
+		cal.Name,
+		cal.Source,
+		nullString(cal.URL),
+		cal.NextSyncAt,
+		cal.CreatedAt,
+		cal.UpdatedAt,
+	).Scan(&cal.ID)
+}
+
+func (r *externalCalendarRepository) GetByID(ctx context.Context, id int64) (*models.ExternalCalendar, error) {
+	query := `SELECT ` + externalCalendarColumns + ` FROM external_calendars WHERE id = $1`
+
+	cal, err := scanExternalCalendar(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrExternalCalendarNotFound
+	}
+	return cal, err
+}
+
+func (r *externalCalendarRepository) ListByResource(ctx context.Context, resourceID int64) ([]*models.ExternalCalendar, error) {
+	query := `
+		SELECT ` + externalCalendarColumns + `
+		FROM external_calendars
+		WHERE resource_id = $1
+		ORDER BY created_at, id
+	`
+
+	return r.listCalendars(ctx, query, resourceID)
+}
+
+func (r *externalCalendarRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM external_calendars WHERE id = $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrExternalCalendarNotFound
+	}
+
+	return nil
+}
+
+// ClaimDueSync забирает календари по URL, которые пора синхронизировать, и откладывает
+// их на lease, чтобы параллельные воркеры не загружали один календарь одновременно
+func (r *externalCalendarRepository) ClaimDueSync(ctx context.Context, limit int, lease time.Duration) ([]*models.ExternalCalendar, error) {
+	query := `
+		UPDATE external_calendars
+		SET next_sync_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM external_calendars
+			WHERE source = 'url' AND next_sync_at <= NOW()
+			ORDER BY next_sync_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + externalCalendarColumns
+
+	return r.listCalendars(ctx, query, limit, lease.Seconds())
+}
+
+// MarkSynced сохраняет результат синхронизации. При ошибке время последней
+// успешной синхронизации не меняется.
+func (r *externalCalendarRepository) MarkSynced(ctx context.Context, id int64, syncErr string, nextSyncAt time.Time) error {
+	query := `
+		UPDATE external_calendars
+		SET last_error = $2,
+			last_synced_at = CASE WHEN $2::text IS NULL THEN NOW() ELSE last_synced_at END,
+			next_sync_at = $3
+		WHERE id = $1
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, nullString(syncErr), nextSyncAt)
+	return err
+}
+
+func (r *externalCalendarRepository) listCalendars(ctx context.Context, query string, args ...any) ([]*models.ExternalCalendar, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calendars := make([]*models.ExternalCalendar, 0)
+	for rows.Next() {
+		cal, err := scanExternalCalendar(rows)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, cal)
+	}
+
+	return calendars, rows.Err()
+}
+
+const resourceBlockColumns = `id, resource_id, calendar_id, uid, summary, start_time, end_time, created_at, updated_at`
+
+func (r *externalCalendarRepository) ListBlocks(ctx context.Context, calendarID int64) ([]*models.ResourceBlock, error) {
+	query := `
+		SELECT ` + resourceBlockColumns + `
+		FROM resource_blocks
+		WHERE calendar_id = $1
+		ORDER BY start_time, id
+	`
+
+	return r.listBlocks(ctx, query, calendarID)
+}
+
+// ListBlocksByResource возвращает блокировки ресурса, пересекающие интервал [from, to)
+func (r *externalCalendarRepository) ListBlocksByResource(ctx context.Context, resourceID int64, from, to time.Time) ([]*models.ResourceBlock, error) {
+	query := `
+		SELECT ` + resourceBlockColumns + `
+		FROM resource_blocks
+		WHERE resource_id = $1 AND start_time < $3 AND end_time > $2
+		ORDER BY start_time, id
+	`
+
+	return r.listBlocks(ctx, query, resourceID, from, to)
+}
+
+func (r *externalCalendarRepository) CreateBlock(ctx context.Context, block *models.ResourceBlock) error {
+	query := `
+		INSERT INTO resource_blocks (resource_id, calendar_id, uid, summary, start_time, end_time, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	now := time.Now()
+	block.CreatedAt = now
+	block.UpdatedAt = now
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		block.ResourceID,
+		block.CalendarID,
+		block.UID,
+		block.Summary,
+		block.StartTime,
+		block.EndTime,
+		block.CreatedAt,
+		block.UpdatedAt,
+	).Scan(&block.ID)
+}
+
+func (r *externalCalendarRepository) UpdateBlock(ctx context.Context, block *models.ResourceBlock) error {
+	query := `
+		UPDATE resource_blocks
+		SET summary = $2, start_time = $3, end_time = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	block.UpdatedAt = time.Now()
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		block.ID,
+		block.Summary,
+		block.StartTime,
+		block.EndTime,
+		block.UpdatedAt,
+	)
+	return err
+}
+
+func (r *externalCalendarRepository) DeleteBlocks(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM resource_blocks WHERE id = ANY($1)`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+func (r *externalCalendarRepository) listBlocks(ctx context.Context, query string, args ...any) ([]*models.ResourceBlock, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]*models.ResourceBlock, 0)
+	for rows.Next() {
+		var b models.ResourceBlock
+		var calendarID sql.NullInt64
+		if err := rows.Scan(&b.ID, &b.ResourceID, &calendarID, &b.UID, &b.Summary,
+			&b.StartTime, &b.EndTime, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		b.CalendarID = models.NullInt64ToPtr(calendarID)
+		blocks = append(blocks, &b)
+	}
+
+	return blocks, rows.Err()
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"smartbooking/internal/models"
+)
+
+var (
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	ErrInvoiceExists   = errors.New("invoice already issued for booking")
+)
+
+// InvoiceRepository defines the interface for invoice data operations
+type InvoiceRepository interface {
+	Create(ctx context.Context, invoice *models.Invoice, numberPrefix string) error
+	GetByBooking(ctx context.Context, bookingID int64) (*models.Invoice, error)
+	ListByOwner(ctx context.Context, ownerID int64) ([]*models.Invoice, error)
+}
+
+// invoiceRepository implements InvoiceRepository interface with PostgreSQL storage
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+// NewInvoiceRepository creates a new instance of InvoiceRepository
+func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
+	return &invoiceRepository{
+		db: db,
+	}
+}
+
+const invoiceColumns = `i.id, i.number, i.booking_id, i.resource_id, i.owner_id, i.customer_name, i.customer_email,
+		i.resource_name, i.currency, i.subtotal, i.discount_amount, i.tax_rate, i.tax_amount, i.total,
+		i.issued_at, i.created_at, b.start_time, b.end_time, b.amount_paid, COALESCE(r.timezone, 'UTC')`
+
+// invoiceCounter строка invoice_counters со сквозной нумерацией счетов
+const invoiceCounter = "invoice"
+
+// Create выставляет счет вместе с позициями. Номер в формате
+// <prefix>-<год>-<номер> берется из счетчика, заблокированного до конца
+// транзакции: параллельные выставления идут по очереди, а номер расходуется
+// только вместе с сохраненным счетом, поэтому пропусков нет. Если счет по
+// бронированию уже есть, возвращается ErrInvoiceExists.
+func (r *invoiceRepository) Create(ctx context.Context, invoice *models.Invoice, numberPrefix string) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lastNumber int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT last_number FROM invoice_counters WHERE name = $1 FOR UPDATE
+	`, invoiceCounter).Scan(&lastNumber)
+	if err != nil {
+		return fmt.Errorf("lock invoice counter: %w", err)
+	}
+
+	// Проверка после блокировки видит счет, выставленный параллельным запросом
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM invoices WHERE booking_id = $1)
+	`, invoice.BookingID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrInvoiceExists
+	}
+
+	query := `
+		INSERT INTO invoices (number, booking_id, resource_id, owner_id, customer_name, customer_email,
+			resource_name, currency, subtotal, discount_amount, tax_rate, tax_amount, total,
+			issued_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (booking_id) DO NOTHING
+		RETURNING id
+	`
+
+	now := time.Now()
+	invoice.IssuedAt = now
+	invoice.CreatedAt = now
+
+	number := lastNumber + 1
+	invoice.Number = fmt.Sprintf("%s-%d-%06d", numberPrefix, now.Year(), number)
+
+	err = tx.QueryRowContext(ctx, query,
+		invoice.Number,
+		invoice.BookingID,
+		invoice.ResourceID,
+		invoice.OwnerID,
+		invoice.CustomerName,
+		invoice.CustomerEmail,
+		invoice.ResourceName,
+		invoice.Currency,
+		invoice.Subtotal,
+		invoice.DiscountAmount,
+		invoice.TaxRate,
+		invoice.TaxAmount,
+		invoice.Total,
+		invoice.IssuedAt,
+		invoice.CreatedAt,
+	).Scan(&invoice.ID)
+	if err == sql.ErrNoRows {
+		return ErrInvoiceExists
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE invoice_counters SET last_number = $1 WHERE name = $2
+	`, number, invoiceCounter)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO invoice_items (invoice_id, position, description, quantity, unit_price, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	for i, item := range invoice.Items {
+		item.InvoiceID = invoice.ID
+		item.Position = i + 1
+		err := tx.QueryRowContext(ctx, itemQuery,
+			item.InvoiceID,
+			item.Position,
+			item.Description,
+			item.Quantity,
+			item.UnitPrice,
+			item.Amount,
+		).Scan(&item.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *invoiceRepository) GetByBooking(ctx context.Context, bookingID int64) (*models.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices i
+		INNER JOIN bookings b ON i.booking_id = b.id
+		LEFT JOIN resources r ON i.resource_id = r.id
+		WHERE i.booking_id = $1
+	`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, bookingID))
+	if err != nil {
+		return nil, err
+	}
+
+	itemsQuery := `
+		SELECT id, invoice_id, position, description, quantity, unit_price, amount
+		FROM invoice_items
+		WHERE invoice_id = $1
+		ORDER BY position
+	`
+
+	rows, err := r.db.QueryContext(ctx, itemsQuery, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoice.Items = make([]*models.InvoiceItem, 0)
+	for rows.Next() {
+		item := &models.InvoiceItem{}
+		err := rows.Scan(
+			&item.ID,
+			&item.InvoiceID,
+			&item.Position,
+			&item.Description,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+
+	return invoice, rows.Err()
+}
+
+func (r *invoiceRepository) ListByOwner(ctx context.Context, ownerID int64) ([]*models.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices i
+		INNER JOIN bookings b ON i.booking_id = b.id
+		LEFT JOIN resources r ON i.resource_id = r.id
+		WHERE i.owner_id = $1
+		ORDER BY i.issued_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := make([]*models.Invoice, 0)
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+func scanInvoice(row rowScanner) (*models.Invoice, error) {
+	invoice := &models.Invoice{}
+	var resourceID, ownerID sql.NullInt64
+
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.Number,
+		&invoice.BookingID,
+		&resourceID,
+		&ownerID,
+		&invoice.CustomerName,
+		&invoice.CustomerEmail,
+		&invoice.ResourceName,
+		&invoice.Currency,
+		&invoice.Subtotal,
+		&invoice.DiscountAmount,
+		&invoice.TaxRate,
+		&invoice.TaxAmount,
+		&invoice.Total,
+		&invoice.IssuedAt,
+		&invoice.CreatedAt,
+		&invoice.StartTime,
+		&invoice.EndTime,
+		&invoice.AmountPaid,
+		&invoice.Timezone,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.ResourceID = models.NullInt64ToPtr(resourceID)
+	invoice.OwnerID = models.NullInt64ToPtr(ownerID)
+
+	// Счет показывает даты по месту нахождения ресурса
+	loc := models.TimezoneLocation(invoice.Timezone)
+	invoice.IssuedAt = invoice.IssuedAt.In(loc)
+	invoice.StartTime = invoice.StartTime.In(loc)
+	invoice.EndTime = invoice.EndTime.In(loc)
+
+	return invoice, nil
+}
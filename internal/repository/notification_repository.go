@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"smartbooking/internal/models"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationRepository defines the interface for notification data operations
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	MarkRead(ctx context.Context, id, userID int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+}
+
+// notificationRepository implements NotificationRepository interface with PostgreSQL storage
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, title, message, type, related_entity_type, related_entity_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	notification.CreatedAt = time.Now()
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		notification.UserID,
+		notification.Title,
+		notification.Message,
+		notification.Type,
+		nullString(notification.RelatedEntityType),
+		notification.RelatedEntityID,
+		notification.CreatedAt,
+	).Scan(&notification.ID)
+}
+
+// ListByUser возвращает уведомления пользователя, новые первыми
+func (r *notificationRepository) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*models.Notification, error) {
+	query := `
+		SELECT id, user_id, title, message, type, is_read, related_entity_type, related_entity_id, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR is_read = FALSE)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		var n models.Notification
+		var entityType sql.NullString
+		var entityID sql.NullInt64
+
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Title, &n.Message, &n.Type, &n.IsRead,
+			&entityType, &entityID, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		n.RelatedEntityType = entityType.String
+		if entityID.Valid {
+			n.RelatedEntityID = &entityID.Int64
+		}
+		notifications = append(notifications, &n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead отмечает уведомление прочитанным; чужие уведомления считаются ненайденными
+func (r *notificationRepository) MarkRead(ctx context.Context, id, userID int64) error {
+	query := `UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllRead отмечает все уведомления пользователя прочитанными и возвращает их количество
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	query := `UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND is_read = FALSE`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
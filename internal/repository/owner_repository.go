@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"smartbooking/internal/models"
+	"smartbooking/internal/money"
 )
 
 // OwnerRepository defines the interface for owner-specific data operations
@@ -12,17 +14,27 @@ type OwnerRepository interface {
 	GetOwnerResources(ctx context.Context, ownerID int64) ([]*models.Resource, error)
 	GetOwnerBookings(ctx context.Context, ownerID int64) ([]*models.Booking, error)
 	GetOwnerStatistics(ctx context.Context, ownerID int64) (*OwnerStatistics, error)
+	GetOwnerBookingsInRange(ctx context.Context, ownerID int64, resourceID *int64, from, to time.Time) ([]*models.Booking, error)
+	GetOwnerBlocks(ctx context.Context, ownerID int64, resourceID *int64, from, to time.Time) ([]*models.ResourceBlock, error)
+	GetOwnerSchedules(ctx context.Context, ownerID int64, resourceID *int64) ([]*models.ResourceSchedule, error)
 }
 
 // OwnerStatistics represents aggregated statistics for an owner
 type OwnerStatistics struct {
-	TotalResources   int     `json:"total_resources"`
-	TotalBookings    int     `json:"total_bookings"`
-	ActiveBookings   int     `json:"active_bookings"`
-	CancelledBookings int    `json:"cancelled_bookings"`
-	TotalRevenue     float64 `json:"total_revenue"`
-	AverageRating    float64 `json:"average_rating"`
-	TotalReviews     int     `json:"total_reviews"`
+	TotalResources    int     `json:"total_resources"`
+	TotalBookings     int     `json:"total_bookings"`
+	ActiveBookings    int     `json:"active_bookings"`
+	CancelledBookings int     `json:"cancelled_bookings"`
+	AverageRating     float64 `json:"average_rating"`
+	TotalReviews      int     `json:"total_reviews"`
+
+	// Суммы по каждой валюте и итоги, пересчитанные в базовую валюту Currency
+	RevenueByCurrency     []CurrencyRevenue `json:"revenue_by_currency"`
+	Currency              string            `json:"currency"`
+	TotalRevenue          money.Amount      `json:"total_revenue"`
+	CollectedRevenue      money.Amount      `json:"collected_revenue"`
+	OutstandingRevenue    money.Amount      `json:"outstanding_revenue"`
+	UnconvertedCurrencies []string          `json:"unconverted_currencies,omitempty"`
 }
 
 // ownerRepository implements OwnerRepository interface with PostgreSQL storage
@@ -43,18 +55,17 @@ func (r *ownerRepository) GetOwnerResources(ctx context.Context, ownerID int64)
 		SELECT
 			r.id, r.name, r.description, r.capacity, r.owner_id,
 			r.category_id, r.address, r.city, r.latitude, r.longitude,
-			r.amenities, r.rules, r.price_per_hour, r.is_active,
+			r.amenities, r.rules, r.price_per_hour, r.currency, r.is_active, r.timezone,
 			r.created_at, r.updated_at,
 			u.name as owner_name,
 			c.name as category_name,
-			COALESCE(AVG(rv.rating), 0) as rating,
-			COUNT(DISTINCT rv.id) as reviews_count
+			COALESCE(rs.rating_sum::float / NULLIF(rs.review_count, 0), 0) as rating,
+			COALESCE(rs.review_count, 0) as reviews_count
 		FROM resources r
 		LEFT JOIN users u ON r.owner_id = u.id
 		LEFT JOIN resource_categories c ON r.category_id = c.id
-		LEFT JOIN reviews rv ON r.id = rv.resource_id
+		LEFT JOIN resource_rating_stats rs ON r.id = rs.resource_id
 		WHERE r.owner_id = $1
-		GROUP BY r.id, u.name, c.name
 		ORDER BY r.created_at DESC
 	`
 
@@ -69,7 +80,7 @@ func (r *ownerRepository) GetOwnerResources(ctx context.Context, ownerID int64)
 		resource := &models.Resource{}
 		var ownerID, categoryID sql.NullInt64
 		var ownerName, categoryName, address, city, rules sql.NullString
-		var latitude, longitude, pricePerHour sql.NullFloat64
+		var latitude, longitude sql.NullFloat64
 		var amenities sql.NullString
 
 		err := rows.Scan(
@@ -85,8 +96,10 @@ func (r *ownerRepository) GetOwnerResources(ctx context.Context, ownerID int64)
 			&longitude,
 			&amenities,
 			&rules,
-			&pricePerHour,
+			&resource.PricePerHour,
+			&resource.Currency,
 			&resource.IsActive,
+			&resource.Timezone,
 			&resource.CreatedAt,
 			&resource.UpdatedAt,
 			&ownerName,
@@ -102,7 +115,6 @@ func (r *ownerRepository) GetOwnerResources(ctx context.Context, ownerID int64)
 		resource.CategoryID = models.NullInt64ToPtr(categoryID)
 		resource.Latitude = models.NullFloat64ToPtr(latitude)
 		resource.Longitude = models.NullFloat64ToPtr(longitude)
-		resource.PricePerHour = models.NullFloat64ToPtr(pricePerHour)
 
 		if ownerName.Valid {
 			resource.OwnerName = ownerName.String
@@ -138,7 +150,7 @@ func (r *ownerRepository) GetOwnerBookings(ctx context.Context, ownerID int64) (
 	query := `
 		SELECT
 			b.id, b.user_id, b.resource_id, b.start_time, b.end_time,
-			b.status, b.total_price, b.notes, b.created_at, b.updated_at,
+			b.status, b.total_price, b.currency, b.amount_paid, b.notes, b.created_at, b.updated_at,
 			u.name as user_name, u.email as user_email,
 			r.name as resource_name
 		FROM bookings b
@@ -148,7 +160,106 @@ func (r *ownerRepository) GetOwnerBookings(ctx context.Context, ownerID int64) (
 		ORDER BY b.start_time DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	return r.queryOwnerBookings(ctx, query, ownerID)
+}
+
+// GetOwnerStatistics calculates and retrieves statistics for an owner
+func (r *ownerRepository) GetOwnerStatistics(ctx context.Context, ownerID int64) (*OwnerStatistics, error) {
+	stats := &OwnerStatistics{}
+
+	// Get resource count
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM resources WHERE owner_id = $1
+	`, ownerID).Scan(&stats.TotalResources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get booking statistics
+	query := `
+		SELECT
+			COUNT(*) as total_bookings,
+			SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN 1 ELSE 0 END) as active_bookings,
+			SUM(CASE WHEN b.status = 'cancelled' THEN 1 ELSE 0 END) as cancelled_bookings
+		FROM bookings b
+		INNER JOIN resources r ON b.resource_id = r.id
+		WHERE r.owner_id = $1
+	`
+
+	err = r.db.QueryRowContext(ctx, query, ownerID).Scan(
+		&stats.TotalBookings,
+		&stats.ActiveBookings,
+		&stats.CancelledBookings,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revenueQuery := `
+		SELECT
+			b.currency,
+			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN b.total_price ELSE 0 END), 0) as revenue,
+			COALESCE(SUM(b.amount_paid), 0) as collected,
+			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed')
+				THEN GREATEST(b.total_price - b.amount_paid, 0) ELSE 0 END), 0) as outstanding,
+			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN b.discount_amount ELSE 0 END), 0) as discounts
+		FROM bookings b
+		INNER JOIN resources r ON b.resource_id = r.id
+		WHERE r.owner_id = $1
+		GROUP BY b.currency
+		ORDER BY b.currency
+	`
+
+	stats.RevenueByCurrency, err = queryCurrencyRevenue(ctx, r.db, revenueQuery, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get rating statistics
+	ratingQuery := `
+		SELECT
+			COALESCE(SUM(rs.rating_sum)::float / NULLIF(SUM(rs.review_count), 0), 0) as average_rating,
+			COALESCE(SUM(rs.review_count), 0) as total_reviews
+		FROM resource_rating_stats rs
+		INNER JOIN resources r ON rs.resource_id = r.id
+		WHERE r.owner_id = $1
+	`
+
+	err = r.db.QueryRowContext(ctx, ratingQuery, ownerID).Scan(
+		&stats.AverageRating,
+		&stats.TotalReviews,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetOwnerBookingsInRange retrieves active bookings of an owner's resources that overlap [from, to)
+func (r *ownerRepository) GetOwnerBookingsInRange(ctx context.Context, ownerID int64, resourceID *int64, from, to time.Time) ([]*models.Booking, error) {
+	query := `
+		SELECT
+			b.id, b.user_id, b.resource_id, b.start_time, b.end_time,
+			b.status, b.total_price, b.currency, b.amount_paid, b.notes, b.created_at, b.updated_at,
+			u.name as user_name, u.email as user_email,
+			r.name as resource_name
+		FROM bookings b
+		INNER JOIN resources r ON b.resource_id = r.id
+		INNER JOIN users u ON b.user_id = u.id
+		WHERE r.owner_id = $1
+			AND ($2::bigint IS NULL OR b.resource_id = $2)
+			AND b.status != 'cancelled'
+			AND b.start_time < $4
+			AND b.end_time > $3
+		ORDER BY b.start_time, b.id
+	`
+
+	return r.queryOwnerBookings(ctx, query, ownerID, resourceID, from, to)
+}
+
+func (r *ownerRepository) queryOwnerBookings(ctx context.Context, query string, args ...any) ([]*models.Booking, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +268,6 @@ func (r *ownerRepository) GetOwnerBookings(ctx context.Context, ownerID int64) (
 	bookings := make([]*models.Booking, 0)
 	for rows.Next() {
 		booking := &models.Booking{}
-		var totalPrice sql.NullFloat64
 		var notes sql.NullString
 		var userName, userEmail, resourceName string
 
@@ -168,7 +278,9 @@ func (r *ownerRepository) GetOwnerBookings(ctx context.Context, ownerID int64) (
 			&booking.StartTime,
 			&booking.EndTime,
 			&booking.Status,
-			&totalPrice,
+			&booking.TotalPrice,
+			&booking.Currency,
+			&booking.AmountPaid,
 			&notes,
 			&booking.CreatedAt,
 			&booking.UpdatedAt,
@@ -180,12 +292,10 @@ func (r *ownerRepository) GetOwnerBookings(ctx context.Context, ownerID int64) (
 			return nil, err
 		}
 
-		if totalPrice.Valid {
-			booking.TotalPrice = totalPrice.Float64
-		}
 		if notes.Valid {
 			booking.Notes = notes.String
 		}
+		booking.Balance = booking.TotalPrice - booking.AmountPaid
 
 		// Add user and resource details (can extend models if needed)
 		booking.UserName = userName
@@ -202,57 +312,67 @@ func (r *ownerRepository) GetOwnerBookings(ctx context.Context, ownerID int64) (
 	return bookings, nil
 }
 
-// GetOwnerStatistics calculates and retrieves statistics for an owner
-func (r *ownerRepository) GetOwnerStatistics(ctx context.Context, ownerID int64) (*OwnerStatistics, error) {
-	stats := &OwnerStatistics{}
-
-	// Get resource count
-	err := r.db.QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM resources WHERE owner_id = $1
-	`, ownerID).Scan(&stats.TotalResources)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get booking statistics
+// GetOwnerBlocks retrieves external calendar blocks of an owner's resources that overlap [from, to)
+func (r *ownerRepository) GetOwnerBlocks(ctx context.Context, ownerID int64, resourceID *int64, from, to time.Time) ([]*models.ResourceBlock, error) {
 	query := `
-		SELECT
-			COUNT(*) as total_bookings,
-			SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN 1 ELSE 0 END) as active_bookings,
-			SUM(CASE WHEN b.status = 'cancelled' THEN 1 ELSE 0 END) as cancelled_bookings,
-			COALESCE(SUM(CASE WHEN b.status IN ('pending', 'confirmed') THEN b.total_price ELSE 0 END), 0) as total_revenue
-		FROM bookings b
-		INNER JOIN resources r ON b.resource_id = r.id
+		SELECT bl.id, bl.resource_id, bl.calendar_id, bl.uid, bl.summary, bl.start_time, bl.end_time,
+			bl.created_at, bl.updated_at
+		FROM resource_blocks bl
+		INNER JOIN resources r ON bl.resource_id = r.id
 		WHERE r.owner_id = $1
+			AND ($2::bigint IS NULL OR bl.resource_id = $2)
+			AND bl.start_time < $4
+			AND bl.end_time > $3
+		ORDER BY bl.start_time, bl.id
 	`
 
-	err = r.db.QueryRowContext(ctx, query, ownerID).Scan(
-		&stats.TotalBookings,
-		&stats.ActiveBookings,
-		&stats.CancelledBookings,
-		&stats.TotalRevenue,
-	)
+	rows, err := r.db.QueryContext(ctx, query, ownerID, resourceID, from, to)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Get rating statistics
-	ratingQuery := `
-		SELECT
-			COALESCE(AVG(rv.rating), 0) as average_rating,
-			COUNT(rv.id) as total_reviews
-		FROM reviews rv
-		INNER JOIN resources r ON rv.resource_id = r.id
+	blocks := make([]*models.ResourceBlock, 0)
+	for rows.Next() {
+		var b models.ResourceBlock
+		var calendarID sql.NullInt64
+		if err := rows.Scan(&b.ID, &b.ResourceID, &calendarID, &b.UID, &b.Summary,
+			&b.StartTime, &b.EndTime, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		b.CalendarID = models.NullInt64ToPtr(calendarID)
+		blocks = append(blocks, &b)
+	}
+
+	return blocks, rows.Err()
+}
+
+// GetOwnerSchedules retrieves weekly opening hours of an owner's resources
+func (r *ownerRepository) GetOwnerSchedules(ctx context.Context, ownerID int64, resourceID *int64) ([]*models.ResourceSchedule, error) {
+	query := `
+		SELECT s.resource_id, s.day_of_week, to_char(s.open_time, 'HH24:MI'), to_char(s.close_time, 'HH24:MI'),
+			COALESCE(s.is_closed, false)
+		FROM resource_schedules s
+		INNER JOIN resources r ON s.resource_id = r.id
 		WHERE r.owner_id = $1
+			AND ($2::bigint IS NULL OR s.resource_id = $2)
+		ORDER BY s.resource_id, s.day_of_week
 	`
 
-	err = r.db.QueryRowContext(ctx, ratingQuery, ownerID).Scan(
-		&stats.AverageRating,
-		&stats.TotalReviews,
-	)
+	rows, err := r.db.QueryContext(ctx, query, ownerID, resourceID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return stats, nil
+	schedules := make([]*models.ResourceSchedule, 0)
+	for rows.Next() {
+		var sc models.ResourceSchedule
+		if err := rows.Scan(&sc.ResourceID, &sc.DayOfWeek, &sc.OpenTime, &sc.CloseTime, &sc.IsClosed); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, &sc)
+	}
+
+	return schedules, rows.Err()
 }
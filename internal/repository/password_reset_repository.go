@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrResetTokenInvalid = errors.New("password reset token is invalid or expired")
+)
+
+// PasswordResetRepository stores one-time password reset tokens by their hash
+type PasswordResetRepository interface {
+	Create(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	Consume(ctx context.Context, tokenHash string) (int64, error)
+}
+
+// passwordResetRepository implements PasswordResetRepository interface with PostgreSQL storage
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository creates a new instance of PasswordResetRepository
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{
+		db: db,
+	}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, tokenHash, expiresAt)
+	return err
+}
+
+// Consume атомарно помечает токен использованным и возвращает ID пользователя
+func (r *passwordResetRepository) Consume(ctx context.Context, tokenHash string) (int64, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+
+	var userID int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrResetTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
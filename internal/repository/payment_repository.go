@@ -0,0 +1,411 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+)
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrInstallmentNotFound  = errors.New("installment not found")
+	ErrRefundExceedsPayment = errors.New("refund exceeds the refundable amount of the payment")
+)
+
+// PaymentRepository defines the interface for payment data operations
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *models.Payment) error
+	GetByID(ctx context.Context, id int64) (*models.Payment, error)
+	// GetForUpdate возвращает платеж, блокируя его строку до конца транзакции
+	GetForUpdate(ctx context.Context, id int64) (*models.Payment, error)
+	GetByProviderID(ctx context.Context, provider, providerPaymentID string) (*models.Payment, error)
+	ListByBooking(ctx context.Context, bookingID int64) ([]*models.Payment, error)
+	Update(ctx context.Context, payment *models.Payment) error
+	RecordWebhookEvent(ctx context.Context, provider, eventID, eventType string, payload []byte) (bool, error)
+
+	CreateInstallments(ctx context.Context, installments []*models.PaymentInstallment) error
+	GetInstallment(ctx context.Context, id int64) (*models.PaymentInstallment, error)
+	ListInstallments(ctx context.Context, bookingID int64) ([]*models.PaymentInstallment, error)
+	ApplySucceeded(ctx context.Context, payment *models.Payment) (bool, error)
+	ApplyRefund(ctx context.Context, payment *models.Payment, amount money.Amount) error
+}
+
+// paymentRepository implements PaymentRepository interface with PostgreSQL storage
+type paymentRepository struct {
+	db *sql.DB
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+const paymentColumns = `id, booking_id, provider, provider_payment_id, amount, refunded_amount,
+		currency, status, failure_reason, installment_id, created_at, updated_at`
+
+const installmentColumns = `id, booking_id, kind, amount, due_at, status, paid_at, created_at, updated_at`
+
+func (r *paymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	query := `
+		INSERT INTO payments (booking_id, provider, provider_payment_id, amount, refunded_amount,
+			currency, status, failure_reason, installment_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	now := time.Now()
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	return r.db.QueryRowContext(ctx, query,
+		payment.BookingID,
+		payment.Provider,
+		payment.ProviderPaymentID,
+		payment.Amount,
+		payment.RefundedAmount,
+		payment.Currency,
+		payment.Status,
+		nullString(payment.FailureReason),
+		payment.InstallmentID,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	).Scan(&payment.ID)
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id int64) (*models.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+	return scanPayment(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetForUpdate блокирует строку платежа до конца транзакции вызывающего кода
+// (WithinTx), чтобы параллельные возвраты проверяли остаток по очереди
+func (r *paymentRepository) GetForUpdate(ctx context.Context, id int64) (*models.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1 FOR UPDATE`
+	return scanPayment(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+}
+
+func (r *paymentRepository) GetByProviderID(ctx context.Context, provider, providerPaymentID string) (*models.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND provider_payment_id = $2`
+	return scanPayment(r.db.QueryRowContext(ctx, query, provider, providerPaymentID))
+}
+
+func (r *paymentRepository) ListByBooking(ctx context.Context, bookingID int64) ([]*models.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE booking_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]*models.Payment, 0)
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func (r *paymentRepository) Update(ctx context.Context, payment *models.Payment) error {
+	query := `
+		UPDATE payments
+		SET status = $1, refunded_amount = $2, failure_reason = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	payment.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		payment.Status,
+		payment.RefundedAmount,
+		nullString(payment.FailureReason),
+		payment.UpdatedAt,
+		payment.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPaymentNotFound
+	}
+
+	return nil
+}
+
+// RecordWebhookEvent сохраняет вебхук и возвращает false, если событие уже обрабатывалось
+func (r *paymentRepository) RecordWebhookEvent(ctx context.Context, provider, eventID, eventType string, payload []byte) (bool, error) {
+	query := `
+		INSERT INTO payment_webhook_events (provider, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, event_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, provider, eventID, eventType, payload)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// CreateInstallments сохраняет график платежей. Уже существующие позиции
+// (booking_id, kind) не перезаписываются, поэтому повторный вызов безопасен.
+func (r *paymentRepository) CreateInstallments(ctx context.Context, installments []*models.PaymentInstallment) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO booking_installments (booking_id, kind, amount, due_at, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (booking_id, kind) DO NOTHING
+	`
+
+	for _, inst := range installments {
+		if _, err := tx.ExecContext(ctx, query, inst.BookingID, inst.Kind, inst.Amount, inst.DueAt, inst.Status); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *paymentRepository) GetInstallment(ctx context.Context, id int64) (*models.PaymentInstallment, error) {
+	query := `SELECT ` + installmentColumns + ` FROM booking_installments WHERE id = $1`
+	return scanInstallment(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *paymentRepository) ListInstallments(ctx context.Context, bookingID int64) ([]*models.PaymentInstallment, error) {
+	query := `SELECT ` + installmentColumns + ` FROM booking_installments WHERE booking_id = $1 ORDER BY due_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	installments := make([]*models.PaymentInstallment, 0)
+	for rows.Next() {
+		inst, err := scanInstallment(rows)
+		if err != nil {
+			return nil, err
+		}
+		installments = append(installments, inst)
+	}
+
+	return installments, rows.Err()
+}
+
+// ApplySucceeded в одной транзакции помечает платеж успешным, закрывает позицию
+// графика и увеличивает оплаченную сумму бронирования. Ожидающее бронирование
+// ресурса с предоплатой подтверждается; возвращает true, если подтверждение
+// произошло сейчас.
+func (r *paymentRepository) ApplySucceeded(ctx context.Context, payment *models.Payment) (bool, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	payment.Status = models.PaymentSucceeded
+	payment.FailureReason = ""
+	payment.UpdatedAt = time.Now()
+
+	// Условие по статусу защищает от двойного зачисления при гонке вебхука и capture
+	result, err := tx.ExecContext(ctx, `
+		UPDATE payments SET status = $1, failure_reason = NULL, updated_at = $2
+		WHERE id = $3 AND status IN ('requires_payment', 'requires_capture')
+	`, payment.Status, payment.UpdatedAt, payment.ID)
+	if err != nil {
+		return false, err
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return false, err
+	}
+
+	if payment.InstallmentID != nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE booking_installments SET status = 'paid', paid_at = $1
+			WHERE id = $2 AND status IN ('pending', 'refunded')
+		`, payment.UpdatedAt, *payment.InstallmentID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	var previousStatus models.BookingStatus
+	var requiresPrepayment bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT b.status, r.requires_prepayment
+		FROM bookings b
+		JOIN resources r ON r.id = b.resource_id
+		WHERE b.id = $1
+		FOR UPDATE OF b
+	`, payment.BookingID).Scan(&previousStatus, &requiresPrepayment)
+	if err == sql.ErrNoRows {
+		return false, ErrBookingNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	confirm := requiresPrepayment && previousStatus == models.StatusPending
+	_, err = tx.ExecContext(ctx, `
+		UPDATE bookings
+		SET amount_paid = amount_paid + $1,
+			status = CASE WHEN $2 THEN 'confirmed' ELSE status END,
+			updated_at = $3
+		WHERE id = $4
+	`, payment.Amount, confirm, payment.UpdatedAt, payment.BookingID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return confirm, nil
+}
+
+// ApplyRefund увеличивает возвращенную сумму платежа на amount и уменьшает
+// оплаченную сумму бронирования. Сумма прибавляется в самом UPDATE, который не
+// проходит, если возвраты превысят платеж, поэтому параллельные возвраты не
+// теряются. RefundedAmount и Status платежа берутся из обновленной строки.
+// При полном возврате позиция графика снова считается неоплаченной.
+func (r *paymentRepository) ApplyRefund(ctx context.Context, payment *models.Payment, amount money.Amount) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	payment.UpdatedAt = time.Now()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE payments
+		SET refunded_amount = refunded_amount + $1,
+			status = CASE WHEN refunded_amount + $1 >= amount THEN 'refunded' ELSE 'partially_refunded' END,
+			updated_at = $2
+		WHERE id = $3 AND status IN ('succeeded', 'partially_refunded') AND refunded_amount + $1 <= amount
+		RETURNING refunded_amount, status
+	`, amount, payment.UpdatedAt, payment.ID).Scan(&payment.RefundedAmount, &payment.Status)
+	if err == sql.ErrNoRows {
+		return ErrRefundExceedsPayment
+	}
+	if err != nil {
+		return err
+	}
+
+	if payment.InstallmentID != nil && payment.Status == models.PaymentRefunded {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE booking_installments SET status = 'refunded'
+			WHERE id = $1 AND status = 'paid'
+		`, *payment.InstallmentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE bookings SET amount_paid = GREATEST(amount_paid - $1, 0), updated_at = $2
+		WHERE id = $3
+	`, amount, payment.UpdatedAt, payment.BookingID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanInstallment(row rowScanner) (*models.PaymentInstallment, error) {
+	inst := &models.PaymentInstallment{}
+	var paidAt sql.NullTime
+
+	err := row.Scan(
+		&inst.ID,
+		&inst.BookingID,
+		&inst.Kind,
+		&inst.Amount,
+		&inst.DueAt,
+		&inst.Status,
+		&paidAt,
+		&inst.CreatedAt,
+		&inst.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrInstallmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if paidAt.Valid {
+		inst.PaidAt = &paidAt.Time
+	}
+
+	return inst, nil
+}
+
+func scanPayment(row rowScanner) (*models.Payment, error) {
+	payment := &models.Payment{}
+	var failureReason sql.NullString
+	var installmentID sql.NullInt64
+
+	err := row.Scan(
+		&payment.ID,
+		&payment.BookingID,
+		&payment.Provider,
+		&payment.ProviderPaymentID,
+		&payment.Amount,
+		&payment.RefundedAmount,
+		&payment.Currency,
+		&payment.Status,
+		&failureReason,
+		&installmentID,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if failureReason.Valid {
+		payment.FailureReason = failureReason.String
+	}
+	payment.InstallmentID = models.NullInt64ToPtr(installmentID)
+
+	return payment, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
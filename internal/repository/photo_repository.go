@@ -3,19 +3,26 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"smartbooking/internal/models"
 )
 
+var ErrPhotoNotFound = errors.New("photo not found")
+
 // PhotoRepository интерфейс для работы с фотографиями
 type PhotoRepository interface {
 	Create(ctx context.Context, photo *models.ResourcePhoto) error
 	GetByID(ctx context.Context, id int64) (*models.ResourcePhoto, error)
 	GetByResourceID(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error)
+	// ListForUpdate возвращает фото ресурса, блокируя ресурс до конца
+	// транзакции, чтобы параллельные загрузки и перестановки не мешали друг другу
+	ListForUpdate(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error)
 	Delete(ctx context.Context, id int64) error
 	SetPrimary(ctx context.Context, id int64, resourceID int64) error
 	UpdateOrder(ctx context.Context, id int64, order int) error
+	UpdateDetails(ctx context.Context, id int64, details models.PhotoDetails) error
 }
 
 type photoRepository struct {
@@ -26,18 +33,50 @@ func NewPhotoRepository(db *sql.DB) PhotoRepository {
 	return &photoRepository{db: db}
 }
 
+const photoColumns = `
+	id, resource_id, url, storage_key, file_name, file_size,
+	mime_type, width, height, is_primary, display_order,
+	COALESCE(thumbnail_url, ''), COALESCE(thumbnail_key, ''),
+	COALESCE(medium_url, ''), COALESCE(medium_key, ''),
+	COALESCE(caption, ''), COALESCE(alt_text, ''),
+	created_at, updated_at`
+
+func scanPhoto(row rowScanner) (*models.ResourcePhoto, error) {
+	photo := &models.ResourcePhoto{}
+	var width, height sql.NullInt64
+	err := row.Scan(
+		&photo.ID, &photo.ResourceID, &photo.URL, &photo.StorageKey, &photo.FileName,
+		&photo.FileSize, &photo.MimeType, &width, &height,
+		&photo.IsPrimary, &photo.DisplayOrder,
+		&photo.ThumbnailURL, &photo.ThumbnailKey, &photo.MediumURL, &photo.MediumKey,
+		&photo.Caption, &photo.AltText,
+		&photo.CreatedAt, &photo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	photo.Width = int(width.Int64)
+	photo.Height = int(height.Int64)
+	return photo, nil
+}
+
 func (r *photoRepository) Create(ctx context.Context, photo *models.ResourcePhoto) error {
 	query := `
 		INSERT INTO resource_photos (
 			resource_id, url, storage_key, file_name, file_size,
-			mime_type, width, height, is_primary, display_order
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			mime_type, width, height, is_primary, display_order,
+			thumbnail_url, thumbnail_key, medium_url, medium_key,
+			caption, alt_text
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		photo.ResourceID, photo.URL, photo.StorageKey, photo.FileName, photo.FileSize,
 		photo.MimeType, photo.Width, photo.Height, photo.IsPrimary, photo.DisplayOrder,
+		nullString(photo.ThumbnailURL), nullString(photo.ThumbnailKey),
+		nullString(photo.MediumURL), nullString(photo.MediumKey),
+		nullString(photo.Caption), nullString(photo.AltText),
 	).Scan(&photo.ID, &photo.CreatedAt, &photo.UpdatedAt)
 
 	if err != nil {
@@ -48,23 +87,11 @@ func (r *photoRepository) Create(ctx context.Context, photo *models.ResourcePhot
 }
 
 func (r *photoRepository) GetByID(ctx context.Context, id int64) (*models.ResourcePhoto, error) {
-	query := `
-		SELECT id, resource_id, url, storage_key, file_name, file_size,
-			mime_type, width, height, is_primary, display_order,
-			created_at, updated_at
-		FROM resource_photos
-		WHERE id = $1
-	`
-
-	photo := &models.ResourcePhoto{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&photo.ID, &photo.ResourceID, &photo.URL, &photo.StorageKey, &photo.FileName,
-		&photo.FileSize, &photo.MimeType, &photo.Width, &photo.Height,
-		&photo.IsPrimary, &photo.DisplayOrder, &photo.CreatedAt, &photo.UpdatedAt,
-	)
+	query := `SELECT ` + photoColumns + ` FROM resource_photos WHERE id = $1`
 
+	photo, err := scanPhoto(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("фото не найдено")
+		return nil, ErrPhotoNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -75,15 +102,13 @@ func (r *photoRepository) GetByID(ctx context.Context, id int64) (*models.Resour
 
 func (r *photoRepository) GetByResourceID(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error) {
 	query := `
-		SELECT id, resource_id, url, storage_key, file_name, file_size,
-			mime_type, width, height, is_primary, display_order,
-			created_at, updated_at
+		SELECT ` + photoColumns + `
 		FROM resource_photos
 		WHERE resource_id = $1
-		ORDER BY is_primary DESC, display_order ASC, created_at ASC
+		ORDER BY display_order ASC, id ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, resourceID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, resourceID)
 	if err != nil {
 		return nil, err
 	}
@@ -91,12 +116,7 @@ func (r *photoRepository) GetByResourceID(ctx context.Context, resourceID int64)
 
 	photos := make([]*models.ResourcePhoto, 0)
 	for rows.Next() {
-		photo := &models.ResourcePhoto{}
-		err := rows.Scan(
-			&photo.ID, &photo.ResourceID, &photo.URL, &photo.StorageKey, &photo.FileName,
-			&photo.FileSize, &photo.MimeType, &photo.Width, &photo.Height,
-			&photo.IsPrimary, &photo.DisplayOrder, &photo.CreatedAt, &photo.UpdatedAt,
-		)
+		photo, err := scanPhoto(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -106,9 +126,22 @@ func (r *photoRepository) GetByResourceID(ctx context.Context, resourceID int64)
 	return photos, rows.Err()
 }
 
+func (r *photoRepository) ListForUpdate(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error) {
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT id FROM resources WHERE id = $1 FOR UPDATE`, resourceID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByResourceID(ctx, resourceID)
+}
+
 func (r *photoRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM resource_photos WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -119,14 +152,14 @@ func (r *photoRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("фото не найдено")
+		return ErrPhotoNotFound
 	}
 
 	return nil
 }
 
 func (r *photoRepository) SetPrimary(ctx context.Context, id int64, resourceID int64) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -155,6 +188,23 @@ func (r *photoRepository) SetPrimary(ctx context.Context, id int64, resourceID i
 
 func (r *photoRepository) UpdateOrder(ctx context.Context, id int64, order int) error {
 	query := `UPDATE resource_photos SET display_order = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, order, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, order, id)
 	return err
 }
+
+func (r *photoRepository) UpdateDetails(ctx context.Context, id int64, details models.PhotoDetails) error {
+	query := `UPDATE resource_photos SET caption = $1, alt_text = $2 WHERE id = $3`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, nullString(details.Caption), nullString(details.AltText), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrPhotoNotFound
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ReminderRepository defines the interface for booking reminder bookkeeping
+type ReminderRepository interface {
+	ClaimDue(ctx context.Context, offset time.Duration, limit int) ([]int64, error)
+}
+
+// reminderRepository implements ReminderRepository interface with PostgreSQL storage
+type reminderRepository struct {
+	db *sql.DB
+}
+
+// NewReminderRepository creates a new instance of ReminderRepository
+func NewReminderRepository(db *sql.DB) ReminderRepository {
+	return &reminderRepository{
+		db: db,
+	}
+}
+
+// ClaimDue отмечает напоминание с данным смещением для бронирований, у которых
+// наступило время напоминания, и возвращает их ID. Бронирования, созданные
+// уже внутри окна напоминания, пропускаются. Конкурентный экземпляр, выбравший
+// те же бронирования, упрется в уникальный ключ и ничего не получит, поэтому
+// вызывать нужно внутри Transactor.WithinTx вместе с публикацией событий.
+func (r *reminderRepository) ClaimDue(ctx context.Context, offset time.Duration, limit int) ([]int64, error) {
+	query := `
+		INSERT INTO booking_reminders (booking_id, offset_minutes)
+		SELECT b.id, $1
+		FROM bookings b
+		WHERE b.status <> 'cancelled'
+			AND b.start_time > NOW()
+			AND b.start_time <= NOW() + $1 * INTERVAL '1 minute'
+			AND b.created_at <= b.start_time - $1 * INTERVAL '1 minute'
+			AND NOT EXISTS (
+				SELECT 1 FROM booking_reminders br
+				WHERE br.booking_id = b.id AND br.offset_minutes = $1
+			)
+		ORDER BY b.start_time
+		LIMIT $2
+		ON CONFLICT (booking_id, offset_minutes) DO NOTHING
+		RETURNING booking_id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, int(offset.Minutes()), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -20,6 +20,8 @@ type ResourceRepository interface {
 	Update(ctx context.Context, resource *models.Resource) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context) ([]*models.Resource, error)
+	GetSchedules(ctx context.Context, resourceID int64) ([]*models.ResourceSchedule, error)
+	GetPricingRules(ctx context.Context, resourceID int64) ([]*models.PricingRule, error)
 }
 
 // resourceRepository implements ResourceRepository interface with PostgreSQL storage
@@ -36,8 +38,9 @@ func NewResourceRepository(db *sql.DB) ResourceRepository {
 
 func (r *resourceRepository) Create(ctx context.Context, resource *models.Resource) error {
 	query := `
-		INSERT INTO resources (name, description, capacity, owner_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO resources (name, description, capacity, owner_id, price_per_hour, currency,
+			requires_prepayment, deposit_percent, balance_due_hours, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
@@ -45,11 +48,17 @@ func (r *resourceRepository) Create(ctx context.Context, resource *models.Resour
 	resource.CreatedAt = now
 	resource.UpdatedAt = now
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		resource.Name,
 		resource.Description,
 		resource.Capacity,
 		resource.OwnerID,
+		resource.PricePerHour,
+		resource.Currency,
+		resource.RequiresPrepayment,
+		resource.DepositPercent,
+		resource.BalanceDueHours,
+		resource.Timezone,
 		resource.CreatedAt,
 		resource.UpdatedAt,
 	).Scan(&resource.ID)
@@ -63,21 +72,31 @@ func (r *resourceRepository) Create(ctx context.Context, resource *models.Resour
 
 func (r *resourceRepository) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
 	query := `
-		SELECT r.id, r.name, r.description, r.capacity, r.owner_id, r.created_at, r.updated_at, u.name as owner_name
+		SELECT r.id, r.name, r.description, r.capacity, r.owner_id, r.category_id, r.price_per_hour,
+			r.currency, r.requires_prepayment, r.deposit_percent, r.balance_due_hours, r.timezone,
+			r.created_at, r.updated_at, u.name as owner_name
 		FROM resources r
 		LEFT JOIN users u ON r.owner_id = u.id
 		WHERE r.id = $1
 	`
 
 	resource := &models.Resource{}
-	var ownerID sql.NullInt64
+	var ownerID, categoryID sql.NullInt64
+	var depositPercent sql.NullFloat64
 	var ownerName sql.NullString
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&resource.ID,
 		&resource.Name,
 		&resource.Description,
 		&resource.Capacity,
 		&ownerID,
+		&categoryID,
+		&resource.PricePerHour,
+		&resource.Currency,
+		&resource.RequiresPrepayment,
+		&depositPercent,
+		&resource.BalanceDueHours,
+		&resource.Timezone,
 		&resource.CreatedAt,
 		&resource.UpdatedAt,
 		&ownerName,
@@ -91,6 +110,8 @@ func (r *resourceRepository) GetByID(ctx context.Context, id int64) (*models.Res
 	}
 
 	resource.OwnerID = models.NullInt64ToPtr(ownerID)
+	resource.CategoryID = models.NullInt64ToPtr(categoryID)
+	resource.DepositPercent = models.NullFloat64ToPtr(depositPercent)
 	if ownerName.Valid {
 		resource.OwnerName = ownerName.String
 	}
@@ -101,17 +122,24 @@ func (r *resourceRepository) GetByID(ctx context.Context, id int64) (*models.Res
 func (r *resourceRepository) Update(ctx context.Context, resource *models.Resource) error {
 	query := `
 		UPDATE resources
-		SET name = $1, description = $2, capacity = $3, owner_id = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, description = $2, capacity = $3, owner_id = $4, price_per_hour = $5, currency = $6,
+			requires_prepayment = $7, deposit_percent = $8, balance_due_hours = $9, timezone = $10, updated_at = $11
+		WHERE id = $12
 	`
 
 	resource.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
 		resource.Name,
 		resource.Description,
 		resource.Capacity,
 		resource.OwnerID,
+		resource.PricePerHour,
+		resource.Currency,
+		resource.RequiresPrepayment,
+		resource.DepositPercent,
+		resource.BalanceDueHours,
+		resource.Timezone,
 		resource.UpdatedAt,
 		resource.ID,
 	)
@@ -135,7 +163,7 @@ func (r *resourceRepository) Update(ctx context.Context, resource *models.Resour
 func (r *resourceRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM resources WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -154,7 +182,8 @@ func (r *resourceRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *resourceRepository) List(ctx context.Context) ([]*models.Resource, error) {
 	query := `
-		SELECT r.id, r.name, r.description, r.capacity, r.owner_id, r.created_at, r.updated_at, u.name as owner_name
+		SELECT r.id, r.name, r.description, r.capacity, r.owner_id, r.price_per_hour, r.currency,
+			r.timezone, r.created_at, r.updated_at, u.name as owner_name
 		FROM resources r
 		LEFT JOIN users u ON r.owner_id = u.id
 		ORDER BY r.created_at DESC
@@ -177,6 +206,9 @@ func (r *resourceRepository) List(ctx context.Context) ([]*models.Resource, erro
 			&resource.Description,
 			&resource.Capacity,
 			&ownerID,
+			&resource.PricePerHour,
+			&resource.Currency,
+			&resource.Timezone,
 			&resource.CreatedAt,
 			&resource.UpdatedAt,
 			&ownerName,
@@ -207,6 +239,76 @@ func (r *resourceRepository) List(ctx context.Context) ([]*models.Resource, erro
 	return resources, nil
 }
 
+// GetSchedules retrieves weekly opening hours of a resource in its local time
+func (r *resourceRepository) GetSchedules(ctx context.Context, resourceID int64) ([]*models.ResourceSchedule, error) {
+	query := `
+		SELECT resource_id, day_of_week, to_char(open_time, 'HH24:MI'), to_char(close_time, 'HH24:MI'),
+			COALESCE(is_closed, false)
+		FROM resource_schedules
+		WHERE resource_id = $1
+		ORDER BY day_of_week
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]*models.ResourceSchedule, 0)
+	for rows.Next() {
+		var sc models.ResourceSchedule
+		if err := rows.Scan(&sc.ResourceID, &sc.DayOfWeek, &sc.OpenTime, &sc.CloseTime, &sc.IsClosed); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, &sc)
+	}
+
+	return schedules, rows.Err()
+}
+
+// GetPricingRules retrieves active pricing rules of a resource; windows are in its local time
+func (r *resourceRepository) GetPricingRules(ctx context.Context, resourceID int64) ([]*models.PricingRule, error) {
+	query := `
+		SELECT id, resource_id, name, price, duration_minutes, day_of_week,
+			to_char(time_from, 'HH24:MI'), to_char(time_to, 'HH24:MI')
+		FROM resource_pricing
+		WHERE resource_id = $1 AND COALESCE(is_active, true)
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]*models.PricingRule, 0)
+	for rows.Next() {
+		var rule models.PricingRule
+		var dayOfWeek sql.NullInt64
+		var timeFrom, timeTo sql.NullString
+		err := rows.Scan(&rule.ID, &rule.ResourceID, &rule.Name, &rule.Price, &rule.DurationMinutes,
+			&dayOfWeek, &timeFrom, &timeTo)
+		if err != nil {
+			return nil, err
+		}
+		if dayOfWeek.Valid {
+			day := int(dayOfWeek.Int64)
+			rule.DayOfWeek = &day
+		}
+		if timeFrom.Valid {
+			rule.TimeFrom = &timeFrom.String
+		}
+		if timeTo.Valid {
+			rule.TimeTo = &timeTo.String
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
 func (r *resourceRepository) loadPhotosForResources(ctx context.Context, resources []*models.Resource) error {
 	if len(resources) == 0 {
 		return nil
@@ -224,7 +326,11 @@ func (r *resourceRepository) loadPhotosForResources(ctx context.Context, resourc
 	// Build query with IN clause
 	query := `
 		SELECT id, resource_id, url, storage_key, file_name, file_size, mime_type,
-		       width, height, is_primary, display_order, created_at, updated_at
+		       width, height, is_primary, display_order,
+		       COALESCE(thumbnail_url, ''), COALESCE(thumbnail_key, ''),
+		       COALESCE(medium_url, ''), COALESCE(medium_key, ''),
+		       COALESCE(caption, ''), COALESCE(alt_text, ''),
+		       created_at, updated_at
 		FROM resource_photos
 		WHERE resource_id = ANY($1)
 		ORDER BY resource_id, display_order, id
@@ -251,6 +357,12 @@ func (r *resourceRepository) loadPhotosForResources(ctx context.Context, resourc
 			&height,
 			&photo.IsPrimary,
 			&photo.DisplayOrder,
+			&photo.ThumbnailURL,
+			&photo.ThumbnailKey,
+			&photo.MediumURL,
+			&photo.MediumKey,
+			&photo.Caption,
+			&photo.AltText,
 			&photo.CreatedAt,
 			&photo.UpdatedAt,
 		)
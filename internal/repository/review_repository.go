@@ -4,23 +4,131 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/lib/pq"
+
 	"smartbooking/internal/models"
 )
 
 var (
-	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewNotFound        = errors.New("review not found")
+	ErrReviewAlreadyExists   = errors.New("booking has already been reviewed")
+	ErrReviewAlreadyReported = errors.New("review has already been reported by this user")
+	ErrReviewPhotoNotFound   = errors.New("review photo not found")
+	ErrReviewAlreadyVoted    = errors.New("review has already been marked helpful by this user")
+	ErrReviewVoteNotFound    = errors.New("review has not been marked helpful by this user")
 )
 
+const reviewColumns = `rv.id, rv.user_id, rv.resource_id, rv.booking_id, rv.rating,
+	rv.cleanliness_rating, rv.value_rating, rv.location_rating, COALESCE(rv.comment, ''),
+	COALESCE(rv.is_verified, false), rv.status, rv.helpful_count, rv.created_at, rv.updated_at,
+	rr.id, rr.owner_id, rr.text, rr.created_at, rr.updated_at`
+
+const reviewTables = `reviews rv LEFT JOIN review_replies rr ON rr.review_id = rv.id`
+
+// ReviewSort порядок списка отзывов. При равенстве ключа сортировки отзывы
+// идут от новых к старым.
+type ReviewSort string
+
+const (
+	ReviewSortNewest  ReviewSort = "newest"
+	ReviewSortHighest ReviewSort = "highest"
+	ReviewSortLowest  ReviewSort = "lowest"
+	ReviewSortHelpful ReviewSort = "helpful"
+)
+
+// ReviewCursor позиция последнего отзыва предыдущей страницы. Key — оценка
+// для highest/lowest и число отметок "полезно" для helpful.
+type ReviewCursor struct {
+	Key       int
+	CreatedAt time.Time
+	ID        int64
+}
+
+// ReviewFilter фильтр и страница списка отзывов; nil-поля не фильтруют.
+// В список попадают только опубликованные отзывы, IncludePending добавляет
+// ожидающие модерации — их показывают только автору.
+type ReviewFilter struct {
+	Verified       *bool
+	Rating         *int
+	IncludePending bool
+	Sort           ReviewSort
+	After          *ReviewCursor
+	Limit          int
+}
+
+// where дописывает к условию статус, фильтры и позицию курсора; order — ORDER BY для Sort
+func (f ReviewFilter) where(where string, args []interface{}) (string, []interface{}, string) {
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.IncludePending {
+		where += " AND rv.status IN ('published', 'pending')"
+	} else {
+		where += " AND rv.status = 'published'"
+	}
+	if f.Verified != nil {
+		where += " AND COALESCE(rv.is_verified, false) = " + arg(*f.Verified)
+	}
+	if f.Rating != nil {
+		where += " AND rv.rating = " + arg(*f.Rating)
+	}
+
+	order := "rv.created_at DESC, rv.id DESC"
+	switch f.Sort {
+	case ReviewSortHighest:
+		order = "rv.rating DESC, " + order
+	case ReviewSortLowest:
+		order = "rv.rating ASC, " + order
+	case ReviewSortHelpful:
+		order = "rv.helpful_count DESC, " + order
+	}
+
+	if c := f.After; c != nil {
+		t, id := arg(c.CreatedAt), arg(c.ID)
+		switch f.Sort {
+		case ReviewSortHighest:
+			where += fmt.Sprintf(" AND (rv.rating, rv.created_at, rv.id) < (%s, %s, %s)", arg(c.Key), t, id)
+		case ReviewSortLowest:
+			key := arg(c.Key)
+			where += fmt.Sprintf(" AND (rv.rating > %s OR (rv.rating = %s AND (rv.created_at, rv.id) < (%s, %s)))", key, key, t, id)
+		case ReviewSortHelpful:
+			where += fmt.Sprintf(" AND (rv.helpful_count, rv.created_at, rv.id) < (%s, %s, %s)", arg(c.Key), t, id)
+		default:
+			where += fmt.Sprintf(" AND (rv.created_at, rv.id) < (%s, %s)", t, id)
+		}
+	}
+
+	if f.Limit > 0 {
+		order += " LIMIT " + arg(f.Limit)
+	}
+	return where, args, order
+}
+
 type ReviewRepository interface {
 	Create(ctx context.Context, review *models.Review) error
 	GetByID(ctx context.Context, id int64) (*models.Review, error)
-	GetByResource(ctx context.Context, resourceID int64) ([]*models.Review, error)
-	GetByUser(ctx context.Context, userID int64) ([]*models.Review, error)
+	GetByResource(ctx context.Context, resourceID int64, filter ReviewFilter) ([]*models.Review, error)
+	GetByUser(ctx context.Context, userID int64, filter ReviewFilter) ([]*models.Review, error)
 	Update(ctx context.Context, review *models.Review) error
 	Delete(ctx context.Context, id int64) error
-	GetAverageRating(ctx context.Context, resourceID int64) (float64, error)
+	GetRatingSummary(ctx context.Context, resourceID int64) (*models.RatingSummary, error)
+	GetRatingTrend(ctx context.Context, resourceID int64, since time.Time, timezone string) ([]models.RatingTrendPoint, error)
+	SaveReply(ctx context.Context, reply *models.ReviewReply) (created bool, err error)
+	CreateReport(ctx context.Context, report *models.ReviewReport) error
+	ListModerationQueue(ctx context.Context, limit, offset int) ([]*models.Review, error)
+	ListOpenReports(ctx context.Context, reviewIDs []int64) ([]*models.ReviewReport, error)
+	Moderate(ctx context.Context, id int64, status models.ReviewStatus) error
+	CreatePhoto(ctx context.Context, photo *models.ReviewPhoto) error
+	GetPhoto(ctx context.Context, id int64) (*models.ReviewPhoto, error)
+	ListPhotosForUpdate(ctx context.Context, reviewID int64) ([]*models.ReviewPhoto, error)
+	DeletePhoto(ctx context.Context, id int64) error
+	AddVote(ctx context.Context, reviewID, userID int64) error
+	RemoveVote(ctx context.Context, reviewID, userID int64) error
 }
 
 type reviewRepository struct {
@@ -35,8 +143,9 @@ func NewReviewRepository(db *sql.DB) ReviewRepository {
 
 func (r *reviewRepository) Create(ctx context.Context, review *models.Review) error {
 	query := `
-		INSERT INTO reviews (user_id, resource_id, booking_id, rating, comment, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO reviews (user_id, resource_id, booking_id, rating, cleanliness_rating, value_rating, location_rating,
+			comment, is_verified, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
@@ -44,156 +153,375 @@ func (r *reviewRepository) Create(ctx context.Context, review *models.Review) er
 	review.CreatedAt = now
 	review.UpdatedAt = now
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		review.UserID,
 		review.ResourceID,
 		review.BookingID,
 		review.Rating,
+		review.Cleanliness,
+		review.Value,
+		review.Location,
 		review.Comment,
+		review.IsVerified,
+		review.Status,
 		review.CreatedAt,
 		review.UpdatedAt,
 	).Scan(&review.ID)
 
-	return err
+	return mapReviewError(err)
 }
 
+// GetByID возвращает отзыв в любом статусе; кому его можно показать, решает сервис
 func (r *reviewRepository) GetByID(ctx context.Context, id int64) (*models.Review, error) {
 	query := `
-		SELECT id, user_id, resource_id, booking_id, rating, comment, created_at, updated_at
-		FROM reviews
-		WHERE id = $1
+		SELECT ` + reviewColumns + `
+		FROM ` + reviewTables + `
+		WHERE rv.id = $1
 	`
 
-	review := &models.Review{}
-	var bookingID sql.NullInt64
+	review, err := scanReview(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&review.ID,
-		&review.UserID,
-		&review.ResourceID,
-		&bookingID,
-		&review.Rating,
-		&review.Comment,
-		&review.CreatedAt,
-		&review.UpdatedAt,
+	if err := r.attachPhotos(ctx, []*models.Review{review}); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetByResource возвращает опубликованные отзывы ресурса
+func (r *reviewRepository) GetByResource(ctx context.Context, resourceID int64, filter ReviewFilter) ([]*models.Review, error) {
+	filter.IncludePending = false
+	return r.list(ctx, "rv.resource_id = $1", resourceID, filter)
+}
+
+// GetByUser возвращает опубликованные отзывы пользователя, с IncludePending —
+// еще и ожидающие модерации. Скрытые модератором не возвращаются никогда.
+func (r *reviewRepository) GetByUser(ctx context.Context, userID int64, filter ReviewFilter) ([]*models.Review, error) {
+	return r.list(ctx, "rv.user_id = $1", userID, filter)
+}
+
+func (r *reviewRepository) list(ctx context.Context, where string, id int64, filter ReviewFilter) ([]*models.Review, error) {
+	where, args, order := filter.where(where, []interface{}{id})
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM ` + reviewTables + `
+		WHERE ` + where + `
+		ORDER BY ` + order
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanWithPhotos(ctx, rows)
+}
+
+func (r *reviewRepository) Update(ctx context.Context, review *models.Review) error {
+	query := `
+		UPDATE reviews
+		SET rating = $1, cleanliness_rating = $2, value_rating = $3, location_rating = $4,
+			comment = $5, status = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	review.UpdatedAt = time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		review.Rating,
+		review.Cleanliness,
+		review.Value,
+		review.Location,
+		review.Comment,
+		review.Status,
+		review.UpdatedAt,
+		review.ID,
 	)
 
-	if bookingID.Valid {
-		review.BookingID = &bookingID.Int64
+	if err != nil {
+		return err
 	}
 
-	if err == sql.ErrNoRows {
-		return nil, ErrReviewNotFound
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrReviewNotFound
 	}
+
+	return nil
+}
+
+func (r *reviewRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM reviews WHERE id = $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrReviewNotFound
+	}
+
+	return nil
+}
+
+// GetRatingSummary читает сводку оценок из resource_rating_stats, которую
+// поддерживает триггер на reviews. Тренд не заполняется.
+func (r *reviewRepository) GetRatingSummary(ctx context.Context, resourceID int64) (*models.RatingSummary, error) {
+	query := `
+		SELECT review_count, rating_sum, rating_1, rating_2, rating_3, rating_4, rating_5,
+			verified_count, verified_sum,
+			cleanliness_count, cleanliness_sum, value_count, value_sum, location_count, location_sum
+		FROM resource_rating_stats
+		WHERE resource_id = $1
+	`
+
+	var counts [5]int
+	var ratingSum, verifiedSum int
+	var subCounts, subSums [3]int
+	summary := &models.RatingSummary{ResourceID: resourceID}
+
+	err := r.db.QueryRowContext(ctx, query, resourceID).Scan(
+		&summary.ReviewCount,
+		&ratingSum,
+		&counts[0], &counts[1], &counts[2], &counts[3], &counts[4],
+		&summary.VerifiedCount,
+		&verifiedSum,
+		&subCounts[0], &subSums[0],
+		&subCounts[1], &subSums[1],
+		&subCounts[2], &subSums[2],
+	)
+	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 
-	return review, nil
+	summary.Histogram = make(map[int]int, len(counts))
+	for i, n := range counts {
+		summary.Histogram[i+1] = n
+	}
+	if summary.ReviewCount > 0 {
+		summary.AverageRating = float64(ratingSum) / float64(summary.ReviewCount)
+	}
+	if summary.VerifiedCount > 0 {
+		summary.VerifiedAverageRating = float64(verifiedSum) / float64(summary.VerifiedCount)
+	}
+	for i, target := range []**float64{&summary.Cleanliness, &summary.Value, &summary.Location} {
+		if subCounts[i] > 0 {
+			avg := float64(subSums[i]) / float64(subCounts[i])
+			*target = &avg
+		}
+	}
+
+	return summary, nil
 }
 
-func (r *reviewRepository) GetByResource(ctx context.Context, resourceID int64) ([]*models.Review, error) {
+// GetRatingTrend возвращает среднюю оценку опубликованных отзывов по месяцам
+// начиная с since. Месяцы считаются в часовом поясе timezone, месяцы без
+// отзывов пропускаются.
+func (r *reviewRepository) GetRatingTrend(ctx context.Context, resourceID int64, since time.Time, timezone string) ([]models.RatingTrendPoint, error) {
 	query := `
-		SELECT id, user_id, resource_id, booking_id, rating, comment, created_at, updated_at
+		SELECT to_char(date_trunc('month', created_at AT TIME ZONE $3), 'YYYY-MM') as month,
+			AVG(rating), COUNT(*)
 		FROM reviews
-		WHERE resource_id = $1
-		ORDER BY created_at DESC
+		WHERE resource_id = $1 AND status = 'published' AND created_at >= $2
+		GROUP BY month
+		ORDER BY month
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, resourceID)
+	rows, err := r.db.QueryContext(ctx, query, resourceID, since, timezone)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	reviews := make([]*models.Review, 0)
+	trend := make([]models.RatingTrendPoint, 0)
 	for rows.Next() {
-		review := &models.Review{}
-		var bookingID sql.NullInt64
-
-		err := rows.Scan(
-			&review.ID,
-			&review.UserID,
-			&review.ResourceID,
-			&bookingID,
-			&review.Rating,
-			&review.Comment,
-			&review.CreatedAt,
-			&review.UpdatedAt,
-		)
-		if err != nil {
+		var point models.RatingTrendPoint
+		if err := rows.Scan(&point.Month, &point.AverageRating, &point.ReviewCount); err != nil {
 			return nil, err
 		}
+		trend = append(trend, point)
+	}
 
-		if bookingID.Valid {
-			review.BookingID = &bookingID.Int64
-		}
+	return trend, rows.Err()
+}
 
-		reviews = append(reviews, review)
+// SaveReply создает ответ на отзыв или заменяет текст существующего.
+// created сообщает, был ли ответ создан впервые.
+func (r *reviewRepository) SaveReply(ctx context.Context, reply *models.ReviewReply) (bool, error) {
+	query := `
+		INSERT INTO review_replies (review_id, owner_id, text, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (review_id) DO UPDATE
+		SET owner_id = EXCLUDED.owner_id, text = EXCLUDED.text, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at, xmax = 0
+	`
+
+	var created bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		reply.ReviewID,
+		reply.OwnerID,
+		reply.Text,
+		time.Now(),
+	).Scan(&reply.ID, &reply.CreatedAt, &reply.UpdatedAt, &created)
+
+	return created, err
+}
+
+// CreateReport сохраняет жалобу; повторная жалоба того же пользователя
+// возвращает ErrReviewAlreadyReported
+func (r *reviewRepository) CreateReport(ctx context.Context, report *models.ReviewReport) error {
+	query := `
+		INSERT INTO review_reports (review_id, user_id, reason, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (review_id, user_id) DO NOTHING
+		RETURNING id
+	`
+
+	report.CreatedAt = time.Now()
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		report.ReviewID,
+		report.UserID,
+		report.Reason,
+		report.CreatedAt,
+	).Scan(&report.ID)
+	if err == sql.ErrNoRows {
+		return ErrReviewAlreadyReported
 	}
+	return err
+}
 
-	return reviews, rows.Err()
+// ListModerationQueue возвращает отзывы, ждущие модерации, и отзывы с открытыми
+// жалобами, начиная с самых старых
+func (r *reviewRepository) ListModerationQueue(ctx context.Context, limit, offset int) ([]*models.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM ` + reviewTables + `
+		WHERE rv.status = 'pending'
+		   OR EXISTS (SELECT 1 FROM review_reports rp WHERE rp.review_id = rv.id AND rp.resolved_at IS NULL)
+		ORDER BY rv.created_at
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanWithPhotos(ctx, rows)
 }
 
-func (r *reviewRepository) GetByUser(ctx context.Context, userID int64) ([]*models.Review, error) {
+func (r *reviewRepository) ListOpenReports(ctx context.Context, reviewIDs []int64) ([]*models.ReviewReport, error) {
 	query := `
-		SELECT id, user_id, resource_id, booking_id, rating, comment, created_at, updated_at
-		FROM reviews
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT id, review_id, user_id, reason, created_at
+		FROM review_reports
+		WHERE review_id = ANY($1) AND resolved_at IS NULL
+		ORDER BY created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(reviewIDs))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	reviews := make([]*models.Review, 0)
+	reports := make([]*models.ReviewReport, 0)
 	for rows.Next() {
-		review := &models.Review{}
-		var bookingID sql.NullInt64
-
-		err := rows.Scan(
-			&review.ID,
-			&review.UserID,
-			&review.ResourceID,
-			&bookingID,
-			&review.Rating,
-			&review.Comment,
-			&review.CreatedAt,
-			&review.UpdatedAt,
-		)
-		if err != nil {
+		report := &models.ReviewReport{}
+		if err := rows.Scan(&report.ID, &report.ReviewID, &report.UserID, &report.Reason, &report.CreatedAt); err != nil {
 			return nil, err
 		}
+		reports = append(reports, report)
+	}
 
-		if bookingID.Valid {
-			review.BookingID = &bookingID.Int64
-		}
+	return reports, rows.Err()
+}
 
-		reviews = append(reviews, review)
+// Moderate устанавливает статус отзыва по решению модератора и закрывает жалобы на него
+func (r *reviewRepository) Moderate(ctx context.Context, id int64, status models.ReviewStatus) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE reviews SET status = $1 WHERE id = $2`, status, id)
+	if err != nil {
+		return err
 	}
 
-	return reviews, rows.Err()
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrReviewNotFound
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE review_reports SET resolved_at = CURRENT_TIMESTAMP
+		WHERE review_id = $1 AND resolved_at IS NULL
+	`, id)
+	return err
 }
 
-func (r *reviewRepository) Update(ctx context.Context, review *models.Review) error {
+func (r *reviewRepository) CreatePhoto(ctx context.Context, photo *models.ReviewPhoto) error {
 	query := `
-		UPDATE reviews
-		SET rating = $1, comment = $2, updated_at = $3
-		WHERE id = $4
+		INSERT INTO review_photos (review_id, url, storage_key, file_name, file_size, mime_type, display_order, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			(SELECT COUNT(*) FROM review_photos WHERE review_id = $1), $7)
+		RETURNING id, display_order
 	`
 
-	review.UpdatedAt = time.Now()
+	photo.CreatedAt = time.Now()
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		photo.ReviewID,
+		photo.URL,
+		photo.StorageKey,
+		photo.FileName,
+		photo.FileSize,
+		photo.MimeType,
+		photo.CreatedAt,
+	).Scan(&photo.ID, &photo.DisplayOrder)
+}
 
-	result, err := r.db.ExecContext(ctx, query,
-		review.Rating,
-		review.Comment,
-		review.UpdatedAt,
-		review.ID,
-	)
+func (r *reviewRepository) GetPhoto(ctx context.Context, id int64) (*models.ReviewPhoto, error) {
+	query := `SELECT ` + reviewPhotoColumns + ` FROM review_photos WHERE id = $1`
+
+	photo, err := scanReviewPhoto(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReviewPhotoNotFound
+	}
+	return photo, err
+}
 
+// ListPhotosForUpdate возвращает фотографии отзыва; внутри транзакции строка отзыва
+// блокируется, чтобы параллельные загрузки не превысили лимит
+func (r *reviewRepository) ListPhotosForUpdate(ctx context.Context, reviewID int64) ([]*models.ReviewPhoto, error) {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `SELECT 1 FROM reviews WHERE id = $1 FOR UPDATE`, reviewID); err != nil {
+		return nil, err
+	}
+
+	photos, err := r.listPhotos(ctx, []int64{reviewID})
+	if err != nil {
+		return nil, err
+	}
+	return photos[reviewID], nil
+}
+
+func (r *reviewRepository) DeletePhoto(ctx context.Context, id int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM review_photos WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
@@ -202,18 +530,21 @@ func (r *reviewRepository) Update(ctx context.Context, review *models.Review) er
 	if err != nil {
 		return err
 	}
-
 	if rows == 0 {
-		return ErrReviewNotFound
+		return ErrReviewPhotoNotFound
 	}
-
 	return nil
 }
 
-func (r *reviewRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM reviews WHERE id = $1`
+// AddVote отмечает отзыв полезным; helpful_count обновляет триггер
+func (r *reviewRepository) AddVote(ctx context.Context, reviewID, userID int64) error {
+	query := `
+		INSERT INTO review_votes (review_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (review_id, user_id) DO NOTHING
+	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, reviewID, userID)
 	if err != nil {
 		return err
 	}
@@ -222,26 +553,182 @@ func (r *reviewRepository) Delete(ctx context.Context, id int64) error {
 	if err != nil {
 		return err
 	}
-
 	if rows == 0 {
-		return ErrReviewNotFound
+		return ErrReviewAlreadyVoted
 	}
+	return nil
+}
+
+func (r *reviewRepository) RemoveVote(ctx context.Context, reviewID, userID int64) error {
+	query := `DELETE FROM review_votes WHERE review_id = $1 AND user_id = $2`
 
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, reviewID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrReviewVoteNotFound
+	}
 	return nil
 }
 
-func (r *reviewRepository) GetAverageRating(ctx context.Context, resourceID int64) (float64, error) {
+const reviewPhotoColumns = `id, review_id, url, storage_key, file_name, file_size, mime_type, display_order, created_at`
+
+func (r *reviewRepository) listPhotos(ctx context.Context, reviewIDs []int64) (map[int64][]*models.ReviewPhoto, error) {
 	query := `
-		SELECT COALESCE(AVG(rating), 0)
-		FROM reviews
-		WHERE resource_id = $1
+		SELECT ` + reviewPhotoColumns + `
+		FROM review_photos
+		WHERE review_id = ANY($1)
+		ORDER BY display_order, id
 	`
 
-	var avgRating float64
-	err := r.db.QueryRowContext(ctx, query, resourceID).Scan(&avgRating)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(reviewIDs))
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return avgRating, nil
+	photos := make(map[int64][]*models.ReviewPhoto)
+	for rows.Next() {
+		photo, err := scanReviewPhoto(rows)
+		if err != nil {
+			return nil, err
+		}
+		photos[photo.ReviewID] = append(photos[photo.ReviewID], photo)
+	}
+
+	return photos, rows.Err()
+}
+
+// attachPhotos загружает фотографии для списка отзывов одним запросом
+func (r *reviewRepository) attachPhotos(ctx context.Context, reviews []*models.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(reviews))
+	for i, review := range reviews {
+		ids[i] = review.ID
+	}
+
+	photos, err := r.listPhotos(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, review := range reviews {
+		review.Photos = photos[review.ID]
+		if review.Photos == nil {
+			review.Photos = []*models.ReviewPhoto{}
+		}
+	}
+	return nil
+}
+
+func (r *reviewRepository) scanWithPhotos(ctx context.Context, rows *sql.Rows) ([]*models.Review, error) {
+	reviews, err := scanReviews(rows)
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.attachPhotos(ctx, reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func scanReviewPhoto(row rowScanner) (*models.ReviewPhoto, error) {
+	photo := &models.ReviewPhoto{}
+	err := row.Scan(
+		&photo.ID, &photo.ReviewID, &photo.URL, &photo.StorageKey, &photo.FileName,
+		&photo.FileSize, &photo.MimeType, &photo.DisplayOrder, &photo.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+func scanReview(row rowScanner) (*models.Review, error) {
+	review := &models.Review{}
+	var bookingID, cleanliness, value, location sql.NullInt64
+	var replyID, replyOwnerID sql.NullInt64
+	var replyText sql.NullString
+	var replyCreatedAt, replyUpdatedAt sql.NullTime
+
+	err := row.Scan(
+		&review.ID,
+		&review.UserID,
+		&review.ResourceID,
+		&bookingID,
+		&review.Rating,
+		&cleanliness,
+		&value,
+		&location,
+		&review.Comment,
+		&review.IsVerified,
+		&review.Status,
+		&review.HelpfulCount,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&replyID,
+		&replyOwnerID,
+		&replyText,
+		&replyCreatedAt,
+		&replyUpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	review.BookingID = models.NullInt64ToPtr(bookingID)
+	review.Cleanliness = nullIntToPtr(cleanliness)
+	review.Value = nullIntToPtr(value)
+	review.Location = nullIntToPtr(location)
+	if replyID.Valid {
+		review.Reply = &models.ReviewReply{
+			ID:        replyID.Int64,
+			ReviewID:  review.ID,
+			OwnerID:   replyOwnerID.Int64,
+			Text:      replyText.String,
+			CreatedAt: replyCreatedAt.Time,
+			UpdatedAt: replyUpdatedAt.Time,
+		}
+	}
+	return review, nil
+}
+
+func scanReviews(rows *sql.Rows) ([]*models.Review, error) {
+	reviews := make([]*models.Review, 0)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+func nullIntToPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+// mapReviewError переводит нарушение UNIQUE(booking_id) в ErrReviewAlreadyExists
+func mapReviewError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrReviewAlreadyExists
+	}
+	return err
 }
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReviewFilterStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ReviewFilter
+		want   string
+	}{
+		{"published only by default", ReviewFilter{}, "rv.status = 'published'"},
+		{"author sees pending", ReviewFilter{IncludePending: true}, "rv.status IN ('published', 'pending')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, _, _ := tt.filter.where("rv.user_id = $1", []interface{}{int64(1)})
+			if !strings.Contains(where, tt.want) {
+				t.Errorf("where = %q, want it to contain %q", where, tt.want)
+			}
+			if strings.Contains(where, "hidden") || strings.Contains(where, "<>") {
+				t.Errorf("where = %q must not select hidden reviews by exclusion", where)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// Transactor runs a function inside a database transaction. Repository methods
+// called with the context passed to fn take part in that transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// sqlTransactor implements Transactor with database/sql transactions
+type sqlTransactor struct {
+	db *sql.DB
+}
+
+// NewTransactor creates a new instance of Transactor
+func NewTransactor(db *sql.DB) Transactor {
+	return &sqlTransactor{
+		db: db,
+	}
+}
+
+// WithinTx выполняет fn в транзакции и фиксирует ее, если fn не вернула ошибку.
+// Вложенный вызов переиспользует уже открытую транзакцию.
+func (t *sqlTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// querier общая часть *sql.DB и *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn возвращает транзакцию из контекста, если она открыта через Transactor, иначе db
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// scopedTx транзакция метода репозитория. Если в контексте уже есть внешняя
+// транзакция, метод работает в ней, а Commit и Rollback оставляются внешнему коду.
+type scopedTx struct {
+	*sql.Tx
+	owned bool
+}
+
+func beginTx(ctx context.Context, db *sql.DB) (*scopedTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return &scopedTx{Tx: tx}, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedTx{Tx: tx, owned: true}, nil
+}
+
+func (t *scopedTx) Commit() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t *scopedTx) Rollback() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
@@ -34,20 +34,24 @@ func NewUserRepository(db *sql.DB) UserRepository {
 
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (name, email, password, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (name, email, password, role, locale, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	if user.Locale == "" {
+		user.Locale = "ru"
+	}
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
 		user.Name,
 		user.Email,
 		user.Password,
 		user.Role,
+		user.Locale,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -61,18 +65,19 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 
 func (r *userRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, role, created_at, updated_at
+		SELECT id, name, email, password, role, locale, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 
 	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -89,18 +94,19 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, role, created_at, updated_at
+		SELECT id, name, email, password, role, locale, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
 
 	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -118,17 +124,18 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET name = $1, email = $2, password = $3, role = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, email = $2, password = $3, role = $4, locale = $5, updated_at = $6
+		WHERE id = $7
 	`
 
 	user.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
 		user.Name,
 		user.Email,
 		user.Password,
 		user.Role,
+		user.Locale,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -152,7 +159,7 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 func (r *userRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM users WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -171,12 +178,12 @@ func (r *userRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *userRepository) List(ctx context.Context) ([]*models.User, error) {
 	query := `
-		SELECT id, name, email, password, role, created_at, updated_at
+		SELECT id, name, email, password, role, locale, created_at, updated_at
 		FROM users
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +198,7 @@ func (r *userRepository) List(ctx context.Context) ([]*models.User, error) {
 			&user.Email,
 			&user.Password,
 			&user.Role,
+			&user.Locale,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
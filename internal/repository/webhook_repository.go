@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"smartbooking/internal/models"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookRepository defines the interface for webhook subscriptions and the delivery log
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id int64) (*models.WebhookSubscription, error)
+	ListSubscriptionsByOwner(ctx context.Context, ownerID int64) ([]*models.WebhookSubscription, error)
+	ListSubscriptionsForEvent(ctx context.Context, ownerID int64, eventType string) ([]*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int, responseBody string) error
+	MarkDeliveryFailed(ctx context.Context, id int64, responseStatus int, responseBody, lastError string, nextAttemptAt *time.Time) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id, subscriptionID int64) error
+}
+
+// webhookRepository implements WebhookRepository interface with PostgreSQL storage
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+const webhookSubscriptionColumns = `id, owner_id, url, secret, event_types, is_active, created_at, updated_at`
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	if err := row.Scan(&s.ID, &s.OwnerID, &s.URL, &s.Secret, pq.Array(&s.EventTypes),
+		&s.IsActive, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (owner_id, url, secret, event_types, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	now := time.Now()
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+	if sub.EventTypes == nil {
+		sub.EventTypes = []string{}
+	}
+
+	return r.db.QueryRowContext(ctx, query,
+		sub.OwnerID,
+		sub.URL,
+		sub.Secret,
+		pq.Array(sub.EventTypes),
+		sub.IsActive,
+		sub.CreatedAt,
+		sub.UpdatedAt,
+	).Scan(&sub.ID)
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	sub, err := scanWebhookSubscription(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	return sub, err
+}
+
+func (r *webhookRepository) ListSubscriptionsByOwner(ctx context.Context, ownerID int64) ([]*models.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE owner_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	return r.listSubscriptions(ctx, query, ownerID)
+}
+
+// ListSubscriptionsForEvent возвращает активные подписки владельца на тип события;
+// подписка с пустым event_types получает все события
+func (r *webhookRepository) ListSubscriptionsForEvent(ctx context.Context, ownerID int64, eventType string) ([]*models.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE owner_id = $1 AND is_active = TRUE
+			AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+		ORDER BY id
+	`
+
+	return r.listSubscriptions(ctx, query, ownerID, eventType)
+}
+
+func (r *webhookRepository) listSubscriptions(ctx context.Context, query string, args ...interface{}) ([]*models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]*models.WebhookSubscription, 0)
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueDelivery добавляет доставку в журнал. Повторная постановка того же события
+// в ту же подписку игнорируется, поэтому повтор события диспетчером безопасен.
+func (r *webhookRepository) EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+		RETURNING id
+	`
+
+	delivery.CreatedAt = time.Now()
+	delivery.Status = models.WebhookDeliveryPending
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		[]byte(delivery.Payload),
+		delivery.CreatedAt,
+	).Scan(&delivery.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// ClaimDueDeliveries забирает доставки к отправке вместе с адресом и секретом подписки
+// и откладывает их на lease, чтобы параллельные воркеры не отправили их дважды
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND s.is_active = TRUE
+				ORDER BY d.next_attempt_at
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING id, subscription_id, event_id, event_type, payload, attempts, created_at
+		)
+		SELECT c.id, c.subscription_id, c.event_id, c.event_type, c.payload, c.attempts, c.created_at,
+			s.url, s.secret
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		ORDER BY c.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload,
+			&d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		d.Status = models.WebhookDeliveryPending
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *webhookRepository) MarkDelivered(ctx context.Context, id int64, responseStatus int, responseBody string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, delivered_at = NOW(),
+			response_status = $2, response_body = $3, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, responseStatus, responseBody)
+	return err
+}
+
+// MarkDeliveryFailed сохраняет результат неудачной попытки. responseStatus == 0 означает,
+// что ответа не было; nextAttemptAt == nil — попытки исчерпаны.
+func (r *webhookRepository) MarkDeliveryFailed(ctx context.Context, id int64, responseStatus int, responseBody, lastError string, nextAttemptAt *time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			response_status = $2,
+			response_body = $3,
+			last_error = $4,
+			status = CASE WHEN $5::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			next_attempt_at = COALESCE($5, next_attempt_at)
+		WHERE id = $1
+	`
+
+	status := sql.NullInt64{Int64: int64(responseStatus), Valid: responseStatus != 0}
+	_, err := r.db.ExecContext(ctx, query, id, status, nullString(responseBody), lastError, nextAttemptAt)
+	return err
+}
+
+// ListDeliveries возвращает журнал доставок подписки, новые первыми
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit, offset int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+			response_status, response_body, last_error, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		var responseStatus sql.NullInt64
+		var responseBody, lastError sql.NullString
+		var deliveredAt sql.NullTime
+
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status,
+			&d.Attempts, &d.NextAttemptAt, &responseStatus, &responseBody, &lastError,
+			&deliveredAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		d.Payload = payload
+		if responseStatus.Valid {
+			status := int(responseStatus.Int64)
+			d.ResponseStatus = &status
+		}
+		d.ResponseBody = responseBody.String
+		d.LastError = lastError.String
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Redeliver ставит доставку в очередь заново с полным запасом попыток,
+// независимо от ее текущего статуса
+func (r *webhookRepository) Redeliver(ctx context.Context, id, subscriptionID int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE id = $1 AND subscription_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package retry задает экспоненциальную задержку повторов и общий цикл
+// обработки очередей: писем, событий outbox и доставок вебхуков
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff экспоненциальная задержка: Base, 2·Base, 4·Base, ... но не больше Max
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay возвращает задержку перед повтором после attempt-й неудачной попытки
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.Max)
+}
+
+// Queue обрабатывает пачку записей, забранных из очереди с арендой. Отправленная
+// запись отмечается через Done. После ошибки Failed получает номер попытки и
+// время следующей, или nil, если исчерпаны MaxAttempts попыток.
+type Queue[T any] struct {
+	Backoff     Backoff
+	MaxAttempts int
+	Attempts    func(item T) int
+	Send        func(ctx context.Context, item T) error
+	Done        func(ctx context.Context, item T) error
+	Failed      func(ctx context.Context, item T, attempt int, err error, next *time.Time) error
+}
+
+// Process обрабатывает batch и возвращает число отправленных записей. Ошибка
+// Done или Failed прерывает пачку: оставшиеся записи вернутся в очередь по
+// истечении аренды.
+func (q *Queue[T]) Process(ctx context.Context, batch []T) (int, error) {
+	sent := 0
+	for _, item := range batch {
+		err := q.Send(ctx, item)
+		if err == nil {
+			if err := q.Done(ctx, item); err != nil {
+				return sent, err
+			}
+			sent++
+			continue
+		}
+
+		attempt := q.Attempts(item) + 1
+		var next *time.Time
+		if attempt < q.MaxAttempts {
+			at := time.Now().Add(q.Backoff.Delay(attempt))
+			next = &at
+		}
+
+		if err := q.Failed(ctx, item, attempt, err, next); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
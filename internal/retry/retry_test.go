@@ -0,0 +1,103 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Base: 30 * time.Second, Max: 12 * time.Hour}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 256 * time.Minute},
+		{12, 12 * time.Hour},
+		{100, 12 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := b.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+type job struct {
+	id       int
+	attempts int
+	fail     bool
+}
+
+type outcome struct {
+	attempt int
+	next    *time.Time
+}
+
+func TestQueueProcess(t *testing.T) {
+	var done []int
+	failed := map[int]outcome{}
+	q := &Queue[*job]{
+		Backoff:     Backoff{Base: time.Minute, Max: time.Hour},
+		MaxAttempts: 3,
+		Attempts:    func(j *job) int { return j.attempts },
+		Send: func(ctx context.Context, j *job) error {
+			if j.fail {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+		Done: func(ctx context.Context, j *job) error {
+			done = append(done, j.id)
+			return nil
+		},
+		Failed: func(ctx context.Context, j *job, attempt int, err error, next *time.Time) error {
+			failed[j.id] = outcome{attempt, next}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	sent, err := q.Process(context.Background(), []*job{
+		{id: 1},
+		{id: 2, attempts: 1, fail: true},
+		{id: 3, attempts: 2, fail: true},
+	})
+	if err != nil || sent != 1 || len(done) != 1 || done[0] != 1 {
+		t.Fatalf("Process = %d, %v; done %v", sent, err, done)
+	}
+
+	// Вторая попытка откладывается на Base·2, последняя больше не планируется
+	retried := failed[2]
+	if retried.attempt != 2 || retried.next == nil || retried.next.Sub(start) < 2*time.Minute {
+		t.Errorf("job 2 failed as %+v, want attempt 2 retried in 2m", retried)
+	}
+	if exhausted := failed[3]; exhausted.attempt != 3 || exhausted.next != nil {
+		t.Errorf("job 3 failed as %+v, want attempt 3 without retry", exhausted)
+	}
+}
+
+func TestQueueStopsOnStoreError(t *testing.T) {
+	storeErr := errors.New("database is down")
+	var sends int
+	q := &Queue[int]{
+		Backoff:     Backoff{Base: time.Second, Max: time.Minute},
+		MaxAttempts: 5,
+		Attempts:    func(int) int { return 0 },
+		Send: func(ctx context.Context, item int) error {
+			sends++
+			return nil
+		},
+		Done: func(ctx context.Context, item int) error { return storeErr },
+	}
+
+	if _, err := q.Process(context.Background(), []int{1, 2, 3}); !errors.Is(err, storeErr) || sends != 1 {
+		t.Errorf("Process = %v after %d sends, want the store error after 1", err, sends)
+	}
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"sort"
 
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
 	"smartbooking/internal/repository"
 )
 
@@ -13,23 +16,56 @@ type AdminService interface {
 	GetResourcesByCategory(ctx context.Context) ([]repository.CategoryResourceCount, error)
 	GetRevenueByMonth(ctx context.Context, months int) ([]repository.MonthlyRevenue, error)
 	GetBookingsByDay(ctx context.Context, days int) ([]repository.DailyBookings, error)
+	GetCouponUsage(ctx context.Context) ([]repository.CouponUsage, error)
+	ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error)
 }
 
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 500
+)
+
 // adminService implements AdminService interface
 type adminService struct {
 	adminRepo repository.AdminRepository
+	auditRepo repository.AuditRepository
+	rates     *money.Rates
 }
 
-// NewAdminService creates a new instance of AdminService
-func NewAdminService(adminRepo repository.AdminRepository) AdminService {
+// NewAdminService creates a new instance of AdminService. rates is used to
+// report money totals in the base currency
+func NewAdminService(adminRepo repository.AdminRepository, auditRepo repository.AuditRepository, rates *money.Rates) AdminService {
 	return &adminService{
 		adminRepo: adminRepo,
+		auditRepo: auditRepo,
+		rates:     rates,
 	}
 }
 
 // GetSystemStatistics retrieves system-wide statistics
 func (s *adminService) GetSystemStatistics(ctx context.Context) (*repository.AdminStatistics, error) {
-	return s.adminRepo.GetSystemStatistics(ctx)
+	stats, err := s.adminRepo.GetSystemStatistics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revenue := newRevenueTotal(s.rates)
+	discounts := newRevenueTotal(s.rates)
+	for _, item := range stats.RevenueByCurrency {
+		if err := revenue.add(item.Revenue, item.Currency); err != nil {
+			return nil, err
+		}
+		if err := discounts.add(item.Discounts, item.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	stats.Currency = s.rates.Base()
+	stats.TotalRevenue = revenue.total
+	stats.TotalDiscounts = discounts.total
+	stats.UnconvertedCurrencies = revenue.unconverted
+
+	return stats, nil
 }
 
 // GetBookingsByStatus retrieves booking count grouped by status
@@ -47,7 +83,22 @@ func (s *adminService) GetRevenueByMonth(ctx context.Context, months int) ([]rep
 	if months <= 0 {
 		months = 6 // default to 6 months
 	}
-	return s.adminRepo.GetRevenueByMonth(ctx, months)
+	result, err := s.adminRepo.GetRevenueByMonth(ctx, months)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		total := newRevenueTotal(s.rates)
+		for currency, amount := range result[i].ByCurrency {
+			if err := total.add(amount, currency); err != nil {
+				return nil, err
+			}
+		}
+		result[i].Revenue = total.total
+	}
+
+	return result, nil
 }
 
 // GetBookingsByDay retrieves booking count aggregated by day
@@ -57,3 +108,42 @@ func (s *adminService) GetBookingsByDay(ctx context.Context, days int) ([]reposi
 	}
 	return s.adminRepo.GetBookingsByDay(ctx, days)
 }
+
+// GetCouponUsage retrieves redemption and discount totals per coupon
+func (s *adminService) GetCouponUsage(ctx context.Context) ([]repository.CouponUsage, error) {
+	result, err := s.adminRepo.GetCouponUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		total := newRevenueTotal(s.rates)
+		for currency, amount := range result[i].DiscountByCurrency {
+			if err := total.add(amount, currency); err != nil {
+				return nil, err
+			}
+		}
+		result[i].TotalDiscount = total.total
+	}
+
+	// Самые "дорогие" промокоды — первыми
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].TotalDiscount > result[j].TotalDiscount
+	})
+
+	return result, nil
+}
+
+// ListAuditLogs retrieves audit log entries matching the filter, newest first
+func (s *adminService) ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLogLimit
+	}
+	if filter.Limit > maxAuditLogLimit {
+		filter.Limit = maxAuditLogLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.auditRepo.List(ctx, filter)
+}
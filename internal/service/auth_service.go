@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"net/url"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"smartbooking/internal/audit"
+	emailpkg "smartbooking/internal/email"
 	"smartbooking/internal/models"
 	"smartbooking/internal/repository"
 )
@@ -13,24 +19,41 @@ import (
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserExists         = errors.New("user already exists")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
 )
 
 type AuthService interface {
-	Register(ctx context.Context, name, email, password string) (*models.User, error)
+	Register(ctx context.Context, name, email, password, locale string) (*models.User, error)
 	Login(ctx context.Context, email, password string) (*models.User, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo     repository.UserRepository
+	resetRepo    repository.PasswordResetRepository
+	emailService EmailService
+	tx           repository.Transactor
+	auditor      audit.Recorder
+	resetURL     string
+	resetTTL     time.Duration
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
+// NewAuthService creates a new AuthService instance. resetURL — адрес страницы сброса пароля,
+// к которому добавляется параметр token.
+func NewAuthService(userRepo repository.UserRepository, resetRepo repository.PasswordResetRepository, emailService EmailService, tx repository.Transactor, auditor audit.Recorder, resetURL string, resetTTL time.Duration) AuthService {
 	return &authService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		resetRepo:    resetRepo,
+		emailService: emailService,
+		tx:           tx,
+		auditor:      auditor,
+		resetURL:     resetURL,
+		resetTTL:     resetTTL,
 	}
 }
 
-func (s *authService) Register(ctx context.Context, name, email, password string) (*models.User, error) {
+func (s *authService) Register(ctx context.Context, name, email, password, locale string) (*models.User, error) {
 	existingUser, _ := s.userRepo.GetByEmail(ctx, email)
 	if existingUser != nil {
 		return nil, ErrUserExists
@@ -45,11 +68,20 @@ func (s *authService) Register(ctx context.Context, name, email, password string
 		Email:     email,
 		Password:  string(hashedPassword),
 		Role:      models.RoleUser,
+		Locale:    emailpkg.NormalizeLocale(locale),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		// Регистрацию выполняет сам пользователь, даже если запрос анонимный
+		ctx = audit.WithActor(ctx, withActorUser(audit.ActorFrom(ctx), user.ID))
+		return s.auditor.Record(ctx, audit.ActionCreate, audit.EntityUser, user.ID, nil, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -68,3 +100,82 @@ func (s *authService) Login(ctx context.Context, email, password string) (*model
 
 	return user, nil
 }
+
+// RequestPasswordReset отправляет письмо со ссылкой для сброса пароля.
+// Для неизвестного email ничего не делает, чтобы не раскрывать наличие аккаунта.
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := s.resetRepo.Create(ctx, user.ID, hashResetToken(token), time.Now().Add(s.resetTTL)); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.resetURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return s.emailService.SendPasswordReset(ctx, user, link.String(), s.resetTTL)
+}
+
+// ResetPassword устанавливает новый пароль по одноразовому токену. Токен
+// гасится в одной транзакции со сменой пароля: если пароль не сохранился,
+// токен остается действительным.
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if len(newPassword) < 8 {
+		return ErrWeakPassword
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		userID, err := s.resetRepo.Consume(ctx, hashResetToken(token))
+		if err != nil {
+			return err
+		}
+
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		before := *user
+		user.Password = string(hashedPassword)
+
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		ctx = audit.WithActor(ctx, withActorUser(audit.ActorFrom(ctx), user.ID))
+		return s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityUser, user.ID, &before, user)
+	})
+}
+
+// withActorUser подставляет пользователя в исполнителя, если он не известен из запроса
+func withActorUser(actor audit.Actor, userID int64) audit.Actor {
+	if actor.UserID == nil {
+		actor.UserID = &userID
+	}
+	return actor
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
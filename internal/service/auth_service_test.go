@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+var errPasswordUpdate = errors.New("update failed")
+
+// memoryResetRepo хранит одноразовые токены: хеш токена -> пользователь
+type memoryResetRepo struct {
+	repository.PasswordResetRepository
+	tokens map[string]int64
+}
+
+func (r *memoryResetRepo) Consume(ctx context.Context, tokenHash string) (int64, error) {
+	userID, ok := r.tokens[tokenHash]
+	if !ok {
+		return 0, repository.ErrResetTokenInvalid
+	}
+	delete(r.tokens, tokenHash)
+	return userID, nil
+}
+
+type resetUserRepo struct {
+	repository.UserRepository
+	failUpdate bool
+	password   string
+}
+
+func (r *resetUserRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	return &models.User{ID: id, Email: "client@example.com", Password: r.password}, nil
+}
+
+func (r *resetUserRepo) Update(ctx context.Context, user *models.User) error {
+	if r.failUpdate {
+		return errPasswordUpdate
+	}
+	r.password = user.Password
+	return nil
+}
+
+// resetTransactor восстанавливает токены, погашенные в неудачной транзакции
+type resetTransactor struct {
+	repo *memoryResetRepo
+}
+
+func (t resetTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := make(map[string]int64, len(t.repo.tokens))
+	for hash, userID := range t.repo.tokens {
+		saved[hash] = userID
+	}
+	if err := fn(ctx); err != nil {
+		t.repo.tokens = saved
+		return err
+	}
+	return nil
+}
+
+func TestResetPasswordConsumesTokenWithUpdate(t *testing.T) {
+	const token = "reset-token"
+
+	tests := []struct {
+		name       string
+		failUpdate bool
+		wantErr    error
+		wantToken  bool
+	}{
+		{"password changed", false, nil, false},
+		// Пароль не сохранился — пользователь может повторить сброс тем же токеном
+		{"update fails", true, errPasswordUpdate, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resets := &memoryResetRepo{tokens: map[string]int64{hashResetToken(token): 7}}
+			users := &resetUserRepo{failUpdate: tt.failUpdate, password: "old-hash"}
+			svc := NewAuthService(users, resets, nil, resetTransactor{repo: resets}, nopAuditor{}, "https://example.com/reset", time.Hour)
+
+			err := svc.ResetPassword(context.Background(), token, "new-password")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetPassword = %v, want %v", err, tt.wantErr)
+			}
+			if _, ok := resets.tokens[hashResetToken(token)]; ok != tt.wantToken {
+				t.Errorf("token still valid: %v, want %v", ok, tt.wantToken)
+			}
+			if changed := users.password != "old-hash"; changed == tt.failUpdate {
+				t.Errorf("password changed: %v", changed)
+			}
+		})
+	}
+
+	t.Run("used token", func(t *testing.T) {
+		resets := &memoryResetRepo{tokens: map[string]int64{}}
+		svc := NewAuthService(&resetUserRepo{}, resets, nil, resetTransactor{repo: resets}, nopAuditor{}, "https://example.com/reset", time.Hour)
+		if err := svc.ResetPassword(context.Background(), token, "new-password"); !errors.Is(err, repository.ErrResetTokenInvalid) {
+			t.Errorf("ResetPassword = %v, want ErrResetTokenInvalid", err)
+		}
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"time"
 
+	"smartbooking/internal/audit"
+	"smartbooking/internal/events"
 	"smartbooking/internal/models"
+	"smartbooking/internal/pricing"
 	"smartbooking/internal/repository"
 )
 
@@ -13,11 +16,14 @@ var (
 	ErrBookingConflict  = errors.New("booking conflicts with existing reservation")
 	ErrInvalidTimeRange = errors.New("invalid time range")
 	ErrBookingNotFound  = errors.New("booking not found")
+
+	ErrOutsideOpeningHours = errors.New("booking is outside the resource's opening hours")
 )
 
 // BookingService handles booking-related business logic
 type BookingService interface {
-	Create(ctx context.Context, userID, resourceID int64, startTime, endTime time.Time) (*models.Booking, error)
+	Create(ctx context.Context, userID, resourceID int64, startTime, endTime time.Time, promoCode string) (*models.Booking, error)
+	Quote(ctx context.Context, userID, resourceID int64, startTime, endTime time.Time, promoCode string) (*models.CouponQuote, error)
 	GetByID(ctx context.Context, id int64) (*models.Booking, error)
 	Cancel(ctx context.Context, id int64) error
 	ListByUser(ctx context.Context, userID int64) ([]*models.Booking, error)
@@ -26,19 +32,27 @@ type BookingService interface {
 }
 
 type bookingService struct {
-	bookingRepo  repository.BookingRepository
-	resourceRepo repository.ResourceRepository
+	bookingRepo   repository.BookingRepository
+	resourceRepo  repository.ResourceRepository
+	couponService CouponService
+	tx            repository.Transactor
+	publisher     events.Publisher
+	auditor       audit.Recorder
 }
 
 // NewBookingService creates a new BookingService instance
-func NewBookingService(bookingRepo repository.BookingRepository, resourceRepo repository.ResourceRepository) BookingService {
+func NewBookingService(bookingRepo repository.BookingRepository, resourceRepo repository.ResourceRepository, couponService CouponService, tx repository.Transactor, publisher events.Publisher, auditor audit.Recorder) BookingService {
 	return &bookingService{
-		bookingRepo:  bookingRepo,
-		resourceRepo: resourceRepo,
+		bookingRepo:   bookingRepo,
+		resourceRepo:  resourceRepo,
+		couponService: couponService,
+		tx:            tx,
+		publisher:     publisher,
+		auditor:       auditor,
 	}
 }
 
-func (s *bookingService) Create(ctx context.Context, userID, resourceID int64, startTime, endTime time.Time) (*models.Booking, error) {
+func (s *bookingService) Create(ctx context.Context, userID, resourceID int64, startTime, endTime time.Time, promoCode string) (*models.Booking, error) {
 	// Validate time range
 	if startTime.After(endTime) || startTime.Equal(endTime) {
 		return nil, ErrInvalidTimeRange
@@ -53,23 +67,107 @@ func (s *bookingService) Create(ctx context.Context, userID, resourceID int64, s
 		return nil, ErrBookingConflict
 	}
 
+	resource, err := s.resourceRepo.GetByID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon, quote, err := s.quote(ctx, userID, resource, startTime, endTime, promoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Бронь ресурса с предоплатой ждет оплаты и подтверждается вебхуком
+	// платежа. Остальные брони подтверждаются сразу при создании: до платежей
+	// все брони создавались в статусе pending.
+	status := models.StatusConfirmed
+	if resource.RequiresPrepayment {
+		status = models.StatusPending
+	}
+
 	booking := &models.Booking{
-		UserID:     userID,
-		ResourceID: resourceID,
-		StartTime:  startTime,
-		EndTime:    endTime,
-		Status:     models.StatusPending,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		UserID:         userID,
+		ResourceID:     resourceID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Status:         status,
+		TotalPrice:     quote.TotalPrice,
+		DiscountAmount: quote.DiscountAmount,
+		Currency:       quote.Currency,
+		PriceItems:     quote.Items,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
-	if err := s.bookingRepo.Create(ctx, booking); err != nil {
+	// Бронь, применение купона и событие фиксируются вместе: если лимит купона
+	// исчерпан параллельным бронированием, бронь откатывается
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.bookingRepo.Create(ctx, booking); err != nil {
+			return err
+		}
+		if coupon != nil {
+			if err := s.couponService.Redeem(ctx, coupon, userID, booking.ID, booking.DiscountAmount); err != nil {
+				return err
+			}
+		}
+		if err := s.auditor.Record(ctx, audit.ActionCreate, audit.EntityBooking, booking.ID, nil, booking); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.BookingCreated, Booking: booking})
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return booking, nil
 }
 
+func (s *bookingService) Quote(ctx context.Context, userID, resourceID int64, startTime, endTime time.Time, promoCode string) (*models.CouponQuote, error) {
+	if startTime.After(endTime) || startTime.Equal(endTime) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	resource, err := s.resourceRepo.GetByID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, quote, err := s.quote(ctx, userID, resource, startTime, endTime, promoCode)
+	return quote, err
+}
+
+// quote проверяет часы работы и рассчитывает цену бронирования по тарифам
+// ресурса минус скидка по промокоду. Расписание и окна тарифов заданы в
+// часовом поясе ресурса.
+func (s *bookingService) quote(ctx context.Context, userID int64, resource *models.Resource, startTime, endTime time.Time, promoCode string) (*models.Coupon, *models.CouponQuote, error) {
+	loc := resource.Location()
+
+	schedules, err := s.resourceRepo.GetSchedules(ctx, resource.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !pricing.WithinSchedule(schedules, loc, startTime, endTime) {
+		return nil, nil, ErrOutsideOpeningHours
+	}
+
+	rules, err := s.resourceRepo.GetPricingRules(ctx, resource.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, subtotal := pricing.Calculate(rules, resource.PricePerHour, loc, startTime, endTime)
+
+	if promoCode == "" {
+		return nil, &models.CouponQuote{Currency: resource.Currency, Subtotal: subtotal, TotalPrice: subtotal, Items: items}, nil
+	}
+
+	coupon, quote, err := s.couponService.Quote(ctx, promoCode, userID, resource, subtotal)
+	if err != nil {
+		return nil, nil, err
+	}
+	quote.Items = items
+	return coupon, quote, nil
+}
+
 func (s *bookingService) GetByID(ctx context.Context, id int64) (*models.Booking, error) {
 	return s.bookingRepo.GetByID(ctx, id)
 }
@@ -83,10 +181,19 @@ func (s *bookingService) Cancel(ctx context.Context, id int64) error {
 		return ErrBookingNotFound
 	}
 
+	before := *booking
 	booking.Status = models.StatusCancelled
 	booking.UpdatedAt = time.Now()
 
-	return s.bookingRepo.Update(ctx, booking)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.bookingRepo.Update(ctx, booking); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityBooking, booking.ID, &before, booking); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.BookingCancelled, Booking: booking})
+	})
 }
 
 func (s *bookingService) ListByUser(ctx context.Context, userID int64) ([]*models.Booking, error) {
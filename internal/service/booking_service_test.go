@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+	"smartbooking/internal/repository"
+)
+
+type createBookingRepo struct {
+	repository.BookingRepository
+	created *models.Booking
+}
+
+func (r *createBookingRepo) CheckOverlap(ctx context.Context, resourceID int64, start, end time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *createBookingRepo) Create(ctx context.Context, booking *models.Booking) error {
+	booking.ID = 1
+	r.created = booking
+	return nil
+}
+
+type createResourceRepo struct {
+	repository.ResourceRepository
+	requiresPrepayment bool
+}
+
+func (r *createResourceRepo) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
+	price := money.FromMinor(5_000)
+	return &models.Resource{ID: id, PricePerHour: &price, Currency: "KZT", RequiresPrepayment: r.requiresPrepayment}, nil
+}
+
+func (r *createResourceRepo) GetSchedules(ctx context.Context, resourceID int64) ([]*models.ResourceSchedule, error) {
+	return nil, nil
+}
+
+func (r *createResourceRepo) GetPricingRules(ctx context.Context, resourceID int64) ([]*models.PricingRule, error) {
+	return nil, nil
+}
+
+func TestCreateBookingStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		requiresPrepayment bool
+		want               models.BookingStatus
+	}{
+		// Без предоплаты бронь подтверждается сразу, а не ждет в pending
+		{"no prepayment", false, models.StatusConfirmed},
+		{"prepayment", true, models.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookings := &createBookingRepo{}
+			publisher := &recordingPublisher{}
+			svc := NewBookingService(bookings, &createResourceRepo{requiresPrepayment: tt.requiresPrepayment}, nil, inlineTransactor{}, publisher, nopAuditor{})
+
+			start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+			booking, err := svc.Create(context.Background(), 2, 3, start, start.Add(2*time.Hour), "")
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if booking.Status != tt.want || bookings.created.Status != tt.want {
+				t.Errorf("status = %s, want %s", booking.Status, tt.want)
+			}
+			if len(publisher.events) != 1 || publisher.events[0].Type != events.BookingCreated {
+				t.Errorf("published %+v, want one BookingCreated", publisher.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"smartbooking/internal/ical"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+var (
+	ErrInvalidCalendarURL   = errors.New("calendar url must be an http, https or webcal url")
+	ErrCalendarFetchFailed  = errors.New("failed to fetch calendar")
+	ErrCalendarFileRequired = errors.New("uploaded calendar can only be synced with a new file")
+	ErrCalendarAccessDenied = errors.New("resource belongs to another owner")
+)
+
+const (
+	calendarSyncBatchSize = 10
+	calendarSyncLease     = 10 * time.Minute
+	maxBlockUIDLength     = 500
+	maxBlockSummaryLength = 500
+)
+
+// CalendarImportService imports external calendars of a resource and keeps
+// the availability blocks produced from their events in sync
+type CalendarImportService interface {
+	AddURL(ctx context.Context, resourceID, ownerID int64, name, rawURL string) (*models.ExternalCalendar, *models.CalendarSyncResult, error)
+	Upload(ctx context.Context, resourceID, ownerID int64, name string, r io.Reader) (*models.ExternalCalendar, *models.CalendarSyncResult, error)
+	Sync(ctx context.Context, resourceID, calendarID int64, r io.Reader) (*models.CalendarSyncResult, error)
+	List(ctx context.Context, resourceID int64) ([]*models.ExternalCalendar, error)
+	Delete(ctx context.Context, resourceID, calendarID int64) error
+	ListBlocks(ctx context.Context, resourceID int64, from, to time.Time) ([]*models.ResourceBlock, error)
+	SyncDue(ctx context.Context) (int, error)
+}
+
+type calendarImportService struct {
+	calendarRepo repository.ExternalCalendarRepository
+	resourceRepo repository.ResourceRepository
+	tx           repository.Transactor
+	fetcher      *ical.Fetcher
+	syncInterval time.Duration
+}
+
+// NewCalendarImportService creates a new CalendarImportService instance. Календари
+// по URL перезагружаются раз в syncInterval фоновым воркером через SyncDue.
+func NewCalendarImportService(calendarRepo repository.ExternalCalendarRepository, resourceRepo repository.ResourceRepository, tx repository.Transactor, fetcher *ical.Fetcher, syncInterval time.Duration) CalendarImportService {
+	return &calendarImportService{
+		calendarRepo: calendarRepo,
+		resourceRepo: resourceRepo,
+		tx:           tx,
+		fetcher:      fetcher,
+		syncInterval: syncInterval,
+	}
+}
+
+// AddURL подключает календарь по URL. Подключить календарь может только
+// владелец ресурса. Календарь загружается сразу, чтобы владелец узнал о
+// неверном адресе до сохранения подписки.
+func (s *calendarImportService) AddURL(ctx context.Context, resourceID, ownerID int64, name, rawURL string) (*models.ExternalCalendar, *models.CalendarSyncResult, error) {
+	calURL, err := ical.NormalizeURL(rawURL)
+	if err != nil {
+		return nil, nil, ErrInvalidCalendarURL
+	}
+	if err := s.checkOwner(ctx, resourceID, ownerID); err != nil {
+		return nil, nil, err
+	}
+
+	events, err := s.fetcher.Fetch(ctx, calURL)
+	if err != nil {
+		return nil, nil, fetchError(err)
+	}
+
+	cal := &models.ExternalCalendar{
+		ResourceID: resourceID,
+		Name:       name,
+		Source:     models.CalendarSourceURL,
+		URL:        calURL,
+	}
+	return s.create(ctx, cal, events)
+}
+
+// Upload создает календарь из загруженного .ics файла владельца ресурса
+func (s *calendarImportService) Upload(ctx context.Context, resourceID, ownerID int64, name string, r io.Reader) (*models.ExternalCalendar, *models.CalendarSyncResult, error) {
+	if err := s.checkOwner(ctx, resourceID, ownerID); err != nil {
+		return nil, nil, err
+	}
+
+	events, err := ical.ParseLimited(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cal := &models.ExternalCalendar{
+		ResourceID: resourceID,
+		Name:       name,
+		Source:     models.CalendarSourceUpload,
+	}
+	return s.create(ctx, cal, events)
+}
+
+// checkOwner проверяет, что ресурс принадлежит ownerID: события календаря
+// закрывают ресурс для бронирования
+func (s *calendarImportService) checkOwner(ctx context.Context, resourceID, ownerID int64) error {
+	resource, err := s.resourceRepo.GetByID(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	if resource.OwnerID == nil || *resource.OwnerID != ownerID {
+		return ErrCalendarAccessDenied
+	}
+	return nil
+}
+
+func (s *calendarImportService) create(ctx context.Context, cal *models.ExternalCalendar, events []ical.Event) (*models.ExternalCalendar, *models.CalendarSyncResult, error) {
+	var result *models.CalendarSyncResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		cal.NextSyncAt = time.Now().Add(s.syncInterval)
+		if err := s.calendarRepo.Create(ctx, cal); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = s.apply(ctx, cal, events)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	cal.LastSyncedAt = &now
+	return cal, result, nil
+}
+
+// Sync синхронизирует календарь вне расписания. Календарь по URL загружается
+// заново, для загруженного календаря нужен новый файл r.
+func (s *calendarImportService) Sync(ctx context.Context, resourceID, calendarID int64, r io.Reader) (*models.CalendarSyncResult, error) {
+	cal, err := s.ownedCalendar(ctx, resourceID, calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cal.Source == models.CalendarSourceUpload {
+		if r == nil {
+			return nil, ErrCalendarFileRequired
+		}
+		events, err := ical.ParseLimited(r)
+		if err != nil {
+			return nil, err
+		}
+		return s.applyInTx(ctx, cal, events)
+	}
+
+	return s.syncURL(ctx, cal)
+}
+
+func (s *calendarImportService) List(ctx context.Context, resourceID int64) ([]*models.ExternalCalendar, error) {
+	return s.calendarRepo.ListByResource(ctx, resourceID)
+}
+
+// Delete удаляет календарь вместе с его блокировками
+func (s *calendarImportService) Delete(ctx context.Context, resourceID, calendarID int64) error {
+	if _, err := s.ownedCalendar(ctx, resourceID, calendarID); err != nil {
+		return err
+	}
+	return s.calendarRepo.Delete(ctx, calendarID)
+}
+
+func (s *calendarImportService) ListBlocks(ctx context.Context, resourceID int64, from, to time.Time) ([]*models.ResourceBlock, error) {
+	return s.calendarRepo.ListBlocksByResource(ctx, resourceID, from, to)
+}
+
+// SyncDue загружает очередную пачку календарей по URL, которые пора обновить,
+// и возвращает число успешно синхронизированных. Ошибка загрузки одного
+// календаря записывается в него и не прерывает остальные.
+func (s *calendarImportService) SyncDue(ctx context.Context) (int, error) {
+	batch, err := s.calendarRepo.ClaimDueSync(ctx, calendarSyncBatchSize, calendarSyncLease)
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, cal := range batch {
+		if _, err := s.syncURL(ctx, cal); err != nil {
+			logger.Error("Calendar sync %d (%s) failed: %v", cal.ID, cal.URL, err)
+			continue
+		}
+		synced++
+	}
+
+	return synced, nil
+}
+
+// syncURL загружает календарь по URL и применяет его. Ошибка загрузки
+// сохраняется в календаре, следующая попытка — через syncInterval.
+func (s *calendarImportService) syncURL(ctx context.Context, cal *models.ExternalCalendar) (*models.CalendarSyncResult, error) {
+	events, err := s.fetcher.Fetch(ctx, cal.URL)
+	if err != nil {
+		if markErr := s.calendarRepo.MarkSynced(ctx, cal.ID, err.Error(), time.Now().Add(s.syncInterval)); markErr != nil {
+			return nil, markErr
+		}
+		return nil, fetchError(err)
+	}
+
+	return s.applyInTx(ctx, cal, events)
+}
+
+func (s *calendarImportService) applyInTx(ctx context.Context, cal *models.ExternalCalendar, events []ical.Event) (*models.CalendarSyncResult, error) {
+	var result *models.CalendarSyncResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.apply(ctx, cal, events)
+		return err
+	})
+	return result, err
+}
+
+// apply сверяет блокировки календаря с событиями по UID: новые события добавляются,
+// измененные обновляются, пропавшие удаляются. Повторное применение того же
+// календаря ничего не меняет.
+func (s *calendarImportService) apply(ctx context.Context, cal *models.ExternalCalendar, events []ical.Event) (*models.CalendarSyncResult, error) {
+	resource, err := s.resourceRepo.GetByID(ctx, cal.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	desired := calendarBlocks(cal, events, resource.Location(), time.Now())
+
+	existing, err := s.calendarRepo.ListBlocks(ctx, cal.ID)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]*models.ResourceBlock, len(existing))
+	for _, b := range existing {
+		current[b.UID] = b
+	}
+
+	result := &models.CalendarSyncResult{}
+	for _, block := range desired {
+		old, ok := current[block.UID]
+		if !ok {
+			if err := s.calendarRepo.CreateBlock(ctx, block); err != nil {
+				return nil, err
+			}
+			result.Added++
+			continue
+		}
+		delete(current, block.UID)
+
+		if old.StartTime.Equal(block.StartTime) && old.EndTime.Equal(block.EndTime) && old.Summary == block.Summary {
+			continue
+		}
+		block.ID = old.ID
+		if err := s.calendarRepo.UpdateBlock(ctx, block); err != nil {
+			return nil, err
+		}
+		result.Updated++
+	}
+
+	stale := make([]int64, 0, len(current))
+	for _, b := range current {
+		stale = append(stale, b.ID)
+	}
+	if err := s.calendarRepo.DeleteBlocks(ctx, stale); err != nil {
+		return nil, err
+	}
+	result.Removed = len(stale)
+
+	if err := s.calendarRepo.MarkSynced(ctx, cal.ID, "", time.Now().Add(s.syncInterval)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *calendarImportService) ownedCalendar(ctx context.Context, resourceID, calendarID int64) (*models.ExternalCalendar, error) {
+	cal, err := s.calendarRepo.GetByID(ctx, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	if cal.ResourceID != resourceID {
+		return nil, repository.ErrExternalCalendarNotFound
+	}
+	return cal, nil
+}
+
+// calendarBlocks превращает события в блокировки. События на весь день и время
+// без часового пояса относятся к часовому поясу ресурса loc. Отмененные, пустые
+// и уже закончившиеся события пропускаются. Событию без UID присваивается UID из хеша
+// его времени и названия; измененные вхождения повторяющегося события (тот же UID)
+// различаются временем начала.
+func calendarBlocks(cal *models.ExternalCalendar, events []ical.Event, loc *time.Location, now time.Time) []*models.ResourceBlock {
+	blocks := make([]*models.ResourceBlock, 0, len(events))
+	seen := make(map[string]bool, len(events))
+	for _, e := range events {
+		e = e.InLocation(loc)
+		if e.Status == ical.StatusCancelled || !e.End.After(e.Start) || !e.End.After(now) {
+			continue
+		}
+
+		start, end := e.Start.UTC(), e.End.UTC()
+		uid := e.UID
+		if uid == "" {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%d|%s", start.Unix(), end.Unix(), e.Summary)))
+			uid = "sha256-" + hex.EncodeToString(sum[:16])
+		}
+		if seen[uid] {
+			uid = fmt.Sprintf("%s#%s", uid, start.Format("20060102T150405Z"))
+		}
+		if seen[uid] || len(uid) > maxBlockUIDLength {
+			continue
+		}
+		seen[uid] = true
+
+		calendarID := cal.ID
+		blocks = append(blocks, &models.ResourceBlock{
+			ResourceID: cal.ResourceID,
+			CalendarID: &calendarID,
+			UID:        uid,
+			Summary:    truncateRunes(e.Summary, maxBlockSummaryLength),
+			StartTime:  start,
+			EndTime:    end,
+		})
+	}
+	return blocks
+}
+
+func fetchError(err error) error {
+	if errors.Is(err, ical.ErrInvalidCalendar) || errors.Is(err, ical.ErrCalendarTooLarge) {
+		return err
+	}
+	if errors.Is(err, ical.ErrForbiddenAddress) {
+		return ErrInvalidCalendarURL
+	}
+	return fmt.Errorf("%w: %v", ErrCalendarFetchFailed, err)
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"smartbooking/internal/ical"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+// memoryCalendarRepo хранит календари и их блокировки в памяти
+type memoryCalendarRepo struct {
+	repository.ExternalCalendarRepository
+	calendars map[int64]*models.ExternalCalendar
+	blocks    map[int64]*models.ResourceBlock
+	nextID    int64
+}
+
+func newMemoryCalendarRepo() *memoryCalendarRepo {
+	return &memoryCalendarRepo{
+		calendars: make(map[int64]*models.ExternalCalendar),
+		blocks:    make(map[int64]*models.ResourceBlock),
+	}
+}
+
+func (r *memoryCalendarRepo) Create(ctx context.Context, cal *models.ExternalCalendar) error {
+	r.nextID++
+	cal.ID = r.nextID
+	stored := *cal
+	r.calendars[cal.ID] = &stored
+	return nil
+}
+
+func (r *memoryCalendarRepo) GetByID(ctx context.Context, id int64) (*models.ExternalCalendar, error) {
+	cal, ok := r.calendars[id]
+	if !ok {
+		return nil, repository.ErrExternalCalendarNotFound
+	}
+	stored := *cal
+	return &stored, nil
+}
+
+func (r *memoryCalendarRepo) MarkSynced(ctx context.Context, id int64, syncErr string, nextSyncAt time.Time) error {
+	r.calendars[id].LastError = syncErr
+	r.calendars[id].NextSyncAt = nextSyncAt
+	return nil
+}
+
+func (r *memoryCalendarRepo) ListBlocks(ctx context.Context, calendarID int64) ([]*models.ResourceBlock, error) {
+	var result []*models.ResourceBlock
+	for _, b := range r.blocks {
+		if b.CalendarID != nil && *b.CalendarID == calendarID {
+			stored := *b
+			result = append(result, &stored)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryCalendarRepo) CreateBlock(ctx context.Context, block *models.ResourceBlock) error {
+	r.nextID++
+	block.ID = r.nextID
+	stored := *block
+	r.blocks[block.ID] = &stored
+	return nil
+}
+
+func (r *memoryCalendarRepo) UpdateBlock(ctx context.Context, block *models.ResourceBlock) error {
+	stored := *block
+	r.blocks[block.ID] = &stored
+	return nil
+}
+
+func (r *memoryCalendarRepo) DeleteBlocks(ctx context.Context, ids []int64) error {
+	for _, id := range ids {
+		delete(r.blocks, id)
+	}
+	return nil
+}
+
+// byUID возвращает блокировки по UID события
+func (r *memoryCalendarRepo) byUID() map[string]*models.ResourceBlock {
+	result := make(map[string]*models.ResourceBlock, len(r.blocks))
+	for _, b := range r.blocks {
+		result[b.UID] = b
+	}
+	return result
+}
+
+// calendarOwner владелец ресурса в тестах импорта
+const calendarOwner = 5
+
+type calendarResourceRepo struct {
+	repository.ResourceRepository
+}
+
+func (r *calendarResourceRepo) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
+	owner := int64(calendarOwner)
+	return &models.Resource{ID: id, Name: "Chalet", Timezone: "Europe/Berlin", OwnerID: &owner}, nil
+}
+
+// localFetcher загружает календари без проверки адреса: тестовый сервер
+// слушает loopback, который NewFetcher запрещает
+func localFetcher() *ical.Fetcher {
+	return ical.NewFetcherWithDialer(time.Second, &net.Dialer{})
+}
+
+// inlineTransactor выполняет fn без транзакции
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestCalendarImportIsIdempotentAndReleasesRemovedEvents(t *testing.T) {
+	// Сервер отдает текущую версию календаря из testdata
+	var fixture atomic.Value
+	fixture.Store("testdata/calendar_v1.ics")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(fixture.Load().(string))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	repo := newMemoryCalendarRepo()
+	svc := NewCalendarImportService(repo, &calendarResourceRepo{}, inlineTransactor{}, localFetcher(), time.Hour)
+	ctx := context.Background()
+
+	cal, result, err := svc.AddURL(ctx, 1, calendarOwner, "Airbnb", srv.URL+"/calendar.ics")
+	if err != nil {
+		t.Fatalf("AddURL: %v", err)
+	}
+	// Отмененное событие не блокирует ресурс
+	if *result != (models.CalendarSyncResult{Added: 3}) {
+		t.Fatalf("first import = %+v, want 3 added", *result)
+	}
+
+	blocks := repo.byUID()
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	allDay := blocks["stay-2@example.com"]
+	if allDay == nil || !allDay.StartTime.Equal(time.Date(2030, time.January, 10, 0, 0, 0, 0, berlin)) {
+		t.Errorf("all-day event block = %+v, want midnight in the resource's zone", allDay)
+	}
+	ids := make(map[string]int64, len(blocks))
+	for uid, b := range blocks {
+		ids[uid] = b.ID
+	}
+
+	// Повторный импорт того же календаря ничего не меняет
+	result, err = svc.Sync(ctx, 1, cal.ID, nil)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if *result != (models.CalendarSyncResult{}) {
+		t.Errorf("second import = %+v, want no changes", *result)
+	}
+	for uid, b := range repo.byUID() {
+		if ids[uid] != b.ID {
+			t.Errorf("block %s was recreated: id %d -> %d", uid, ids[uid], b.ID)
+		}
+	}
+	if len(repo.blocks) != 3 {
+		t.Errorf("got %d blocks after second import, want 3", len(repo.blocks))
+	}
+
+	// Во второй версии одно событие продлено, одно удалено
+	fixture.Store("testdata/calendar_v2.ics")
+	result, err = svc.Sync(ctx, 1, cal.ID, nil)
+	if err != nil {
+		t.Fatalf("import after changes: %v", err)
+	}
+	if *result != (models.CalendarSyncResult{Updated: 1, Removed: 1}) {
+		t.Errorf("import after changes = %+v, want 1 updated and 1 removed", *result)
+	}
+
+	blocks = repo.byUID()
+	if _, ok := blocks["stay-3@example.com"]; ok {
+		t.Error("block of the removed event was not released")
+	}
+	stay := blocks["stay-1@example.com"]
+	if stay == nil || stay.ID != ids["stay-1@example.com"] || !stay.EndTime.Equal(time.Date(2030, time.January, 8, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("updated block = %+v, want the same block ending 2030-01-08 10:00 UTC", stay)
+	}
+	if len(blocks) != 2 {
+		t.Errorf("got %d blocks, want 2", len(blocks))
+	}
+}
+
+func TestCalendarImportKeepsBlocksWhenFetchFails(t *testing.T) {
+	var broken atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if broken.Load() {
+			http.Error(w, "gone", http.StatusBadGateway)
+			return
+		}
+		http.ServeFile(w, r, "testdata/calendar_v1.ics")
+	}))
+	defer srv.Close()
+
+	repo := newMemoryCalendarRepo()
+	svc := NewCalendarImportService(repo, &calendarResourceRepo{}, inlineTransactor{}, localFetcher(), time.Hour)
+	ctx := context.Background()
+
+	cal, _, err := svc.AddURL(ctx, 1, calendarOwner, "Airbnb", srv.URL)
+	if err != nil {
+		t.Fatalf("AddURL: %v", err)
+	}
+
+	broken.Store(true)
+	if _, err := svc.Sync(ctx, 1, cal.ID, nil); err == nil {
+		t.Fatal("Sync succeeded against a failing server")
+	}
+	if len(repo.blocks) != 3 {
+		t.Errorf("got %d blocks after failed fetch, want the 3 imported ones kept", len(repo.blocks))
+	}
+	if repo.calendars[cal.ID].LastError == "" {
+		t.Error("fetch error was not recorded on the calendar")
+	}
+}
+
+func TestCalendarImportRequiresResourceOwner(t *testing.T) {
+	var fetched atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		http.ServeFile(w, r, "testdata/calendar_v1.ics")
+	}))
+	defer srv.Close()
+
+	repo := newMemoryCalendarRepo()
+	svc := NewCalendarImportService(repo, &calendarResourceRepo{}, inlineTransactor{}, localFetcher(), time.Hour)
+	ctx := context.Background()
+
+	// Чужой владелец не закрывает ресурс своими событиями и не заставляет
+	// сервер загружать его URL
+	if _, _, err := svc.AddURL(ctx, 1, calendarOwner+1, "Airbnb", srv.URL); !errors.Is(err, ErrCalendarAccessDenied) {
+		t.Errorf("AddURL by another owner = %v, want ErrCalendarAccessDenied", err)
+	}
+
+	file, err := os.Open("testdata/calendar_v1.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, _, err := svc.Upload(ctx, 1, calendarOwner+1, "Upload", file); !errors.Is(err, ErrCalendarAccessDenied) {
+		t.Errorf("Upload by another owner = %v, want ErrCalendarAccessDenied", err)
+	}
+
+	if fetched.Load() != 0 || len(repo.calendars) != 0 || len(repo.blocks) != 0 {
+		t.Errorf("denied import fetched %d times and stored %d calendars, %d blocks", fetched.Load(), len(repo.calendars), len(repo.blocks))
+	}
+}
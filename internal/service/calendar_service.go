@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"smartbooking/internal/ical"
+	"smartbooking/internal/invoice"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+var (
+	ErrInvalidFeedToken = errors.New("invalid calendar feed token")
+	ErrFeedAccessDenied = errors.New("calendar feed belongs to another user")
+)
+
+// calendarFeedHistory сколько прошедших бронирований попадает в ленту
+const calendarFeedHistory = 365 * 24 * time.Hour
+
+// CalendarService builds iCalendar feeds of bookings and manages their access tokens
+type CalendarService interface {
+	IssueFeedToken(ctx context.Context, scope string, entityID, callerID int64) (string, error)
+	UserFeed(ctx context.Context, userID int64, token string) (*ical.Calendar, error)
+	ResourceFeed(ctx context.Context, resourceID int64, token string) (*ical.Calendar, error)
+}
+
+type calendarService struct {
+	feedRepo     repository.CalendarFeedRepository
+	bookingRepo  repository.BookingRepository
+	resourceRepo repository.ResourceRepository
+	userRepo     repository.UserRepository
+}
+
+// NewCalendarService creates a new CalendarService instance
+func NewCalendarService(feedRepo repository.CalendarFeedRepository, bookingRepo repository.BookingRepository, resourceRepo repository.ResourceRepository, userRepo repository.UserRepository) CalendarService {
+	return &calendarService{
+		feedRepo:     feedRepo,
+		bookingRepo:  bookingRepo,
+		resourceRepo: resourceRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// IssueFeedToken выпускает новый секретный токен ленты пользователя или ресурса.
+// Ленту пользователя выпускает только он сам, ленту ресурса — его владелец
+// (callerID). Прежний токен перестает действовать; сам токен возвращается только здесь.
+func (s *calendarService) IssueFeedToken(ctx context.Context, scope string, entityID, callerID int64) (string, error) {
+	switch scope {
+	case repository.CalendarFeedUser:
+		if _, err := s.userRepo.GetByID(ctx, entityID); err != nil {
+			return "", err
+		}
+		if callerID != entityID {
+			return "", ErrFeedAccessDenied
+		}
+	case repository.CalendarFeedResource:
+		resource, err := s.resourceRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return "", err
+		}
+		if resource.OwnerID == nil || *resource.OwnerID != callerID {
+			return "", ErrFeedAccessDenied
+		}
+	default:
+		return "", fmt.Errorf("unknown calendar feed scope %q", scope)
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := s.feedRepo.SetToken(ctx, scope, entityID, hashFeedToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// UserFeed возвращает календарь бронирований пользователя
+func (s *calendarService) UserFeed(ctx context.Context, userID int64, token string) (*ical.Calendar, error) {
+	if err := s.checkToken(ctx, repository.CalendarFeedUser, userID, token); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.bookingRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[int64]*models.Resource)
+	cal := &ical.Calendar{Name: "SmartBooking: " + user.Name}
+	for _, b := range recentBookings(bookings) {
+		resource, ok := resources[b.ResourceID]
+		if !ok {
+			resource, err = s.resourceRepo.GetByID(ctx, b.ResourceID)
+			if err != nil {
+				return nil, err
+			}
+			resources[b.ResourceID] = resource
+		}
+
+		cal.Events = append(cal.Events, bookingEvent(b, resource.Name, resourceLocation(resource)))
+	}
+
+	return cal, nil
+}
+
+// ResourceFeed возвращает календарь бронирований ресурса для владельца
+func (s *calendarService) ResourceFeed(ctx context.Context, resourceID int64, token string) (*ical.Calendar, error) {
+	if err := s.checkToken(ctx, repository.CalendarFeedResource, resourceID, token); err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceRepo.GetByID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.bookingRepo.ListByResource(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	guests := make(map[int64]string)
+	cal := &ical.Calendar{Name: "SmartBooking: " + resource.Name}
+	for _, b := range recentBookings(bookings) {
+		guest, ok := guests[b.UserID]
+		if !ok {
+			user, err := s.userRepo.GetByID(ctx, b.UserID)
+			if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+				return nil, err
+			}
+			if user != nil {
+				guest = user.Name
+			}
+			guests[b.UserID] = guest
+		}
+
+		summary := resource.Name
+		if guest != "" {
+			summary = fmt.Sprintf("%s — %s", resource.Name, guest)
+		}
+		cal.Events = append(cal.Events, bookingEvent(b, summary, resourceLocation(resource)))
+	}
+
+	return cal, nil
+}
+
+func (s *calendarService) checkToken(ctx context.Context, scope string, entityID int64, token string) error {
+	if token == "" {
+		return ErrInvalidFeedToken
+	}
+	ok, err := s.feedRepo.HasToken(ctx, scope, entityID, hashFeedToken(token))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidFeedToken
+	}
+	return nil
+}
+
+// recentBookings отбрасывает бронирования, закончившиеся раньше calendarFeedHistory
+func recentBookings(bookings []*models.Booking) []*models.Booking {
+	cutoff := time.Now().Add(-calendarFeedHistory)
+	result := make([]*models.Booking, 0, len(bookings))
+	for _, b := range bookings {
+		if b.EndTime.After(cutoff) {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// bookingEvent превращает бронирование в VEVENT. UID зависит только от ID
+// бронирования, поэтому клиент обновляет событие при изменении статуса.
+func bookingEvent(b *models.Booking, summary, location string) ical.Event {
+	event := ical.Event{
+		UID:          fmt.Sprintf("booking-%d@smartbooking", b.ID),
+		Summary:      summary,
+		Description:  fmt.Sprintf("Бронирование #%d\nСтоимость: %s", b.ID, invoice.FormatMoney(b.TotalPrice, b.Currency)),
+		Location:     location,
+		Start:        b.StartTime,
+		End:          b.EndTime,
+		Created:      b.CreatedAt,
+		LastModified: b.UpdatedAt,
+	}
+
+	switch b.Status {
+	case models.StatusConfirmed:
+		event.Status = ical.StatusConfirmed
+	case models.StatusCancelled:
+		event.Status = ical.StatusCancelled
+		event.Sequence = 1
+	default:
+		event.Status = ical.StatusTentative
+	}
+
+	return event
+}
+
+func resourceLocation(resource *models.Resource) string {
+	parts := make([]string, 0, 2)
+	for _, p := range []string{resource.Address, resource.City} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
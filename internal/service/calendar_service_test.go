@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+type feedTokenRepo struct {
+	repository.CalendarFeedRepository
+	issued int
+}
+
+func (r *feedTokenRepo) SetToken(ctx context.Context, scope string, entityID int64, tokenHash string) error {
+	r.issued++
+	return nil
+}
+
+type feedUserRepo struct {
+	repository.UserRepository
+}
+
+func (r *feedUserRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	return &models.User{ID: id}, nil
+}
+
+type feedResourceRepo struct {
+	repository.ResourceRepository
+	ownerID *int64
+}
+
+func (r *feedResourceRepo) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
+	return &models.Resource{ID: id, OwnerID: r.ownerID}, nil
+}
+
+func TestIssueFeedTokenChecksCaller(t *testing.T) {
+	owner := int64(5)
+
+	tests := []struct {
+		name     string
+		scope    string
+		ownerID  *int64
+		entityID int64
+		callerID int64
+		wantErr  error
+	}{
+		{"own user feed", repository.CalendarFeedUser, nil, 7, 7, nil},
+		{"another user's feed", repository.CalendarFeedUser, nil, 7, 8, ErrFeedAccessDenied},
+		{"owner's resource feed", repository.CalendarFeedResource, &owner, 3, owner, nil},
+		{"another owner's resource", repository.CalendarFeedResource, &owner, 3, 6, ErrFeedAccessDenied},
+		{"resource without owner", repository.CalendarFeedResource, nil, 3, owner, ErrFeedAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feeds := &feedTokenRepo{}
+			svc := NewCalendarService(feeds, nil, &feedResourceRepo{ownerID: tt.ownerID}, &feedUserRepo{})
+
+			token, err := svc.IssueFeedToken(context.Background(), tt.scope, tt.entityID, tt.callerID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueFeedToken = %v, want %v", err, tt.wantErr)
+			}
+			// Отказ не должен менять действующий токен владельца
+			wantIssued := 1
+			if tt.wantErr != nil {
+				wantIssued = 0
+			}
+			if feeds.issued != wantIssued || (tt.wantErr == nil) != (token != "") {
+				t.Errorf("token %q, %d tokens stored, want %d", token, feeds.issued, wantIssued)
+			}
+		})
+	}
+}
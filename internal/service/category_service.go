@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"smartbooking/internal/audit"
 	"smartbooking/internal/models"
 	"smartbooking/internal/repository"
 )
@@ -17,11 +18,15 @@ type CategoryService interface {
 
 type categoryService struct {
 	categoryRepo repository.CategoryRepository
+	tx           repository.Transactor
+	auditor      audit.Recorder
 }
 
-func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+func NewCategoryService(categoryRepo repository.CategoryRepository, tx repository.Transactor, auditor audit.Recorder) CategoryService {
 	return &categoryService{
 		categoryRepo: categoryRepo,
+		tx:           tx,
+		auditor:      auditor,
 	}
 }
 
@@ -33,7 +38,13 @@ func (s *categoryService) Create(ctx context.Context, name, slug, description, i
 		Icon:        icon,
 	}
 
-	if err := s.categoryRepo.Create(ctx, category); err != nil {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.categoryRepo.Create(ctx, category); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionCreate, audit.EntityCategory, category.ID, nil, category)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -54,12 +65,19 @@ func (s *categoryService) Update(ctx context.Context, id int64, name, slug, desc
 		return nil, err
 	}
 
+	before := *category
 	category.Name = name
 	category.Slug = slug
 	category.Description = description
 	category.Icon = icon
 
-	if err := s.categoryRepo.Update(ctx, category); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.categoryRepo.Update(ctx, category); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityCategory, category.ID, &before, category)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -67,5 +85,15 @@ func (s *categoryService) Update(ctx context.Context, id int64, name, slug, desc
 }
 
 func (s *categoryService) Delete(ctx context.Context, id int64) error {
-	return s.categoryRepo.Delete(ctx, id)
+	before, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.categoryRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionDelete, audit.EntityCategory, id, before, nil)
+	})
 }
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+	"smartbooking/internal/repository"
+)
+
+var (
+	ErrInvalidCoupon       = errors.New("invalid coupon")
+	ErrCouponInactive      = errors.New("promo code is not active")
+	ErrCouponExpired       = errors.New("promo code is expired or not yet valid")
+	ErrCouponNotApplicable = errors.New("promo code does not apply to this resource")
+)
+
+// CouponService handles promo codes and discount calculation
+type CouponService interface {
+	Create(ctx context.Context, req models.CouponRequest) (*models.Coupon, error)
+	GetByID(ctx context.Context, id int64) (*models.Coupon, error)
+	List(ctx context.Context) ([]*models.Coupon, error)
+	Update(ctx context.Context, id int64, req models.CouponRequest) (*models.Coupon, error)
+	Delete(ctx context.Context, id int64) error
+	Quote(ctx context.Context, code string, userID int64, resource *models.Resource, subtotal money.Amount) (*models.Coupon, *models.CouponQuote, error)
+	Redeem(ctx context.Context, coupon *models.Coupon, userID, bookingID int64, discount money.Amount) error
+}
+
+type couponService struct {
+	couponRepo repository.CouponRepository
+}
+
+// NewCouponService creates a new CouponService instance
+func NewCouponService(couponRepo repository.CouponRepository) CouponService {
+	return &couponService{
+		couponRepo: couponRepo,
+	}
+}
+
+func (s *couponService) Create(ctx context.Context, req models.CouponRequest) (*models.Coupon, error) {
+	coupon := &models.Coupon{IsActive: true}
+	applyCouponRequest(coupon, req)
+
+	if err := validateCoupon(coupon); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+func (s *couponService) GetByID(ctx context.Context, id int64) (*models.Coupon, error) {
+	return s.couponRepo.GetByID(ctx, id)
+}
+
+func (s *couponService) List(ctx context.Context) ([]*models.Coupon, error) {
+	return s.couponRepo.List(ctx)
+}
+
+func (s *couponService) Update(ctx context.Context, id int64, req models.CouponRequest) (*models.Coupon, error) {
+	coupon, err := s.couponRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyCouponRequest(coupon, req)
+
+	if err := validateCoupon(coupon); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, err
+	}
+
+	return coupon, nil
+}
+
+func (s *couponService) Delete(ctx context.Context, id int64) error {
+	return s.couponRepo.Delete(ctx, id)
+}
+
+// Quote проверяет промокод для бронирования ресурса и рассчитывает скидку
+func (s *couponService) Quote(ctx context.Context, code string, userID int64, resource *models.Resource, subtotal money.Amount) (*models.Coupon, *models.CouponQuote, error) {
+	coupon, err := s.couponRepo.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repository.ErrCouponNotFound) {
+			return nil, nil, ErrInvalidCoupon
+		}
+		return nil, nil, err
+	}
+
+	if !coupon.IsActive {
+		return nil, nil, ErrCouponInactive
+	}
+
+	now := time.Now()
+	if coupon.ValidFrom != nil && now.Before(*coupon.ValidFrom) {
+		return nil, nil, ErrCouponExpired
+	}
+	if coupon.ValidUntil != nil && now.After(*coupon.ValidUntil) {
+		return nil, nil, ErrCouponExpired
+	}
+
+	if !couponAppliesTo(coupon, resource) || subtotal <= 0 {
+		return nil, nil, ErrCouponNotApplicable
+	}
+	// Фиксированная скидка действует только в своей валюте
+	if coupon.DiscountType == models.DiscountFixed && coupon.Currency != resource.Currency {
+		return nil, nil, ErrCouponNotApplicable
+	}
+
+	if coupon.MaxUses != nil && coupon.TimesUsed >= *coupon.MaxUses {
+		return nil, nil, repository.ErrCouponUsageLimit
+	}
+	if coupon.MaxUsesPerUser != nil {
+		used, err := s.couponRepo.CountUserRedemptions(ctx, coupon.ID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if used >= *coupon.MaxUsesPerUser {
+			return nil, nil, repository.ErrCouponUsageLimit
+		}
+	}
+
+	discount := calculateDiscount(coupon, subtotal)
+
+	return coupon, &models.CouponQuote{
+		Code:           coupon.Code,
+		Currency:       resource.Currency,
+		Subtotal:       subtotal,
+		DiscountAmount: discount,
+		TotalPrice:     subtotal - discount,
+	}, nil
+}
+
+// Redeem фиксирует применение купона; лимиты перепроверяются в транзакции
+func (s *couponService) Redeem(ctx context.Context, coupon *models.Coupon, userID, bookingID int64, discount money.Amount) error {
+	return s.couponRepo.Redeem(ctx, coupon, &models.CouponRedemption{
+		UserID:         userID,
+		BookingID:      bookingID,
+		DiscountAmount: discount,
+	})
+}
+
+// Вспомогательные функции
+
+func applyCouponRequest(coupon *models.Coupon, req models.CouponRequest) {
+	coupon.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	coupon.Description = req.Description
+	coupon.DiscountType = req.DiscountType
+	coupon.DiscountValue = req.DiscountValue
+	coupon.Currency = ""
+	if req.DiscountType == models.DiscountFixed {
+		coupon.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+		if coupon.Currency == "" {
+			coupon.Currency = money.DefaultCurrency
+		}
+	}
+	coupon.ValidFrom = req.ValidFrom
+	coupon.ValidUntil = req.ValidUntil
+	coupon.MaxUses = req.MaxUses
+	coupon.MaxUsesPerUser = req.MaxUsesPerUser
+	if req.IsActive != nil {
+		coupon.IsActive = *req.IsActive
+	}
+	coupon.CategoryIDs = req.CategoryIDs
+	coupon.ResourceIDs = req.ResourceIDs
+	if coupon.CategoryIDs == nil {
+		coupon.CategoryIDs = []int64{}
+	}
+	if coupon.ResourceIDs == nil {
+		coupon.ResourceIDs = []int64{}
+	}
+}
+
+func validateCoupon(coupon *models.Coupon) error {
+	if coupon.Code == "" {
+		return errors.New("coupon code is required")
+	}
+	switch coupon.DiscountType {
+	case models.DiscountPercent:
+		if coupon.DiscountValue <= 0 || coupon.DiscountValue > 100 {
+			return errors.New("percent discount must be between 0 and 100")
+		}
+	case models.DiscountFixed:
+		if coupon.DiscountValue <= 0 {
+			return errors.New("fixed discount must be positive")
+		}
+		if _, err := money.NormalizeCurrency(coupon.Currency); err != nil {
+			return errors.New("currency must be a 3-letter ISO 4217 code")
+		}
+	default:
+		return errors.New("discount_type must be 'percent' or 'fixed'")
+	}
+	if coupon.ValidFrom != nil && coupon.ValidUntil != nil && !coupon.ValidUntil.After(*coupon.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	if coupon.MaxUses != nil && *coupon.MaxUses <= 0 {
+		return errors.New("max_uses must be positive")
+	}
+	if coupon.MaxUsesPerUser != nil && *coupon.MaxUsesPerUser <= 0 {
+		return errors.New("max_uses_per_user must be positive")
+	}
+	return nil
+}
+
+// couponAppliesTo проверяет ограничения по ресурсам и категориям.
+// Пустые списки означают, что купон действует на все ресурсы.
+func couponAppliesTo(coupon *models.Coupon, resource *models.Resource) bool {
+	if len(coupon.ResourceIDs) == 0 && len(coupon.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range coupon.ResourceIDs {
+		if id == resource.ID {
+			return true
+		}
+	}
+	if resource.CategoryID != nil {
+		for _, id := range coupon.CategoryIDs {
+			if id == *resource.CategoryID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func calculateDiscount(coupon *models.Coupon, subtotal money.Amount) money.Amount {
+	var discount money.Amount
+	switch coupon.DiscountType {
+	case models.DiscountPercent:
+		discount = subtotal.Percent(coupon.DiscountValue)
+	case models.DiscountFixed:
+		discount = money.FromFloat(coupon.DiscountValue)
+	}
+	return min(discount, subtotal)
+}
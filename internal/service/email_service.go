@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"smartbooking/internal/email"
+	"smartbooking/internal/events"
+	"smartbooking/internal/invoice"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/retry"
+)
+
+const (
+	emailBatchSize = 20
+	emailLease     = 5 * time.Minute
+)
+
+// emailBackoff задержка повтора письма: 1, 2, 4, ... минут, но не больше 6 часов
+var emailBackoff = retry.Backoff{Base: time.Minute, Max: 6 * time.Hour}
+
+// EmailService queues localized emails and delivers them from the outbox
+type EmailService interface {
+	HandleEvent(ctx context.Context, event events.Event) error
+	SendPasswordReset(ctx context.Context, user *models.User, link string, ttl time.Duration) error
+	ProcessOutbox(ctx context.Context) (int, error)
+}
+
+type emailService struct {
+	outboxRepo   repository.EmailOutboxRepository
+	userRepo     repository.UserRepository
+	resourceRepo repository.ResourceRepository
+	sender       email.EmailSender
+	renderer     *email.Renderer
+	from         string
+	maxAttempts  int
+}
+
+// NewEmailService creates a new EmailService instance. Письма только ставятся в очередь,
+// отправку выполняет ProcessOutbox из фонового воркера.
+func NewEmailService(outboxRepo repository.EmailOutboxRepository, userRepo repository.UserRepository, resourceRepo repository.ResourceRepository, sender email.EmailSender, renderer *email.Renderer, from string, maxAttempts int) EmailService {
+	return &emailService{
+		outboxRepo:   outboxRepo,
+		userRepo:     userRepo,
+		resourceRepo: resourceRepo,
+		sender:       sender,
+		renderer:     renderer,
+		from:         from,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// EmailEvents перечисляет события, на которые подписывается HandleEvent
+var EmailEvents = []events.Type{
+	events.BookingCreated,
+	events.BookingConfirmed,
+	events.BookingCancelled,
+	events.BookingReminder,
+}
+
+// HandleEvent ставит в очередь письмо клиенту о его бронировании. Письмо
+// помечается номером события, чтобы повторная доставка не дублировала его.
+func (s *emailService) HandleEvent(ctx context.Context, event events.Event) error {
+	booking := event.Booking
+	if booking == nil {
+		return nil
+	}
+
+	var template string
+	switch event.Type {
+	case events.BookingCreated, events.BookingConfirmed:
+		template = email.TemplateBookingConfirmation
+	case events.BookingCancelled:
+		template = email.TemplateBookingCancellation
+	case events.BookingReminder:
+		template = email.TemplateBookingReminder
+	default:
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, booking.UserID)
+	if err != nil {
+		return err
+	}
+	resource, err := s.resourceRepo.GetByID(ctx, booking.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	// Время показывается по месту нахождения ресурса
+	locale := email.NormalizeLocale(user.Locale)
+	loc := resource.Location()
+	var eventID *int64
+	if event.ID != 0 {
+		eventID = &event.ID
+	}
+	return s.enqueue(ctx, user, template, eventID, email.BookingData{
+		Name:         user.Name,
+		BookingID:    booking.ID,
+		ResourceName: resource.Name,
+		Start:        formatEmailTime(booking.StartTime.In(loc), locale),
+		End:          formatEmailTime(booking.EndTime.In(loc), locale),
+		Total:        invoice.FormatMoney(booking.TotalPrice, booking.Currency),
+		Confirmed:    booking.Status == models.StatusConfirmed,
+	})
+}
+
+func (s *emailService) SendPasswordReset(ctx context.Context, user *models.User, link string, ttl time.Duration) error {
+	expiresIn := fmt.Sprintf("%d min", int(ttl.Minutes()))
+	if email.NormalizeLocale(user.Locale) == email.LocaleRU {
+		expiresIn = fmt.Sprintf("%d мин.", int(ttl.Minutes()))
+	}
+
+	return s.enqueue(ctx, user, email.TemplatePasswordReset, nil, email.PasswordResetData{
+		Name:      user.Name,
+		Link:      link,
+		ExpiresIn: expiresIn,
+	})
+}
+
+func (s *emailService) enqueue(ctx context.Context, user *models.User, template string, eventID *int64, data any) error {
+	msg, err := s.renderer.Render(user.Locale, template, data)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepo.Enqueue(ctx, &models.OutboxEmail{
+		To:       user.Email,
+		Subject:  msg.Subject,
+		TextBody: msg.Text,
+		HTMLBody: msg.HTML,
+		Template: template,
+		EventID:  eventID,
+	})
+}
+
+// ProcessOutbox отправляет очередную пачку писем и возвращает число отправленных.
+// После ошибки письмо откладывается с экспоненциальной задержкой, после
+// maxAttempts попыток помечается как failed.
+func (s *emailService) ProcessOutbox(ctx context.Context) (int, error) {
+	batch, err := s.outboxRepo.ClaimDue(ctx, emailBatchSize, emailLease)
+	if err != nil {
+		return 0, err
+	}
+
+	queue := &retry.Queue[*models.OutboxEmail]{
+		Backoff:     emailBackoff,
+		MaxAttempts: s.maxAttempts,
+		Attempts:    func(e *models.OutboxEmail) int { return e.Attempts },
+		Send: func(ctx context.Context, e *models.OutboxEmail) error {
+			return s.sender.Send(ctx, &email.Message{
+				From:    s.from,
+				To:      e.To,
+				Subject: e.Subject,
+				Text:    e.TextBody,
+				HTML:    e.HTMLBody,
+			})
+		},
+		Done: func(ctx context.Context, e *models.OutboxEmail) error {
+			return s.outboxRepo.MarkSent(ctx, e.ID)
+		},
+		Failed: func(ctx context.Context, e *models.OutboxEmail, attempt int, err error, next *time.Time) error {
+			logger.Error("Email %d to %s failed (attempt %d/%d): %v", e.ID, e.To, attempt, s.maxAttempts, err)
+			return s.outboxRepo.MarkFailed(ctx, e.ID, err.Error(), next)
+		},
+	}
+
+	return queue.Process(ctx, batch)
+}
+
+func formatEmailTime(t time.Time, locale string) string {
+	if locale == email.LocaleEN {
+		return t.Format("Jan 2, 2006 15:04")
+	}
+	return t.Format("02.01.2006 15:04")
+}
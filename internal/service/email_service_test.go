@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smartbooking/internal/email"
+	"smartbooking/internal/events"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+type recordingOutboxRepo struct {
+	repository.EmailOutboxRepository
+	queued []*models.OutboxEmail
+}
+
+func (r *recordingOutboxRepo) Enqueue(ctx context.Context, e *models.OutboxEmail) error {
+	r.queued = append(r.queued, e)
+	return nil
+}
+
+type emailUserRepo struct {
+	repository.UserRepository
+}
+
+func (r *emailUserRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	return &models.User{ID: id, Name: "Айгерим", Email: "client@example.com", Locale: "ru"}, nil
+}
+
+type emailResourceRepo struct {
+	repository.ResourceRepository
+}
+
+func (r *emailResourceRepo) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
+	return &models.Resource{ID: id, Name: "Переговорная"}, nil
+}
+
+func TestEmailEventIDMarksQueuedEmail(t *testing.T) {
+	renderer, err := email.NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	outbox := &recordingOutboxRepo{}
+	svc := NewEmailService(outbox, &emailUserRepo{}, &emailResourceRepo{}, nil, renderer, "noreply@example.com", 5)
+	ctx := context.Background()
+
+	start := time.Now().Add(24 * time.Hour)
+	booking := &models.Booking{ID: 1, UserID: 2, ResourceID: 3, StartTime: start, EndTime: start.Add(time.Hour), Status: models.StatusConfirmed}
+	if err := svc.HandleEvent(ctx, events.Event{ID: 42, Type: events.BookingConfirmed, Booking: booking}); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+	if err := svc.SendPasswordReset(ctx, &models.User{ID: 2, Email: "client@example.com"}, "https://example.com/reset", time.Hour); err != nil {
+		t.Fatalf("SendPasswordReset: %v", err)
+	}
+
+	if len(outbox.queued) != 2 {
+		t.Fatalf("queued %d emails, want 2", len(outbox.queued))
+	}
+	// По номеру события репозиторий отсеивает повторную доставку
+	if id := outbox.queued[0].EventID; id == nil || *id != 42 {
+		t.Errorf("booking email event_id = %v, want 42", id)
+	}
+	if id := outbox.queued[1].EventID; id != nil {
+		t.Errorf("password reset event_id = %d, want none", *id)
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+var (
+	ErrInvoiceNotAvailable = errors.New("invoice is available only for confirmed or paid bookings")
+)
+
+// InvoiceService issues invoices for bookings
+type InvoiceService interface {
+	GetForBooking(ctx context.Context, bookingID int64) (*models.Invoice, error)
+	ListByOwner(ctx context.Context, ownerID int64) ([]*models.Invoice, error)
+}
+
+type invoiceService struct {
+	invoiceRepo  repository.InvoiceRepository
+	bookingRepo  repository.BookingRepository
+	resourceRepo repository.ResourceRepository
+	userRepo     repository.UserRepository
+	numberPrefix string
+	taxRate      float64
+}
+
+// NewInvoiceService creates a new InvoiceService instance. taxRate задается в процентах
+// и считается включенным в стоимость бронирования.
+func NewInvoiceService(invoiceRepo repository.InvoiceRepository, bookingRepo repository.BookingRepository, resourceRepo repository.ResourceRepository, userRepo repository.UserRepository, numberPrefix string, taxRate float64) InvoiceService {
+	return &invoiceService{
+		invoiceRepo:  invoiceRepo,
+		bookingRepo:  bookingRepo,
+		resourceRepo: resourceRepo,
+		userRepo:     userRepo,
+		numberPrefix: numberPrefix,
+		taxRate:      taxRate,
+	}
+}
+
+// GetForBooking возвращает счет по бронированию, выставляя его при первом запросе
+func (s *invoiceService) GetForBooking(ctx context.Context, bookingID int64) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepo.GetByBooking(ctx, bookingID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, repository.ErrInvoiceNotFound) {
+		return nil, err
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status == models.StatusCancelled ||
+		(booking.Status != models.StatusConfirmed && booking.AmountPaid <= 0) {
+		return nil, ErrInvoiceNotAvailable
+	}
+
+	invoice, err = s.buildInvoice(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	// Параллельный запрос мог выставить счет раньше — тогда возвращаем его
+	if err := s.invoiceRepo.Create(ctx, invoice, s.numberPrefix); err != nil && !errors.Is(err, repository.ErrInvoiceExists) {
+		return nil, err
+	}
+
+	return s.invoiceRepo.GetByBooking(ctx, bookingID)
+}
+
+func (s *invoiceService) ListByOwner(ctx context.Context, ownerID int64) ([]*models.Invoice, error) {
+	return s.invoiceRepo.ListByOwner(ctx, ownerID)
+}
+
+// buildInvoice собирает позиции счета из расчета цены, сохраненного с
+// бронированием: по позиции на тариф, затем скидка по промокоду
+func (s *invoiceService) buildInvoice(ctx context.Context, booking *models.Booking) (*models.Invoice, error) {
+	resource, err := s.resourceRepo.GetByID(ctx, booking.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	priceItems, err := s.bookingRepo.GetPriceItems(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal := booking.TotalPrice + booking.DiscountAmount
+	items := make([]*models.InvoiceItem, 0, len(priceItems)+1)
+	for _, p := range priceItems {
+		items = append(items, &models.InvoiceItem{
+			Description: rentalDescription(resource.Name, p),
+			Quantity:    p.Quantity,
+			UnitPrice:   p.UnitPrice,
+			Amount:      p.Amount,
+		})
+	}
+	if len(priceItems) == 0 {
+		// Бронирования до расчета по тарифам: аренда одной позицией за весь период
+		loc := resource.Location()
+		items = append(items, &models.InvoiceItem{
+			Description: fmt.Sprintf("Rental of %s, %s – %s", resource.Name,
+				booking.StartTime.In(loc).Format("02.01.2006 15:04"), booking.EndTime.In(loc).Format("02.01.2006 15:04")),
+			Quantity:  1,
+			UnitPrice: subtotal,
+			Amount:    subtotal,
+		})
+	}
+	if booking.DiscountAmount > 0 {
+		items = append(items, &models.InvoiceItem{
+			Description: "Promo code discount",
+			Quantity:    1,
+			UnitPrice:   -booking.DiscountAmount,
+			Amount:      -booking.DiscountAmount,
+		})
+	}
+
+	return &models.Invoice{
+		BookingID:      booking.ID,
+		ResourceID:     &resource.ID,
+		OwnerID:        resource.OwnerID,
+		CustomerName:   user.Name,
+		CustomerEmail:  user.Email,
+		ResourceName:   resource.Name,
+		Currency:       booking.Currency,
+		Subtotal:       subtotal,
+		DiscountAmount: booking.DiscountAmount,
+		TaxRate:        s.taxRate,
+		TaxAmount:      booking.TotalPrice.MulFloat(s.taxRate / (100 + s.taxRate)),
+		Total:          booking.TotalPrice,
+		Items:          items,
+	}, nil
+}
+
+// rentalDescription описание позиции аренды по тарифу и его единице
+func rentalDescription(resourceName string, item *models.PriceItem) string {
+	unit := "hours"
+	if item.UnitMinutes != 60 {
+		unit = fmt.Sprintf("per %d min", item.UnitMinutes)
+	}
+	return fmt.Sprintf("Rental of %s: %s (%s)", resourceName, item.Name, unit)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+	"smartbooking/internal/repository"
+)
+
+type invoiceBookingRepo struct {
+	repository.BookingRepository
+	items []*models.PriceItem
+}
+
+func (r *invoiceBookingRepo) GetPriceItems(ctx context.Context, bookingID int64) ([]*models.PriceItem, error) {
+	return r.items, nil
+}
+
+type invoiceResourceRepo struct {
+	repository.ResourceRepository
+}
+
+func (r *invoiceResourceRepo) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
+	return &models.Resource{ID: id, Name: "Sauna", Timezone: "Asia/Almaty"}, nil
+}
+
+type invoiceUserRepo struct {
+	repository.UserRepository
+}
+
+func (r *invoiceUserRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	return &models.User{ID: id, Name: "Customer", Email: "customer@example.com"}, nil
+}
+
+func TestBuildInvoiceItemsFollowPriceBreakdown(t *testing.T) {
+	ruleID := int64(3)
+	breakdown := []*models.PriceItem{
+		{Name: "Базовый тариф", UnitMinutes: 60, Quantity: 1.5, UnitPrice: money.FromMinor(333_333), Amount: money.FromMinor(333_333).MulFloat(1.5)},
+		{PricingID: &ruleID, Name: "Вечер", UnitMinutes: 30, Quantity: 3, UnitPrice: money.FromMinor(100_001), Amount: money.FromMinor(300_003)},
+	}
+	var subtotal money.Amount
+	for _, item := range breakdown {
+		subtotal += item.Amount
+	}
+	discount := money.FromMinor(10_000)
+
+	start := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	booking := &models.Booking{
+		ID: 1, UserID: 2, ResourceID: 3,
+		StartTime: start, EndTime: start.Add(3 * time.Hour),
+		TotalPrice: subtotal - discount, DiscountAmount: discount,
+	}
+
+	tests := []struct {
+		name      string
+		breakdown []*models.PriceItem
+		wantItems int
+	}{
+		{"from breakdown", breakdown, 3},
+		{"booking without breakdown", nil, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &invoiceService{
+				bookingRepo:  &invoiceBookingRepo{items: tt.breakdown},
+				resourceRepo: &invoiceResourceRepo{},
+				userRepo:     &invoiceUserRepo{},
+			}
+
+			invoice, err := svc.buildInvoice(context.Background(), booking)
+			if err != nil {
+				t.Fatalf("buildInvoice: %v", err)
+			}
+			if len(invoice.Items) != tt.wantItems {
+				t.Fatalf("got %d items, want %d", len(invoice.Items), tt.wantItems)
+			}
+
+			var sum money.Amount
+			for _, item := range invoice.Items {
+				if got := item.UnitPrice.MulFloat(item.Quantity); got != item.Amount {
+					t.Errorf("%q: %s × %v = %s, amount is %s", item.Description, item.UnitPrice, item.Quantity, got, item.Amount)
+				}
+				sum += item.Amount
+			}
+			if sum != invoice.Total {
+				t.Errorf("items sum to %s, total is %s", sum, invoice.Total)
+			}
+			if invoice.Subtotal != subtotal {
+				t.Errorf("subtotal = %s, want %s", invoice.Subtotal, subtotal)
+			}
+		})
+	}
+}
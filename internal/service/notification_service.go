@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+)
+
+// NotificationService manages in-app notifications and creates them from domain events
+type NotificationService interface {
+	List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	MarkRead(ctx context.Context, id, userID int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	HandleEvent(ctx context.Context, event events.Event) error
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	resourceRepo     repository.ResourceRepository
+	tx               repository.Transactor
+}
+
+// NewNotificationService creates a new NotificationService instance
+func NewNotificationService(notificationRepo repository.NotificationRepository, resourceRepo repository.ResourceRepository, tx repository.Transactor) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		resourceRepo:     resourceRepo,
+		tx:               tx,
+	}
+}
+
+// NotificationEvents перечисляет события, на которые подписывается HandleEvent
+var NotificationEvents = []events.Type{
+	events.BookingCreated,
+	events.BookingConfirmed,
+	events.BookingCancelled,
+	events.BookingReminder,
+	events.ReviewReplied,
+}
+
+func (s *notificationService) List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*models.Notification, error) {
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+	if limit > maxNotificationLimit {
+		limit = maxNotificationLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (s *notificationService) CountUnread(ctx context.Context, userID int64) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, id, userID int64) error {
+	return s.notificationRepo.MarkRead(ctx, id, userID)
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+// HandleEvent создает уведомления по событию: клиенту — о его бронировании,
+// владельцу ресурса — о новых и отмененных бронированиях. Оба уведомления
+// создаются в одной транзакции: если второе не записалось, откатывается и
+// первое, и повторная доставка события не продублирует его.
+func (s *notificationService) HandleEvent(ctx context.Context, event events.Event) error {
+	if event.Type == events.ReviewReplied {
+		if event.Review == nil {
+			return nil
+		}
+		return s.notificationRepo.Create(ctx, &models.Notification{
+			UserID:            event.Review.UserID,
+			Title:             "Ответ на отзыв",
+			Message:           "Владелец ресурса ответил на ваш отзыв",
+			Type:              models.NotificationInfo,
+			RelatedEntityType: "review",
+			RelatedEntityID:   &event.Review.ID,
+		})
+	}
+
+	booking := event.Booking
+	if booking == nil {
+		return nil
+	}
+
+	resource, err := s.resourceRepo.GetByID(ctx, booking.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	when := booking.StartTime.In(resource.Location()).Format("02.01.2006 15:04")
+	n := &models.Notification{
+		UserID:            booking.UserID,
+		RelatedEntityType: "booking",
+		RelatedEntityID:   &booking.ID,
+	}
+	var ownerMessage string
+
+	switch event.Type {
+	case events.BookingCreated:
+		n.Title = "Бронирование создано"
+		n.Message = fmt.Sprintf("Ваше бронирование %s на %s создано и ожидает подтверждения", resource.Name, when)
+		if booking.Status == models.StatusConfirmed {
+			n.Message = fmt.Sprintf("Ваше бронирование %s на %s создано и подтверждено", resource.Name, when)
+		}
+		n.Type = models.NotificationInfo
+		ownerMessage = fmt.Sprintf("Новое бронирование %s на %s", resource.Name, when)
+	case events.BookingConfirmed:
+		n.Title = "Бронирование подтверждено"
+		n.Message = fmt.Sprintf("Ваше бронирование %s на %s успешно подтверждено", resource.Name, when)
+		n.Type = models.NotificationSuccess
+	case events.BookingCancelled:
+		n.Title = "Бронирование отменено"
+		n.Message = fmt.Sprintf("Бронирование %s на %s отменено", resource.Name, when)
+		n.Type = models.NotificationWarning
+		ownerMessage = n.Message
+	case events.BookingReminder:
+		n.Title = "Напоминание"
+		n.Message = fmt.Sprintf("Напоминаем о бронировании %s на %s", resource.Name, when)
+		n.Type = models.NotificationInfo
+	default:
+		return nil
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.notificationRepo.Create(ctx, n); err != nil {
+			return err
+		}
+
+		if ownerMessage != "" && resource.OwnerID != nil && *resource.OwnerID != booking.UserID {
+			return s.notificationRepo.Create(ctx, &models.Notification{
+				UserID:            *resource.OwnerID,
+				Title:             n.Title,
+				Message:           ownerMessage,
+				Type:              n.Type,
+				RelatedEntityType: "booking",
+				RelatedEntityID:   &booking.ID,
+			})
+		}
+		return nil
+	})
+}
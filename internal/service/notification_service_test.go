@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+var errNotificationInsert = errors.New("insert failed")
+
+// memoryNotificationRepo сохраняет уведомления и может отказать на записи для failFor
+type memoryNotificationRepo struct {
+	repository.NotificationRepository
+	saved   []*models.Notification
+	failFor int64
+}
+
+func (r *memoryNotificationRepo) Create(ctx context.Context, n *models.Notification) error {
+	if n.UserID == r.failFor {
+		return errNotificationInsert
+	}
+	r.saved = append(r.saved, n)
+	return nil
+}
+
+// rollbackTransactor отбрасывает уведомления, записанные в неудачной транзакции
+type rollbackTransactor struct {
+	repo *memoryNotificationRepo
+}
+
+func (t rollbackTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := len(t.repo.saved)
+	if err := fn(ctx); err != nil {
+		t.repo.saved = t.repo.saved[:saved]
+		return err
+	}
+	return nil
+}
+
+func TestNotificationsForBookingCommitTogether(t *testing.T) {
+	const client, owner = 2, 5
+	ownerID := int64(owner)
+
+	tests := []struct {
+		name      string
+		failFor   int64
+		wantErr   error
+		wantSaved int
+	}{
+		{"both saved", 0, nil, 2},
+		// Клиентское уведомление не остается без владельческого: повтор
+		// события после ошибки не создаст клиенту второе
+		{"owner insert fails", owner, errNotificationInsert, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryNotificationRepo{failFor: tt.failFor}
+			svc := NewNotificationService(repo, &feedResourceRepo{ownerID: &ownerID}, rollbackTransactor{repo: repo})
+
+			booking := &models.Booking{ID: 1, UserID: client, ResourceID: 3, StartTime: time.Now(), Status: models.StatusConfirmed}
+			err := svc.HandleEvent(context.Background(), events.Event{ID: 9, Type: events.BookingCreated, Booking: booking})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HandleEvent = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.saved) != tt.wantSaved {
+				t.Errorf("saved %d notifications, want %d", len(repo.saved), tt.wantSaved)
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"smartbooking/internal/models"
+	"smartbooking/internal/money"
 	"smartbooking/internal/repository"
 )
 
@@ -11,16 +15,49 @@ type OwnerService interface {
 	GetOwnerResources(ctx context.Context, ownerID int64) ([]*models.Resource, error)
 	GetOwnerBookings(ctx context.Context, ownerID int64) ([]*models.Booking, error)
 	GetOwnerStatistics(ctx context.Context, ownerID int64) (*repository.OwnerStatistics, error)
+	GetOwnerCalendar(ctx context.Context, ownerID int64, query OwnerCalendarQuery) (*models.OwnerCalendar, error)
+}
+
+var (
+	ErrInvalidCalendarView  = errors.New("calendar view must be day, week or month")
+	ErrInvalidCalendarRange = errors.New("calendar range must end after it starts and span at most 92 days")
+	ErrInvalidCalendarTime  = errors.New("calendar date must be RFC3339 time or YYYY-MM-DD")
+)
+
+// Виды календаря владельца
+const (
+	CalendarViewDay   = "day"
+	CalendarViewWeek  = "week"
+	CalendarViewMonth = "month"
+)
+
+// maxOwnerCalendarDays ограничение длины произвольного диапазона календаря
+const maxOwnerCalendarDays = 92
+
+// OwnerCalendarQuery задает период календаря владельца: либо явный диапазон
+// From–To, либо день, неделю (с понедельника) или месяц, содержащие Date.
+// Даты задаются в RFC3339 или как YYYY-MM-DD; дата в To включает весь день.
+// Дни каждого ресурса отсчитываются в его часовом поясе, а если задан
+// Location — в нем для всех ресурсов.
+type OwnerCalendarQuery struct {
+	View       string
+	Date       string
+	From       string
+	To         string
+	Location   *time.Location
+	ResourceID *int64
 }
 
 type ownerService struct {
 	ownerRepo repository.OwnerRepository
+	rates     *money.Rates
 }
 
-// NewOwnerService creates a new OwnerService
-func NewOwnerService(ownerRepo repository.OwnerRepository) OwnerService {
+// NewOwnerService creates a new OwnerService. rates is used to report revenue totals in the base currency
+func NewOwnerService(ownerRepo repository.OwnerRepository, rates *money.Rates) OwnerService {
 	return &ownerService{
 		ownerRepo: ownerRepo,
+		rates:     rates,
 	}
 }
 
@@ -36,5 +73,241 @@ func (s *ownerService) GetOwnerBookings(ctx context.Context, ownerID int64) ([]*
 
 // GetOwnerStatistics retrieves statistics for an owner
 func (s *ownerService) GetOwnerStatistics(ctx context.Context, ownerID int64) (*repository.OwnerStatistics, error) {
-	return s.ownerRepo.GetOwnerStatistics(ctx, ownerID)
+	stats, err := s.ownerRepo.GetOwnerStatistics(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	revenue := newRevenueTotal(s.rates)
+	collected := newRevenueTotal(s.rates)
+	outstanding := newRevenueTotal(s.rates)
+	for _, item := range stats.RevenueByCurrency {
+		if err := revenue.add(item.Revenue, item.Currency); err != nil {
+			return nil, err
+		}
+		if err := collected.add(item.Collected, item.Currency); err != nil {
+			return nil, err
+		}
+		if err := outstanding.add(item.Outstanding, item.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	stats.Currency = s.rates.Base()
+	stats.TotalRevenue = revenue.total
+	stats.CollectedRevenue = collected.total
+	stats.OutstandingRevenue = outstanding.total
+	stats.UnconvertedCurrencies = revenue.unconverted
+
+	return stats, nil
+}
+
+// GetOwnerCalendar собирает бронирования, блокировки внешних календарей и часы
+// работы ресурсов владельца по дням периода
+func (s *ownerService) GetOwnerCalendar(ctx context.Context, ownerID int64, query OwnerCalendarQuery) (*models.OwnerCalendar, error) {
+	resources, err := s.ownerRepo.GetOwnerResources(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if query.ResourceID != nil {
+		resources = filterResources(resources, *query.ResourceID)
+		if len(resources) == 0 {
+			return nil, repository.ErrResourceNotFound
+		}
+	}
+
+	// Период считается для каждого ресурса в его поясе; данные читаются за
+	// объединение периодов
+	periods := make([]calendarPeriod, len(resources))
+	for i, resource := range resources {
+		loc := query.Location
+		if loc == nil {
+			loc = resource.Location()
+		}
+		periods[i].loc = loc
+		if periods[i].from, periods[i].to, err = ownerCalendarRange(query, loc); err != nil {
+			return nil, err
+		}
+	}
+	from, to, err := calendarBounds(query, periods)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.ownerRepo.GetOwnerBookingsInRange(ctx, ownerID, query.ResourceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.ownerRepo.GetOwnerBlocks(ctx, ownerID, query.ResourceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.ownerRepo.GetOwnerSchedules(ctx, ownerID, query.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours := make(map[int64]map[int]*models.ResourceSchedule)
+	for _, sc := range schedules {
+		if hours[sc.ResourceID] == nil {
+			hours[sc.ResourceID] = make(map[int]*models.ResourceSchedule)
+		}
+		hours[sc.ResourceID][sc.DayOfWeek] = sc
+	}
+
+	view := query.View
+	switch {
+	case query.From != "" || query.To != "":
+		view = ""
+	case view == "":
+		view = CalendarViewWeek
+	}
+
+	calendar := &models.OwnerCalendar{
+		View:      view,
+		From:      from,
+		To:        to,
+		Resources: make([]*models.OwnerCalendarResource, 0, len(resources)),
+	}
+	for i, resource := range resources {
+		period := periods[i]
+		item := &models.OwnerCalendarResource{
+			ResourceID:   resource.ID,
+			ResourceName: resource.Name,
+			Timezone:     period.loc.String(),
+			From:         period.from,
+			To:           period.to,
+			Days:         make([]*models.OwnerCalendarDay, 0),
+		}
+
+		// Границы дней считаются через AddDate, поэтому дни перехода на летнее
+		// время длятся 23 или 25 часов
+		for day := startOfDay(period.from, period.loc); day.Before(period.to); day = day.AddDate(0, 0, 1) {
+			next := day.AddDate(0, 0, 1)
+			entry := &models.OwnerCalendarDay{
+				Date:      day.Format("2006-01-02"),
+				Hours:     hours[resource.ID][int(day.Weekday())],
+				Bookings:  make([]*models.Booking, 0),
+				Blackouts: make([]*models.ResourceBlock, 0),
+			}
+			for _, b := range bookings {
+				if b.ResourceID == resource.ID && b.StartTime.Before(next) && b.EndTime.After(day) {
+					entry.Bookings = append(entry.Bookings, b)
+				}
+			}
+			for _, b := range blocks {
+				if b.ResourceID == resource.ID && b.StartTime.Before(next) && b.EndTime.After(day) {
+					entry.Blackouts = append(entry.Blackouts, b)
+				}
+			}
+			item.Days = append(item.Days, entry)
+		}
+
+		calendar.Resources = append(calendar.Resources, item)
+	}
+
+	return calendar, nil
+}
+
+// ownerCalendarRange возвращает полуоткрытый интервал [from, to) календаря
+func ownerCalendarRange(query OwnerCalendarQuery, loc *time.Location) (time.Time, time.Time, error) {
+	var from, to time.Time
+	if query.From != "" || query.To != "" {
+		if query.From == "" || query.To == "" {
+			return from, to, ErrInvalidCalendarRange
+		}
+		var err error
+		if from, _, err = parseCalendarTime(query.From, loc); err != nil {
+			return from, to, err
+		}
+		var dateOnly bool
+		if to, dateOnly, err = parseCalendarTime(query.To, loc); err != nil {
+			return from, to, err
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+	} else {
+		date := time.Now()
+		if query.Date != "" {
+			var err error
+			if date, _, err = parseCalendarTime(query.Date, loc); err != nil {
+				return from, to, err
+			}
+		}
+		day := startOfDay(date, loc)
+
+		switch query.View {
+		case CalendarViewDay:
+			from, to = day, day.AddDate(0, 0, 1)
+		case CalendarViewWeek, "":
+			from = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			to = from.AddDate(0, 0, 7)
+		case CalendarViewMonth:
+			from = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, loc)
+			to = from.AddDate(0, 1, 0)
+		default:
+			return from, to, ErrInvalidCalendarView
+		}
+	}
+
+	if !to.After(from) || to.After(from.AddDate(0, 0, maxOwnerCalendarDays)) {
+		return from, to, ErrInvalidCalendarRange
+	}
+	return from, to, nil
+}
+
+// parseCalendarTime разбирает RFC3339 или дату YYYY-MM-DD (полночь в loc)
+func parseCalendarTime(value string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, false, ErrInvalidCalendarTime
+	}
+	return t.In(loc), false, nil
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// calendarPeriod период календаря ресурса в его часовом поясе
+type calendarPeriod struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// calendarBounds объединяет периоды ресурсов. Без ресурсов период все равно
+// проверяется — в Location запроса или в UTC.
+func calendarBounds(query OwnerCalendarQuery, periods []calendarPeriod) (time.Time, time.Time, error) {
+	if len(periods) == 0 {
+		loc := query.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		return ownerCalendarRange(query, loc)
+	}
+
+	from, to := periods[0].from, periods[0].to
+	for _, p := range periods[1:] {
+		if p.from.Before(from) {
+			from = p.from
+		}
+		if p.to.After(to) {
+			to = p.to
+		}
+	}
+	return from, to, nil
+}
+
+func filterResources(resources []*models.Resource, id int64) []*models.Resource {
+	for _, r := range resources {
+		if r.ID == id {
+			return []*models.Resource{r}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+// calendarOwnerRepo отдает заранее заданные ресурсы и бронирования;
+// остальные методы OwnerRepository календарю не нужны
+type calendarOwnerRepo struct {
+	repository.OwnerRepository
+	resources []*models.Resource
+	bookings  []*models.Booking
+}
+
+func (r *calendarOwnerRepo) GetOwnerResources(ctx context.Context, ownerID int64) ([]*models.Resource, error) {
+	return r.resources, nil
+}
+
+func (r *calendarOwnerRepo) GetOwnerBookingsInRange(ctx context.Context, ownerID int64, resourceID *int64, from, to time.Time) ([]*models.Booking, error) {
+	var result []*models.Booking
+	for _, b := range r.bookings {
+		if b.StartTime.Before(to) && b.EndTime.After(from) {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (r *calendarOwnerRepo) GetOwnerBlocks(ctx context.Context, ownerID int64, resourceID *int64, from, to time.Time) ([]*models.ResourceBlock, error) {
+	return nil, nil
+}
+
+func (r *calendarOwnerRepo) GetOwnerSchedules(ctx context.Context, ownerID int64, resourceID *int64) ([]*models.ResourceSchedule, error) {
+	return nil, nil
+}
+
+func TestOwnerCalendarBucketsEachResourceInItsZone(t *testing.T) {
+	utc := func(d, h int) time.Time {
+		return time.Date(2026, time.March, d, h, 0, 0, 0, time.UTC)
+	}
+	repo := &calendarOwnerRepo{
+		resources: []*models.Resource{
+			{ID: 1, Name: "Berlin", Timezone: "Europe/Berlin"},
+			{ID: 2, Name: "Almaty", Timezone: "Asia/Almaty"},
+		},
+		bookings: []*models.Booking{
+			// 21:00–22:00 28 марта в Берлине
+			{ID: 10, ResourceID: 1, StartTime: utc(28, 20), EndTime: utc(28, 21)},
+			// 01:00–02:00 29 марта в Алматы, хотя в UTC это еще 28 марта
+			{ID: 20, ResourceID: 2, StartTime: utc(28, 20), EndTime: utc(28, 21)},
+		},
+	}
+	svc := NewOwnerService(repo, nil)
+
+	tests := []struct {
+		date      string
+		wantDays  map[int64]int64 // ресурс -> бронирование в этот день (0 — нет)
+		wantHours map[int64]time.Duration
+	}{
+		{"2026-03-28", map[int64]int64{1: 10, 2: 0}, map[int64]time.Duration{1: 24 * time.Hour, 2: 24 * time.Hour}},
+		// В Берлине переход на летнее время: сутки длятся 23 часа
+		{"2026-03-29", map[int64]int64{1: 0, 2: 20}, map[int64]time.Duration{1: 23 * time.Hour, 2: 24 * time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			calendar, err := svc.GetOwnerCalendar(context.Background(), 1, OwnerCalendarQuery{View: CalendarViewDay, Date: tt.date})
+			if err != nil {
+				t.Fatalf("GetOwnerCalendar: %v", err)
+			}
+
+			for _, res := range calendar.Resources {
+				if len(res.Days) != 1 || res.Days[0].Date != tt.date {
+					t.Fatalf("resource %d days = %+v, want only %s", res.ResourceID, res.Days, tt.date)
+				}
+				if got := res.To.Sub(res.From); got != tt.wantHours[res.ResourceID] {
+					t.Errorf("resource %d (%s) day lasts %v, want %v", res.ResourceID, res.Timezone, got, tt.wantHours[res.ResourceID])
+				}
+
+				bookings := res.Days[0].Bookings
+				want := tt.wantDays[res.ResourceID]
+				switch {
+				case want == 0 && len(bookings) != 0:
+					t.Errorf("resource %d: unexpected bookings %+v", res.ResourceID, bookings)
+				case want != 0 && (len(bookings) != 1 || bookings[0].ID != want):
+					t.Errorf("resource %d: bookings %+v, want booking %d", res.ResourceID, bookings, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+	"smartbooking/internal/payment"
+	"smartbooking/internal/repository"
+)
+
+var (
+	ErrBookingNotPayable    = errors.New("booking cannot be paid")
+	ErrPaymentNotCaptured   = errors.New("payment is not awaiting capture")
+	ErrPaymentNotRefundable = errors.New("payment cannot be refunded")
+	ErrInstallmentPaid      = errors.New("installment is already paid")
+)
+
+// PaymentService handles booking payments through a PaymentProvider
+type PaymentService interface {
+	CreateForBooking(ctx context.Context, bookingID, installmentID int64) (*models.Payment, error)
+	GetSchedule(ctx context.Context, bookingID int64) (*models.PaymentSchedule, error)
+	GetByID(ctx context.Context, id int64) (*models.Payment, error)
+	ListByBooking(ctx context.Context, bookingID int64) ([]*models.Payment, error)
+	Capture(ctx context.Context, id int64) (*models.Payment, error)
+	Refund(ctx context.Context, id int64, amount money.Amount) (*models.Payment, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+}
+
+type paymentService struct {
+	paymentRepo  repository.PaymentRepository
+	bookingRepo  repository.BookingRepository
+	resourceRepo repository.ResourceRepository
+	provider     payment.PaymentProvider
+	tx           repository.Transactor
+	publisher    events.Publisher
+}
+
+// NewPaymentService creates a new PaymentService instance
+func NewPaymentService(paymentRepo repository.PaymentRepository, bookingRepo repository.BookingRepository, resourceRepo repository.ResourceRepository, provider payment.PaymentProvider, tx repository.Transactor, publisher events.Publisher) PaymentService {
+	return &paymentService{
+		paymentRepo:  paymentRepo,
+		bookingRepo:  bookingRepo,
+		resourceRepo: resourceRepo,
+		provider:     provider,
+		tx:           tx,
+		publisher:    publisher,
+	}
+}
+
+// CreateForBooking создает платеж по позиции графика. installmentID == 0
+// означает ближайшую неоплаченную позицию (обычно депозит).
+func (s *paymentService) CreateForBooking(ctx context.Context, bookingID, installmentID int64) (*models.Payment, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status == models.StatusCancelled || booking.Balance <= 0 {
+		return nil, ErrBookingNotPayable
+	}
+
+	installments, err := s.ensureSchedule(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, err := selectInstallment(installments, installmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Номер попытки входит в ключ идемпотентности, чтобы после отказа можно было оплатить заново
+	attempt, err := s.countAttempts(ctx, booking.ID, inst.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.provider.CreateIntent(ctx, payment.IntentParams{
+		Amount:         inst.Amount,
+		Currency:       booking.Currency,
+		Description:    fmt.Sprintf("Booking #%d (%s)", booking.ID, inst.Kind),
+		IdempotencyKey: fmt.Sprintf("booking-%d-installment-%d-attempt-%d", booking.ID, inst.ID, attempt),
+		Metadata: map[string]string{
+			"booking_id":     strconv.FormatInt(booking.ID, 10),
+			"user_id":        strconv.FormatInt(booking.UserID, 10),
+			"installment_id": strconv.FormatInt(inst.ID, 10),
+			"kind":           string(inst.Kind),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	// Повторный запрос с тем же ключом идемпотентности возвращает уже созданный платеж
+	if existing, err := s.paymentRepo.GetByProviderID(ctx, s.provider.Name(), intent.ID); err == nil {
+		existing.ClientSecret = intent.ClientSecret
+		return existing, nil
+	}
+
+	p := &models.Payment{
+		BookingID:         booking.ID,
+		Provider:          s.provider.Name(),
+		ProviderPaymentID: intent.ID,
+		Amount:            intent.Amount,
+		Currency:          intent.Currency,
+		Status:            models.PaymentStatus(intent.Status),
+		InstallmentID:     &inst.ID,
+	}
+
+	if err := s.paymentRepo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	p.ClientSecret = intent.ClientSecret
+	return p, nil
+}
+
+func (s *paymentService) GetSchedule(ctx context.Context, bookingID int64) (*models.PaymentSchedule, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := s.ensureSchedule(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &models.PaymentSchedule{
+		BookingID:    booking.ID,
+		AmountDue:    booking.TotalPrice,
+		AmountPaid:   booking.AmountPaid,
+		Balance:      booking.Balance,
+		Installments: installments,
+	}
+	if booking.Status != models.StatusCancelled {
+		schedule.NextDue, _ = selectInstallment(installments, 0)
+	}
+
+	return schedule, nil
+}
+
+// ensureSchedule возвращает график платежей бронирования, создавая его при первом обращении
+// по настройкам депозита ресурса.
+func (s *paymentService) ensureSchedule(ctx context.Context, booking *models.Booking) ([]*models.PaymentInstallment, error) {
+	installments, err := s.paymentRepo.ListInstallments(ctx, booking.ID)
+	if err != nil || len(installments) > 0 || booking.TotalPrice <= 0 {
+		return installments, err
+	}
+
+	resource, err := s.resourceRepo.GetByID(ctx, booking.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.paymentRepo.CreateInstallments(ctx, buildSchedule(booking, resource)); err != nil {
+		return nil, err
+	}
+
+	return s.paymentRepo.ListInstallments(ctx, booking.ID)
+}
+
+func (s *paymentService) countAttempts(ctx context.Context, bookingID, installmentID int64) (int, error) {
+	payments, err := s.paymentRepo.ListByBooking(ctx, bookingID)
+	if err != nil {
+		return 0, err
+	}
+
+	attempts := 0
+	for _, p := range payments {
+		if p.InstallmentID != nil && *p.InstallmentID == installmentID &&
+			(p.Status == models.PaymentFailed || p.Status == models.PaymentCanceled || p.Status == models.PaymentRefunded) {
+			attempts++
+		}
+	}
+
+	return attempts, nil
+}
+
+// buildSchedule делит стоимость на депозит и остаток, если у ресурса задан процент депозита.
+// Депозит подлежит оплате сразу, остаток — за BalanceDueHours часов до начала.
+func buildSchedule(booking *models.Booking, resource *models.Resource) []*models.PaymentInstallment {
+	balanceDue := booking.StartTime.Add(-time.Duration(resource.BalanceDueHours) * time.Hour)
+	if balanceDue.Before(booking.CreatedAt) {
+		balanceDue = booking.CreatedAt
+	}
+
+	var deposit money.Amount
+	if resource.DepositPercent != nil {
+		deposit = booking.TotalPrice.Percent(*resource.DepositPercent)
+	}
+
+	if deposit <= 0 || deposit >= booking.TotalPrice {
+		dueAt := balanceDue
+		if resource.RequiresPrepayment {
+			dueAt = booking.CreatedAt
+		}
+		return []*models.PaymentInstallment{{
+			BookingID: booking.ID,
+			Kind:      models.InstallmentFull,
+			Amount:    booking.TotalPrice,
+			DueAt:     dueAt,
+			Status:    models.InstallmentPending,
+		}}
+	}
+
+	return []*models.PaymentInstallment{
+		{
+			BookingID: booking.ID,
+			Kind:      models.InstallmentDeposit,
+			Amount:    deposit,
+			DueAt:     booking.CreatedAt,
+			Status:    models.InstallmentPending,
+		},
+		{
+			BookingID: booking.ID,
+			Kind:      models.InstallmentBalance,
+			Amount:    booking.TotalPrice - deposit,
+			DueAt:     balanceDue,
+			Status:    models.InstallmentPending,
+		},
+	}
+}
+
+// selectInstallment выбирает позицию графика для оплаты; id == 0 — ближайшая неоплаченная
+func selectInstallment(installments []*models.PaymentInstallment, id int64) (*models.PaymentInstallment, error) {
+	for _, inst := range installments {
+		if id != 0 && inst.ID != id {
+			continue
+		}
+		if inst.Status != models.InstallmentPaid {
+			return inst, nil
+		}
+		if id != 0 {
+			return nil, ErrInstallmentPaid
+		}
+	}
+
+	if id != 0 {
+		return nil, repository.ErrInstallmentNotFound
+	}
+	return nil, ErrBookingNotPayable
+}
+
+func (s *paymentService) GetByID(ctx context.Context, id int64) (*models.Payment, error) {
+	return s.paymentRepo.GetByID(ctx, id)
+}
+
+func (s *paymentService) ListByBooking(ctx context.Context, bookingID int64) ([]*models.Payment, error) {
+	return s.paymentRepo.ListByBooking(ctx, bookingID)
+}
+
+func (s *paymentService) Capture(ctx context.Context, id int64) (*models.Payment, error) {
+	p, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Status != models.PaymentRequiresCapture {
+		return nil, ErrPaymentNotCaptured
+	}
+
+	if _, err := s.provider.CaptureIntent(ctx, p.ProviderPaymentID, p.Amount); err != nil {
+		return nil, fmt.Errorf("failed to capture payment: %w", err)
+	}
+
+	if err := s.markSucceeded(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Refund возвращает amount по платежу (0 — весь остаток). Проверка остатка,
+// возврат у провайдера и запись идут в одной транзакции под блокировкой
+// платежа: параллельный возврат ждет ее и видит уже увеличенную сумму возвратов.
+func (s *paymentService) Refund(ctx context.Context, id int64, amount money.Amount) (*models.Payment, error) {
+	var p *models.Payment
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		p, err = s.paymentRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if p.Status != models.PaymentSucceeded && p.Status != models.PaymentPartiallyRefunded {
+			return ErrPaymentNotRefundable
+		}
+
+		refundable := p.Amount - p.RefundedAmount
+		if amount == 0 {
+			amount = refundable
+		}
+		if amount <= 0 || amount > refundable {
+			return ErrPaymentNotRefundable
+		}
+
+		if _, err := s.provider.Refund(ctx, p.ProviderPaymentID, amount); err != nil {
+			return fmt.Errorf("failed to refund payment: %w", err)
+		}
+
+		err = s.paymentRepo.ApplyRefund(ctx, p, amount)
+		if errors.Is(err, repository.ErrRefundExceedsPayment) {
+			return ErrPaymentNotRefundable
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// HandleWebhook проверяет подпись вебхука и применяет смену статуса платежа.
+// Обработка идемпотентна: повторная доставка того же события ничего не меняет.
+func (s *paymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.paymentRepo.GetByProviderID(ctx, s.provider.Name(), event.IntentID)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case payment.EventPaymentAuthorized:
+		if p.Status == models.PaymentRequiresPayment {
+			p.Status = models.PaymentRequiresCapture
+			err = s.paymentRepo.Update(ctx, p)
+		}
+	case payment.EventPaymentSucceeded:
+		if p.Status == models.PaymentRequiresPayment || p.Status == models.PaymentRequiresCapture {
+			err = s.markSucceeded(ctx, p)
+		}
+	case payment.EventPaymentFailed:
+		if p.Status == models.PaymentRequiresPayment || p.Status == models.PaymentRequiresCapture {
+			p.Status = models.PaymentFailed
+			p.FailureReason = event.Reason
+			err = s.paymentRepo.Update(ctx, p)
+		}
+	default:
+		logger.Info("Payment webhook: ignoring event %s of type %s", event.ID, event.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.paymentRepo.RecordWebhookEvent(ctx, s.provider.Name(), event.ID, event.Type, payload); err != nil {
+		logger.Error("Payment webhook: failed to record event %s: %v", event.ID, err)
+	}
+
+	return nil
+}
+
+// markSucceeded зачисляет успешную оплату на бронирование. Бронирование
+// ресурса с предоплатой подтверждается после первого платежа (депозита или
+// полной оплаты), остальные подтверждены уже при создании.
+func (s *paymentService) markSucceeded(ctx context.Context, p *models.Payment) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		confirmed, err := s.paymentRepo.ApplySucceeded(ctx, p)
+		if err != nil || !confirmed {
+			return err
+		}
+
+		booking, err := s.bookingRepo.GetByID(ctx, p.BookingID)
+		if err != nil {
+			return err
+		}
+		logger.LogBookingOperation("confirm", booking.ID, booking.UserID, booking.ResourceID, nil)
+
+		return s.publisher.Publish(ctx, events.Event{Type: events.BookingConfirmed, Booking: booking})
+	})
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/models"
+	"smartbooking/internal/money"
+	"smartbooking/internal/payment"
+	"smartbooking/internal/repository"
+)
+
+// memoryPaymentRepo хранит платежи, график и одно бронирование в памяти.
+// ApplySucceeded и ApplyRefund повторяют условия SQL-версий: зачисление только
+// из ожидающего статуса и возврат не больше остатка платежа.
+type memoryPaymentRepo struct {
+	repository.PaymentRepository
+	booking            *models.Booking
+	requiresPrepayment bool
+	payments           map[int64]*models.Payment
+	installments       []*models.PaymentInstallment
+	webhookEvents      []string
+}
+
+func newMemoryPaymentRepo(booking *models.Booking, requiresPrepayment bool) *memoryPaymentRepo {
+	return &memoryPaymentRepo{
+		booking:            booking,
+		requiresPrepayment: requiresPrepayment,
+		payments:           make(map[int64]*models.Payment),
+	}
+}
+
+func (r *memoryPaymentRepo) Create(ctx context.Context, p *models.Payment) error {
+	p.ID = int64(len(r.payments) + 1)
+	stored := *p
+	r.payments[p.ID] = &stored
+	return nil
+}
+
+func (r *memoryPaymentRepo) GetByID(ctx context.Context, id int64) (*models.Payment, error) {
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, repository.ErrPaymentNotFound
+	}
+	stored := *p
+	return &stored, nil
+}
+
+func (r *memoryPaymentRepo) GetForUpdate(ctx context.Context, id int64) (*models.Payment, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryPaymentRepo) GetByProviderID(ctx context.Context, provider, providerPaymentID string) (*models.Payment, error) {
+	for _, p := range r.payments {
+		if p.Provider == provider && p.ProviderPaymentID == providerPaymentID {
+			return r.GetByID(ctx, p.ID)
+		}
+	}
+	return nil, repository.ErrPaymentNotFound
+}
+
+func (r *memoryPaymentRepo) ListByBooking(ctx context.Context, bookingID int64) ([]*models.Payment, error) {
+	var result []*models.Payment
+	for id := range r.payments {
+		p, _ := r.GetByID(ctx, id)
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepo) Update(ctx context.Context, p *models.Payment) error {
+	stored := *p
+	r.payments[p.ID] = &stored
+	return nil
+}
+
+func (r *memoryPaymentRepo) RecordWebhookEvent(ctx context.Context, provider, eventID, eventType string, payload []byte) (bool, error) {
+	r.webhookEvents = append(r.webhookEvents, eventID)
+	return true, nil
+}
+
+func (r *memoryPaymentRepo) CreateInstallments(ctx context.Context, installments []*models.PaymentInstallment) error {
+	for _, inst := range installments {
+		stored := *inst
+		stored.ID = int64(len(r.installments) + 1)
+		r.installments = append(r.installments, &stored)
+	}
+	return nil
+}
+
+func (r *memoryPaymentRepo) ListInstallments(ctx context.Context, bookingID int64) ([]*models.PaymentInstallment, error) {
+	return r.installments, nil
+}
+
+func (r *memoryPaymentRepo) ApplySucceeded(ctx context.Context, p *models.Payment) (bool, error) {
+	stored := r.payments[p.ID]
+	if stored.Status != models.PaymentRequiresPayment && stored.Status != models.PaymentRequiresCapture {
+		return false, nil
+	}
+	stored.Status = models.PaymentSucceeded
+	p.Status = models.PaymentSucceeded
+
+	r.booking.AmountPaid += p.Amount
+	r.booking.Balance -= p.Amount
+	confirm := r.requiresPrepayment && r.booking.Status == models.StatusPending
+	if confirm {
+		r.booking.Status = models.StatusConfirmed
+	}
+	return confirm, nil
+}
+
+func (r *memoryPaymentRepo) ApplyRefund(ctx context.Context, p *models.Payment, amount money.Amount) error {
+	stored := r.payments[p.ID]
+	if stored.RefundedAmount+amount > stored.Amount {
+		return repository.ErrRefundExceedsPayment
+	}
+	stored.RefundedAmount += amount
+	stored.Status = models.PaymentPartiallyRefunded
+	if stored.RefundedAmount >= stored.Amount {
+		stored.Status = models.PaymentRefunded
+	}
+	p.RefundedAmount, p.Status = stored.RefundedAmount, stored.Status
+
+	r.booking.AmountPaid -= amount
+	r.booking.Balance += amount
+	return nil
+}
+
+type paymentBookingRepo struct {
+	repository.BookingRepository
+	booking *models.Booking
+}
+
+func (r *paymentBookingRepo) GetByID(ctx context.Context, id int64) (*models.Booking, error) {
+	b := *r.booking
+	return &b, nil
+}
+
+type paymentResourceRepo struct {
+	repository.ResourceRepository
+	requiresPrepayment bool
+}
+
+func (r *paymentResourceRepo) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
+	return &models.Resource{ID: id, RequiresPrepayment: r.requiresPrepayment}, nil
+}
+
+// lockingTransactor выполняет транзакции по одной, как блокировка строки
+// платежа в GetForUpdate
+type lockingTransactor struct {
+	mu sync.Mutex
+}
+
+func (t *lockingTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(ctx)
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+type paymentFixture struct {
+	svc       PaymentService
+	provider  *payment.FakeProvider
+	repo      *memoryPaymentRepo
+	booking   *models.Booking
+	publisher *recordingPublisher
+}
+
+func newPaymentFixture(status models.BookingStatus, requiresPrepayment bool) *paymentFixture {
+	start := time.Now().Add(72 * time.Hour)
+	booking := &models.Booking{
+		ID: 1, UserID: 2, ResourceID: 3,
+		StartTime: start, EndTime: start.Add(2 * time.Hour),
+		Status: status, TotalPrice: money.FromMinor(10_000), Balance: money.FromMinor(10_000),
+		Currency: "KZT", CreatedAt: time.Now(),
+	}
+	repo := newMemoryPaymentRepo(booking, requiresPrepayment)
+	provider := payment.NewFakeProvider("whsec_test")
+	publisher := &recordingPublisher{}
+	svc := NewPaymentService(repo, &paymentBookingRepo{booking: booking}, &paymentResourceRepo{requiresPrepayment: requiresPrepayment}, provider, &lockingTransactor{}, publisher)
+	return &paymentFixture{svc: svc, provider: provider, repo: repo, booking: booking, publisher: publisher}
+}
+
+// pay создает платеж по бронированию и доставляет вебхук об успешной оплате
+func (f *paymentFixture) pay(t *testing.T) (*models.Payment, []byte, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	p, err := f.svc.CreateForBooking(ctx, f.booking.ID, 0)
+	if err != nil {
+		t.Fatalf("CreateForBooking: %v", err)
+	}
+	if p.ClientSecret == "" || p.Status != models.PaymentRequiresPayment {
+		t.Fatalf("payment = %+v, want a pending intent with a client secret", p)
+	}
+
+	payload, signature, err := f.provider.Simulate(p.ProviderPaymentID, true)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if err := f.svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	return p, payload, signature
+}
+
+func TestPaymentWebhookConfirmsBooking(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             models.BookingStatus
+		requiresPrepayment bool
+		wantConfirmedEvent bool
+	}{
+		{"prepayment confirms pending booking", models.StatusPending, true, true},
+		{"confirmed booking stays confirmed", models.StatusConfirmed, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPaymentFixture(tt.status, tt.requiresPrepayment)
+			p, payload, signature := f.pay(t)
+
+			if f.booking.Status != models.StatusConfirmed {
+				t.Errorf("booking status = %s, want confirmed", f.booking.Status)
+			}
+			if f.booking.AmountPaid != p.Amount || f.booking.Balance != 0 {
+				t.Errorf("amount paid %s, balance %s; want %s paid", f.booking.AmountPaid, f.booking.Balance, p.Amount)
+			}
+			if got := len(f.publisher.events) == 1 && f.publisher.events[0].Type == events.BookingConfirmed; got != tt.wantConfirmedEvent {
+				t.Errorf("published %+v, want BookingConfirmed: %v", f.publisher.events, tt.wantConfirmedEvent)
+			}
+
+			// Повторная доставка того же вебхука не зачисляет оплату второй раз
+			if err := f.svc.HandleWebhook(context.Background(), payload, signature); err != nil {
+				t.Fatalf("replayed HandleWebhook: %v", err)
+			}
+			if f.booking.AmountPaid != p.Amount {
+				t.Errorf("after replay amount paid = %s, want %s", f.booking.AmountPaid, p.Amount)
+			}
+			if tt.wantConfirmedEvent && len(f.publisher.events) != 1 {
+				t.Errorf("after replay published %d events, want 1", len(f.publisher.events))
+			}
+
+			// Capture после зачисления вебхуком отклоняется
+			if _, err := f.svc.Capture(context.Background(), p.ID); !errors.Is(err, ErrPaymentNotCaptured) {
+				t.Errorf("Capture after webhook = %v, want ErrPaymentNotCaptured", err)
+			}
+		})
+	}
+}
+
+func TestPaymentWebhookRejectsBadSignature(t *testing.T) {
+	f := newPaymentFixture(models.StatusPending, true)
+	p, err := f.svc.CreateForBooking(context.Background(), f.booking.ID, 0)
+	if err != nil {
+		t.Fatalf("CreateForBooking: %v", err)
+	}
+	payload, _, _ := f.provider.Simulate(p.ProviderPaymentID, true)
+
+	err = f.svc.HandleWebhook(context.Background(), payload, payment.SignPayload("wrong", payload, time.Now()))
+	if !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("HandleWebhook = %v, want ErrInvalidSignature", err)
+	}
+	if f.booking.Status != models.StatusPending || f.booking.AmountPaid != 0 {
+		t.Errorf("booking changed by an unsigned webhook: %+v", f.booking)
+	}
+}
+
+func TestConcurrentRefundsDoNotExceedPayment(t *testing.T) {
+	f := newPaymentFixture(models.StatusPending, true)
+	p, _, _ := f.pay(t)
+
+	refund := money.FromMinor(6_000)
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.svc.Refund(context.Background(), p.ID, refund)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded, rejected int
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrPaymentNotRefundable):
+			rejected++
+		default:
+			t.Errorf("Refund: %v", err)
+		}
+	}
+	if succeeded != 1 || rejected != 1 {
+		t.Fatalf("%d refunds succeeded and %d were rejected, want 1 and 1", succeeded, rejected)
+	}
+
+	stored, _ := f.repo.GetByID(context.Background(), p.ID)
+	if stored.RefundedAmount != refund || stored.Status != models.PaymentPartiallyRefunded {
+		t.Errorf("payment refunded %s (%s), want %s partially refunded", stored.RefundedAmount, stored.Status, refund)
+	}
+	if f.booking.AmountPaid != p.Amount-refund {
+		t.Errorf("booking amount paid = %s, want %s", f.booking.AmountPaid, p.Amount-refund)
+	}
+
+	// Провайдер вернул ровно одну сумму: остаток еще можно вернуть
+	if _, err := f.svc.Refund(context.Background(), p.ID, 0); err != nil {
+		t.Errorf("refund of the remainder: %v", err)
+	}
+}
@@ -1,79 +1,404 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"path"
+	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"smartbooking/internal/audit"
+	"smartbooking/internal/imaging"
+	"smartbooking/internal/logger"
 	"smartbooking/internal/models"
 	"smartbooking/internal/repository"
 	"smartbooking/internal/storage"
 )
 
+// photoUploadURLTTL срок действия ссылки для прямой загрузки
+const photoUploadURLTTL = 15 * time.Minute
+
+// maxPhotoDetailsLength предел подписи и альтернативного текста, как в БД
+const maxPhotoDetailsLength = 500
+
+var (
+	ErrDirectUploadUnsupported = errors.New("direct uploads are not supported by this storage")
+	ErrInvalidUploadKey        = errors.New("storage key was not issued for this resource")
+	ErrNoPhotos                = errors.New("no photos to upload")
+	ErrTooManyPhotos           = errors.New("resource photo limit reached")
+	ErrPhotoOrderMismatch      = errors.New("photo order must list every photo of the resource exactly once")
+	ErrPhotoDetailsTooLong     = errors.New("caption and alt text must be at most 500 characters")
+)
+
 // PhotoService интерфейс для работы с фотографиями
 type PhotoService interface {
-	UploadPhoto(ctx context.Context, resourceID int64, file io.Reader, fileName string, isPrimary bool) (*models.ResourcePhoto, error)
+	UploadPhotos(ctx context.Context, req models.PhotoUploadRequest, files []PhotoFile) ([]*models.ResourcePhoto, error)
+	CreateUploadURL(ctx context.Context, resourceID int64, contentType string) (*models.PhotoUploadURL, error)
+	ConfirmUpload(ctx context.Context, req models.PhotoConfirmRequest) (*models.ResourcePhoto, error)
 	GetResourcePhotos(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error)
+	UpdatePhotoDetails(ctx context.Context, id int64, details models.PhotoDetails) (*models.ResourcePhoto, error)
+	SetPhotoOrder(ctx context.Context, resourceID int64, photoIDs []int64) ([]*models.ResourcePhoto, error)
 	DeletePhoto(ctx context.Context, id int64) error
 	SetPrimaryPhoto(ctx context.Context, id int64, resourceID int64) error
 }
 
+// PhotoFile загружаемый файл с подписью
+type PhotoFile struct {
+	Reader   io.Reader
+	FileName string
+	models.PhotoDetails
+}
+
 type photoService struct {
 	photoRepo repository.PhotoRepository
 	storage   storage.StorageService
+	tx        repository.Transactor
+	auditor   audit.Recorder
+	maxPhotos int
 }
 
-func NewPhotoService(photoRepo repository.PhotoRepository, storage storage.StorageService) PhotoService {
+func NewPhotoService(photoRepo repository.PhotoRepository, storage storage.StorageService, tx repository.Transactor, auditor audit.Recorder, maxPhotos int) PhotoService {
 	return &photoService{
 		photoRepo: photoRepo,
 		storage:   storage,
+		tx:        tx,
+		auditor:   auditor,
+		maxPhotos: maxPhotos,
+	}
+}
+
+// UploadPhotos проверяет, что файлы действительно изображения, снимает с них
+// метаданные и сохраняет оригиналы вместе с миниатюрой и средней копией.
+// Загружаются все файлы или ни одного.
+func (s *photoService) UploadPhotos(ctx context.Context, req models.PhotoUploadRequest, files []PhotoFile) ([]*models.ResourcePhoto, error) {
+	if len(files) == 0 {
+		return nil, ErrNoPhotos
+	}
+	for i := range files {
+		details, err := normalizePhotoDetails(files[i].PhotoDetails)
+		if err != nil {
+			return nil, err
+		}
+		files[i].PhotoDetails = details
+	}
+	if err := s.checkPhotoLimit(ctx, req.ResourceID, len(files)); err != nil {
+		return nil, err
+	}
+
+	// Файлы обрабатываются по одному, чтобы в памяти был только один
+	// декодированный снимок
+	photos := make([]*models.ResourcePhoto, 0, len(files))
+	var uploaded []string
+	for _, f := range files {
+		src, err := decodePhoto(f.Reader)
+		if err != nil {
+			s.deleteFiles(ctx, uploaded)
+			return nil, fmt.Errorf("%s: %w", f.FileName, err)
+		}
+		photo, keys, err := s.uploadPhotoFiles(ctx, req.ResourceID, src, f.FileName)
+		uploaded = append(uploaded, keys...)
+		if err != nil {
+			s.deleteFiles(ctx, uploaded)
+			return nil, err
+		}
+		photo.PhotoDetails = f.PhotoDetails
+		photos = append(photos, photo)
+	}
+
+	if err := s.savePhotos(ctx, req, photos); err != nil {
+		s.deleteFiles(ctx, uploaded)
+		return nil, err
 	}
+	return photos, nil
 }
 
-func (s *photoService) UploadPhoto(ctx context.Context, resourceID int64, file io.Reader, fileName string, isPrimary bool) (*models.ResourcePhoto, error) {
-	// Определяем MIME type по расширению
-	contentType := getContentType(fileName)
-	if !isImageMimeType(contentType) {
-		return nil, fmt.Errorf("неподдерживаемый тип файла: %s", contentType)
+// CreateUploadURL выдает подписанную ссылку, по которой клиент загружает фото
+// прямо в хранилище. Файл попадает во временный каталог ресурса и становится
+// фотографией только после ConfirmUpload.
+func (s *photoService) CreateUploadURL(ctx context.Context, resourceID int64, contentType string) (*models.PhotoUploadURL, error) {
+	uploader, ok := s.storage.(storage.DirectUploader)
+	if !ok {
+		return nil, ErrDirectUploadUnsupported
+	}
+	if !imaging.Supported(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPhotoType, contentType)
+	}
+	if err := s.checkPhotoLimit(ctx, resourceID, 1); err != nil {
+		return nil, err
+	}
+
+	storageKey := storage.NewIncomingKey(photoUploadScope(resourceID), imaging.Ext(contentType))
+	url, err := uploader.PresignUpload(storageKey, contentType, photoUploadURLTTL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Загружаем в storage
-	result, err := s.storage.UploadFile(ctx, file, fileName, contentType)
+	return &models.PhotoUploadURL{
+		URL:        url,
+		Method:     http.MethodPut,
+		Headers:    map[string]string{"Content-Type": contentType},
+		StorageKey: storageKey,
+		ExpiresAt:  time.Now().Add(photoUploadURLTTL),
+	}, nil
+}
+
+// ConfirmUpload регистрирует фото, загруженное по ссылке из CreateUploadURL.
+// Загруженный файл проходит ту же обработку, что и при UploadPhotos, а сам
+// удаляется: в хранилище остаются только очищенный оригинал и копии.
+func (s *photoService) ConfirmUpload(ctx context.Context, req models.PhotoConfirmRequest) (*models.ResourcePhoto, error) {
+	uploader, ok := s.storage.(storage.DirectUploader)
+	if !ok {
+		return nil, ErrDirectUploadUnsupported
+	}
+	// Ключ должен быть выдан для этого ресурса, иначе можно было бы
+	// зарегистрировать чужой файл
+	storageKey := req.StorageKey
+	if !strings.HasPrefix(storageKey, storage.IncomingPrefix(photoUploadScope(req.ResourceID))) || strings.Contains(storageKey, "..") {
+		return nil, ErrInvalidUploadKey
+	}
+	details, err := normalizePhotoDetails(req.PhotoDetails)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка загрузки файла: %w", err)
+		return nil, err
+	}
+	if err := s.checkPhotoLimit(ctx, req.ResourceID, 1); err != nil {
+		return nil, err
 	}
 
-	// Создаем запись в БД
-	photo := &models.ResourcePhoto{
-		ResourceID: resourceID,
-		URL:        result.URL,
-		StorageKey: result.StorageKey,
-		FileName:   result.FileName,
-		FileSize:   result.FileSize,
-		MimeType:   contentType,
-		IsPrimary:  isPrimary,
+	body, err := uploader.OpenFile(ctx, storageKey)
+	if errors.Is(err, storage.ErrFileTooLarge) {
+		_ = s.storage.DeleteFile(ctx, storageKey)
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedPhotoType, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	src, err := decodePhoto(body)
+	body.Close()
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedPhotoType) {
+			_ = s.storage.DeleteFile(ctx, storageKey)
+		}
+		return nil, err
 	}
 
-	err = s.photoRepo.Create(ctx, photo)
+	fileName := req.FileName
+	if fileName == "" {
+		fileName = path.Base(storageKey)
+	}
+	// При ошибке загруженный файл оставляем, чтобы подтверждение можно было повторить
+	photo, keys, err := s.uploadPhotoFiles(ctx, req.ResourceID, src, fileName)
 	if err != nil {
-		// Если не удалось создать запись в БД, удаляем файл из storage
-		_ = s.storage.DeleteFile(ctx, result.StorageKey)
-		return nil, fmt.Errorf("ошибка сохранения в БД: %w", err)
+		s.deleteFiles(ctx, keys)
+		return nil, err
 	}
+	photo.PhotoDetails = details
 
-	// Если это главное фото, обновляем остальные
-	if isPrimary {
-		_ = s.photoRepo.SetPrimary(ctx, photo.ID, resourceID)
+	upload := models.PhotoUploadRequest{ResourceID: req.ResourceID, IsPrimary: req.IsPrimary, DisplayOrder: req.DisplayOrder}
+	if err := s.savePhotos(ctx, upload, []*models.ResourcePhoto{photo}); err != nil {
+		s.deleteFiles(ctx, keys)
+		return nil, err
 	}
 
+	if err := s.storage.DeleteFile(ctx, storageKey); err != nil {
+		logger.Error("Failed to delete incoming upload %s: %v", storageKey, err)
+	}
 	return photo, nil
 }
 
+// uploadPhotoFiles загружает в storage оригинал без метаданных, миниатюру и
+// среднюю копию. Возвращает фото без записи в БД и ключи загруженных файлов;
+// при ошибке ключи тоже возвращаются, чтобы вызывающий мог их удалить.
+func (s *photoService) uploadPhotoFiles(ctx context.Context, resourceID int64, src *imaging.Source, fileName string) (*models.ResourcePhoto, []string, error) {
+	original, err := src.Original()
+	if err != nil {
+		return nil, nil, err
+	}
+	thumbnail, err := src.Fit(imaging.ThumbnailSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	medium, err := src.Fit(imaging.MediumSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var uploaded []string
+	upload := func(img *imaging.Image, suffix string) (*storage.UploadResult, error) {
+		result, err := s.storage.UploadFile(ctx, bytes.NewReader(img.Data), photoFileName(fileName, suffix, img), img.ContentType)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки файла: %w", err)
+		}
+		uploaded = append(uploaded, result.StorageKey)
+		return result, nil
+	}
+
+	result, err := upload(original, "")
+	if err != nil {
+		return nil, uploaded, err
+	}
+	thumbResult, err := upload(thumbnail, "_thumb")
+	if err != nil {
+		return nil, uploaded, err
+	}
+	mediumResult, err := upload(medium, "_medium")
+	if err != nil {
+		return nil, uploaded, err
+	}
+
+	return &models.ResourcePhoto{
+		ResourceID:   resourceID,
+		URL:          result.URL,
+		StorageKey:   result.StorageKey,
+		ThumbnailURL: thumbResult.URL,
+		ThumbnailKey: thumbResult.StorageKey,
+		MediumURL:    mediumResult.URL,
+		MediumKey:    mediumResult.StorageKey,
+		FileName:     result.FileName,
+		FileSize:     int64(len(original.Data)),
+		MimeType:     original.ContentType,
+		Width:        original.Width,
+		Height:       original.Height,
+	}, uploaded, nil
+}
+
+// savePhotos создает записи о загруженных фото одной транзакцией: проверяет
+// лимит галереи, вставляет фото на позицию req.DisplayOrder (по умолчанию в
+// конец) и сдвигает остальные
+func (s *photoService) savePhotos(ctx context.Context, req models.PhotoUploadRequest, photos []*models.ResourcePhoto) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.photoRepo.ListForUpdate(ctx, req.ResourceID)
+		if err != nil {
+			return err
+		}
+		if len(existing)+len(photos) > s.maxPhotos {
+			return ErrTooManyPhotos
+		}
+
+		pos := len(existing)
+		if req.DisplayOrder != nil {
+			pos = min(max(*req.DisplayOrder, 0), len(existing))
+		}
+
+		for i, photo := range photos {
+			photo.DisplayOrder = pos + i
+			photo.IsPrimary = req.IsPrimary && i == 0
+			if err := s.photoRepo.Create(ctx, photo); err != nil {
+				return err
+			}
+			if err := s.auditor.Record(ctx, audit.ActionCreate, audit.EntityPhoto, photo.ID, nil, photo); err != nil {
+				return err
+			}
+		}
+		// После удалений в нумерации бывают пропуски, поэтому нумеруется вся галерея
+		if err := s.applyOrder(ctx, existing[:pos], 0); err != nil {
+			return err
+		}
+		if err := s.applyOrder(ctx, existing[pos:], pos+len(photos)); err != nil {
+			return err
+		}
+
+		// Главное фото у ресурса одно: снимаем признак с остальных
+		if req.IsPrimary {
+			return s.photoRepo.SetPrimary(ctx, photos[0].ID, req.ResourceID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения в БД: %w", err)
+	}
+	return nil
+}
+
 func (s *photoService) GetResourcePhotos(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error) {
 	return s.photoRepo.GetByResourceID(ctx, resourceID)
 }
 
+// UpdatePhotoDetails меняет подпись и альтернативный текст фото
+func (s *photoService) UpdatePhotoDetails(ctx context.Context, id int64, details models.PhotoDetails) (*models.ResourcePhoto, error) {
+	details, err := normalizePhotoDetails(details)
+	if err != nil {
+		return nil, err
+	}
+
+	var after models.ResourcePhoto
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.photoRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.photoRepo.UpdateDetails(ctx, id, details); err != nil {
+			return err
+		}
+		after = *before
+		after.PhotoDetails = details
+		return s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityPhoto, id, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// SetPhotoOrder задает порядок галереи. photoIDs должен содержать каждое фото
+// ресурса ровно один раз, иначе порядок не меняется.
+func (s *photoService) SetPhotoOrder(ctx context.Context, resourceID int64, photoIDs []int64) ([]*models.ResourcePhoto, error) {
+	var gallery []*models.ResourcePhoto
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.photoRepo.ListForUpdate(ctx, resourceID)
+		if err != nil {
+			return err
+		}
+		if len(photoIDs) != len(existing) {
+			return ErrPhotoOrderMismatch
+		}
+
+		byID := make(map[int64]*models.ResourcePhoto, len(existing))
+		for _, photo := range existing {
+			byID[photo.ID] = photo
+		}
+		gallery = make([]*models.ResourcePhoto, 0, len(photoIDs))
+		for _, id := range photoIDs {
+			photo, ok := byID[id]
+			if !ok {
+				return ErrPhotoOrderMismatch
+			}
+			delete(byID, id)
+			gallery = append(gallery, photo)
+		}
+
+		return s.applyOrder(ctx, gallery, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gallery, nil
+}
+
+// applyOrder нумерует фото подряд начиная с from и сохраняет изменившиеся позиции
+func (s *photoService) applyOrder(ctx context.Context, photos []*models.ResourcePhoto, from int) error {
+	for i, photo := range photos {
+		order := from + i
+		if photo.DisplayOrder == order {
+			continue
+		}
+		if err := s.photoRepo.UpdateOrder(ctx, photo.ID, order); err != nil {
+			return err
+		}
+		before := *photo
+		photo.DisplayOrder = order
+		if err := s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityPhoto, photo.ID, &before, photo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *photoService) DeletePhoto(ctx context.Context, id int64) error {
 	// Получаем фото чтобы удалить из storage
 	photo, err := s.photoRepo.GetByID(ctx, id)
@@ -81,14 +406,23 @@ func (s *photoService) DeletePhoto(ctx context.Context, id int64) error {
 		return err
 	}
 
-	// Удаляем из storage
-	err = s.storage.DeleteFile(ctx, photo.StorageKey)
-	if err != nil {
-		return fmt.Errorf("ошибка удаления из storage: %w", err)
+	// Удаляем из storage оригинал и копии
+	for _, key := range []string{photo.StorageKey, photo.ThumbnailKey, photo.MediumKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.DeleteFile(ctx, key); err != nil {
+			return fmt.Errorf("ошибка удаления из storage: %w", err)
+		}
 	}
 
 	// Удаляем из БД
-	err = s.photoRepo.Delete(ctx, id)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.photoRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionDelete, audit.EntityPhoto, id, photo, nil)
+	})
 	if err != nil {
 		return fmt.Errorf("ошибка удаления из БД: %w", err)
 	}
@@ -97,27 +431,77 @@ func (s *photoService) DeletePhoto(ctx context.Context, id int64) error {
 }
 
 func (s *photoService) SetPrimaryPhoto(ctx context.Context, id int64, resourceID int64) error {
-	return s.photoRepo.SetPrimary(ctx, id, resourceID)
+	before, err := s.photoRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.photoRepo.SetPrimary(ctx, id, resourceID); err != nil {
+			return err
+		}
+		after := *before
+		after.IsPrimary = true
+		return s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityPhoto, id, before, &after)
+	})
 }
 
 // Вспомогательные функции
 
-func getContentType(fileName string) string {
-	ext := strings.ToLower(fileName[strings.LastIndex(fileName, ".")+1:])
-	switch ext {
-	case "jpg", "jpeg":
-		return "image/jpeg"
-	case "png":
-		return "image/png"
-	case "gif":
-		return "image/gif"
-	case "webp":
-		return "image/webp"
-	default:
-		return "application/octet-stream"
+// decodePhoto читает загруженный файл как изображение. Тип определяется по
+// содержимому, имя файла не учитывается.
+func decodePhoto(file io.Reader) (*imaging.Source, error) {
+	src, err := imaging.Decode(file)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrFileTooLarge) || errors.Is(err, imaging.ErrTooManyPixels) {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedPhotoType, err)
 	}
+	return src, err
 }
 
-func isImageMimeType(mimeType string) bool {
-	return strings.HasPrefix(mimeType, "image/")
+// checkPhotoLimit заранее отклоняет загрузку, которая превысит лимит
+// галереи, чтобы не обрабатывать файлы зря. Окончательная проверка — в
+// транзакции savePhotos.
+func (s *photoService) checkPhotoLimit(ctx context.Context, resourceID int64, adding int) error {
+	photos, err := s.photoRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	if len(photos)+adding > s.maxPhotos {
+		return ErrTooManyPhotos
+	}
+	return nil
+}
+
+// deleteFiles удаляет из storage файлы, для которых не появилось записи в БД
+func (s *photoService) deleteFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.DeleteFile(ctx, key); err != nil {
+			logger.Error("Failed to delete orphaned photo file %s: %v", key, err)
+		}
+	}
+}
+
+// normalizePhotoDetails обрезает пробелы и проверяет длину подписи
+func normalizePhotoDetails(details models.PhotoDetails) (models.PhotoDetails, error) {
+	details.Caption = strings.TrimSpace(details.Caption)
+	details.AltText = strings.TrimSpace(details.AltText)
+	if utf8.RuneCountInString(details.Caption) > maxPhotoDetailsLength || utf8.RuneCountInString(details.AltText) > maxPhotoDetailsLength {
+		return details, ErrPhotoDetailsTooLong
+	}
+	return details, nil
+}
+
+// photoUploadScope подкаталог прямых загрузок ресурса
+func photoUploadScope(resourceID int64) string {
+	return fmt.Sprintf("resources/%d", resourceID)
+}
+
+// photoFileName строит имя файла в storage: исходное имя без расширения,
+// суффикс копии и расширение по настоящему типу изображения
+func photoFileName(fileName, suffix string, img *imaging.Image) string {
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	if base == "" || base == "." || base == "/" {
+		base = "photo"
+	}
+	return base + suffix + img.Ext()
 }
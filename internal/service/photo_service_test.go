@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/storage"
+)
+
+// memoryDirectStorage хранилище с прямой загрузкой: клиент "загружает" файл,
+// записывая его по ключу из подписанной ссылки
+type memoryDirectStorage struct {
+	files map[string][]byte
+}
+
+func (m *memoryDirectStorage) UploadFile(ctx context.Context, file io.Reader, fileName string, contentType string) (*storage.UploadResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("resources/%d-%s", len(m.files), fileName)
+	m.files[key] = data
+	return &storage.UploadResult{URL: m.GetFileURL(key), StorageKey: key, FileName: fileName, FileSize: int64(len(data))}, nil
+}
+
+func (m *memoryDirectStorage) DeleteFile(ctx context.Context, storageKey string) error {
+	delete(m.files, storageKey)
+	return nil
+}
+
+func (m *memoryDirectStorage) GetFileURL(storageKey string) string {
+	return "http://cdn.example.com/" + storageKey
+}
+
+func (m *memoryDirectStorage) GetSignedURL(storageKey string, expiration time.Duration) (string, error) {
+	return m.GetFileURL(storageKey) + "?signed", nil
+}
+
+func (m *memoryDirectStorage) PresignUpload(storageKey, contentType string, expiration time.Duration) (string, error) {
+	return "http://s3.example.com/" + storageKey + "?X-Amz-Signature=test", nil
+}
+
+func (m *memoryDirectStorage) OpenFile(ctx context.Context, storageKey string) (io.ReadCloser, error) {
+	data, ok := m.files[storageKey]
+	if !ok {
+		return nil, storage.ErrFileNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+type memoryPhotoRepo struct {
+	repository.PhotoRepository
+	photos []*models.ResourcePhoto
+}
+
+func (r *memoryPhotoRepo) GetByResourceID(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error) {
+	return r.photos, nil
+}
+
+func (r *memoryPhotoRepo) ListForUpdate(ctx context.Context, resourceID int64) ([]*models.ResourcePhoto, error) {
+	return r.photos, nil
+}
+
+func (r *memoryPhotoRepo) Create(ctx context.Context, photo *models.ResourcePhoto) error {
+	photo.ID = int64(len(r.photos) + 1)
+	r.photos = append(r.photos, photo)
+	return nil
+}
+
+func (r *memoryPhotoRepo) SetPrimary(ctx context.Context, id int64, resourceID int64) error {
+	for _, photo := range r.photos {
+		photo.IsPrimary = photo.ID == id
+	}
+	return nil
+}
+
+type nopAuditor struct{}
+
+func (nopAuditor) Record(ctx context.Context, action, entityType string, entityID int64, before, after any) error {
+	return nil
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := range 40 {
+		img.Set(x, x%30, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestConfirmDirectUpload(t *testing.T) {
+	files := &memoryDirectStorage{files: make(map[string][]byte)}
+	repo := &memoryPhotoRepo{}
+	svc := NewPhotoService(repo, files, inlineTransactor{}, nopAuditor{}, 10)
+	ctx := context.Background()
+
+	upload, err := svc.CreateUploadURL(ctx, 1, "image/png")
+	if err != nil {
+		t.Fatalf("CreateUploadURL: %v", err)
+	}
+	if !strings.HasPrefix(upload.StorageKey, storage.IncomingPrefix("resources/1")) || upload.Headers["Content-Type"] != "image/png" {
+		t.Fatalf("upload url = %+v", upload)
+	}
+
+	// Подтверждение до загрузки: файла в хранилище нет, фото не создается
+	_, err = svc.ConfirmUpload(ctx, models.PhotoConfirmRequest{ResourceID: 1, StorageKey: upload.StorageKey})
+	if !errors.Is(err, storage.ErrFileNotFound) {
+		t.Fatalf("ConfirmUpload before upload = %v, want ErrFileNotFound", err)
+	}
+	if len(repo.photos) != 0 || len(files.files) != 0 {
+		t.Fatalf("confirming a missing upload left %d photos and %d files", len(repo.photos), len(files.files))
+	}
+
+	// Ключ другого ресурса не принимается
+	_, err = svc.ConfirmUpload(ctx, models.PhotoConfirmRequest{ResourceID: 2, StorageKey: upload.StorageKey})
+	if !errors.Is(err, ErrInvalidUploadKey) {
+		t.Errorf("ConfirmUpload for another resource = %v, want ErrInvalidUploadKey", err)
+	}
+
+	files.files[upload.StorageKey] = testPNG(t)
+	photo, err := svc.ConfirmUpload(ctx, models.PhotoConfirmRequest{
+		ResourceID:   1,
+		StorageKey:   upload.StorageKey,
+		FileName:     "view.png",
+		IsPrimary:    true,
+		PhotoDetails: models.PhotoDetails{Caption: "  Sea view "},
+	})
+	if err != nil {
+		t.Fatalf("ConfirmUpload: %v", err)
+	}
+	if photo.ID == 0 || photo.Width != 40 || photo.Height != 30 || photo.Caption != "Sea view" || !photo.IsPrimary {
+		t.Errorf("photo = %+v", photo)
+	}
+	if _, ok := files.files[upload.StorageKey]; ok {
+		t.Error("incoming upload was not deleted after confirmation")
+	}
+	for _, key := range []string{photo.StorageKey, photo.ThumbnailKey, photo.MediumKey} {
+		if _, ok := files.files[key]; !ok {
+			t.Errorf("rendition %q is missing from storage", key)
+		}
+	}
+
+	// Повторное подтверждение того же ключа: файла уже нет
+	_, err = svc.ConfirmUpload(ctx, models.PhotoConfirmRequest{ResourceID: 1, StorageKey: upload.StorageKey})
+	if !errors.Is(err, storage.ErrFileNotFound) || len(repo.photos) != 1 {
+		t.Errorf("second ConfirmUpload = %v with %d photos, want ErrFileNotFound and 1 photo", err, len(repo.photos))
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/repository"
+)
+
+const reminderBatchSize = 100
+
+// ReminderService sends booking reminders at configured offsets before the start time
+type ReminderService interface {
+	SendDue(ctx context.Context) (int, error)
+}
+
+type reminderService struct {
+	reminderRepo repository.ReminderRepository
+	bookingRepo  repository.BookingRepository
+	tx           repository.Transactor
+	publisher    events.Publisher
+	offsets      []time.Duration
+}
+
+// NewReminderService creates a new ReminderService instance. offsets — за сколько
+// до начала бронирования напоминать, например 24h и 2h.
+func NewReminderService(reminderRepo repository.ReminderRepository, bookingRepo repository.BookingRepository, tx repository.Transactor, publisher events.Publisher, offsets []time.Duration) ReminderService {
+	return &reminderService{
+		reminderRepo: reminderRepo,
+		bookingRepo:  bookingRepo,
+		tx:           tx,
+		publisher:    publisher,
+		offsets:      offsets,
+	}
+}
+
+// SendDue публикует BookingReminder для всех наступивших напоминаний и возвращает их число.
+// Отметка о напоминании и событие в outbox записываются в одной транзакции,
+// поэтому напоминание не теряется и не дублируется при перезапусках и
+// при нескольких экземплярах приложения. Доставку по каналам (уведомления,
+// email, вебхуки) выполняют подписчики события.
+func (s *reminderService) SendDue(ctx context.Context) (int, error) {
+	sent := 0
+	for _, offset := range s.offsets {
+		for {
+			var claimed int
+			err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+				ids, err := s.reminderRepo.ClaimDue(ctx, offset, reminderBatchSize)
+				if err != nil {
+					return err
+				}
+				for _, id := range ids {
+					booking, err := s.bookingRepo.GetByID(ctx, id)
+					if err != nil {
+						return err
+					}
+					if err := s.publisher.Publish(ctx, events.Event{Type: events.BookingReminder, Booking: booking}); err != nil {
+						return err
+					}
+				}
+				claimed = len(ids)
+				return nil
+			})
+			if err != nil {
+				return sent, err
+			}
+
+			sent += claimed
+			if claimed < reminderBatchSize {
+				break
+			}
+		}
+	}
+
+	return sent, nil
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
 
+	"smartbooking/internal/audit"
+	"smartbooking/internal/events"
 	"smartbooking/internal/models"
 	"smartbooking/internal/repository"
 )
@@ -18,17 +21,35 @@ type ResourceService interface {
 
 type resourceService struct {
 	resourceRepo repository.ResourceRepository
+	tx           repository.Transactor
+	publisher    events.Publisher
+	auditor      audit.Recorder
 }
 
 // NewResourceService creates a new ResourceService instance
-func NewResourceService(resourceRepo repository.ResourceRepository) ResourceService {
+func NewResourceService(resourceRepo repository.ResourceRepository, tx repository.Transactor, publisher events.Publisher, auditor audit.Recorder) ResourceService {
 	return &resourceService{
 		resourceRepo: resourceRepo,
+		tx:           tx,
+		publisher:    publisher,
+		auditor:      auditor,
 	}
 }
 
 func (s *resourceService) Create(ctx context.Context, resource *models.Resource) error {
-	return s.resourceRepo.Create(ctx, resource)
+	if err := normalizeResourceTimezone(resource); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.resourceRepo.Create(ctx, resource); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionCreate, audit.EntityResource, resource.ID, nil, resource); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ResourceCreated, Resource: resource})
+	})
 }
 
 func (s *resourceService) GetByID(ctx context.Context, id int64) (*models.Resource, error) {
@@ -36,13 +57,55 @@ func (s *resourceService) GetByID(ctx context.Context, id int64) (*models.Resour
 }
 
 func (s *resourceService) Update(ctx context.Context, resource *models.Resource) error {
-	return s.resourceRepo.Update(ctx, resource)
+	before, err := s.resourceRepo.GetByID(ctx, resource.ID)
+	if err != nil {
+		return err
+	}
+	if resource.Timezone == "" {
+		resource.Timezone = before.Timezone
+	}
+	if err := normalizeResourceTimezone(resource); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.resourceRepo.Update(ctx, resource); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityResource, resource.ID, before, resource); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ResourceUpdated, Resource: resource})
+	})
 }
 
 func (s *resourceService) Delete(ctx context.Context, id int64) error {
-	return s.resourceRepo.Delete(ctx, id)
+	// Ресурс читается до удаления, чтобы подписчики знали его владельца
+	resource, err := s.resourceRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.resourceRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionDelete, audit.EntityResource, id, resource, nil); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ResourceDeleted, Resource: resource})
+	})
 }
 
 func (s *resourceService) List(ctx context.Context) ([]*models.Resource, error) {
 	return s.resourceRepo.List(ctx)
 }
+
+func normalizeResourceTimezone(resource *models.Resource) error {
+	tz, err := models.NormalizeTimezone(resource.Timezone)
+	if err != nil {
+		return fmt.Errorf("resource timezone: %w", err)
+	}
+	resource.Timezone = tz
+	return nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"errors"
+	"slices"
+
+	"smartbooking/internal/money"
+)
+
+// revenueTotal накапливает суммы в разных валютах, пересчитывая их в базовую.
+// Валюты без курса не попадают в итог и перечисляются в unconverted.
+type revenueTotal struct {
+	rates       *money.Rates
+	total       money.Amount
+	unconverted []string
+}
+
+func newRevenueTotal(rates *money.Rates) *revenueTotal {
+	return &revenueTotal{rates: rates}
+}
+
+func (t *revenueTotal) add(amount money.Amount, currency string) error {
+	converted, err := t.rates.ToBase(amount, currency)
+	if err != nil {
+		if !errors.Is(err, money.ErrUnknownRate) {
+			return err
+		}
+		if !slices.Contains(t.unconverted, currency) {
+			t.unconverted = append(t.unconverted, currency)
+		}
+		return nil
+	}
+	t.total += converted
+	return nil
+}
@@ -1,39 +1,118 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 
+	"smartbooking/internal/audit"
+	"smartbooking/internal/events"
+	"smartbooking/internal/logger"
 	"smartbooking/internal/models"
+	"smartbooking/internal/moderation"
 	"smartbooking/internal/repository"
+	"smartbooking/internal/storage"
 )
 
 var (
-	ErrInvalidRating = errors.New("rating must be between 1 and 5")
+	ErrInvalidRating            = errors.New("rating must be between 1 and 5")
+	ErrReviewBookingRequired    = errors.New("review must reference a completed booking")
+	ErrReviewBookingNotOwned    = errors.New("booking belongs to another user")
+	ErrReviewBookingMismatch    = errors.New("booking is for a different resource")
+	ErrReviewBookingNotFinished = errors.New("booking has not been completed yet")
+	ErrReplyTextRequired        = errors.New("reply text is required")
+	ErrNotResourceOwner         = errors.New("only the resource owner can reply to reviews")
+	ErrNotReviewAuthor          = errors.New("only the review author can manage its photos")
+	ErrTooManyReviewPhotos      = errors.New("review photo limit reached")
+	ErrUnsupportedPhotoType     = errors.New("unsupported photo type")
+	ErrInvalidReviewSort        = errors.New("sort must be one of newest, highest, lowest, helpful")
+	ErrInvalidReviewCursor      = errors.New("invalid cursor")
+	ErrCannotVoteOwnReview      = errors.New("cannot mark own review as helpful")
 )
 
+const (
+	defaultModerationLimit = 50
+	maxModerationLimit     = 200
+	maxReportReasonLength  = 1000
+	defaultTrendMonths     = 12
+	maxTrendMonths         = 60
+	defaultReviewPageSize  = 20
+	maxReviewPageSize      = 100
+)
+
+// ReviewListQuery параметры страницы списка отзывов. Cursor — NextCursor
+// предыдущей страницы, он действителен только для того же Sort.
+type ReviewListQuery struct {
+	Verified *bool
+	Rating   *int
+	Sort     string
+	Cursor   string
+	Limit    int
+}
+
 type ReviewService interface {
-	Create(ctx context.Context, userID, resourceID int64, bookingID *int64, rating int, comment string) (*models.Review, error)
-	GetByID(ctx context.Context, id int64) (*models.Review, error)
-	GetByResource(ctx context.Context, resourceID int64) ([]*models.Review, error)
-	GetByUser(ctx context.Context, userID int64) ([]*models.Review, error)
-	Update(ctx context.Context, id int64, rating int, comment string) (*models.Review, error)
+	Create(ctx context.Context, userID, resourceID int64, bookingID *int64, rating int, sub models.SubRatings, comment string) (*models.Review, error)
+	GetByID(ctx context.Context, id, viewerID int64) (*models.Review, error)
+	GetByResource(ctx context.Context, resourceID int64, query ReviewListQuery) (*models.ReviewPage, error)
+	GetByUser(ctx context.Context, userID, viewerID int64, query ReviewListQuery) (*models.ReviewPage, error)
+	Update(ctx context.Context, id int64, rating int, sub models.SubRatings, comment string) (*models.Review, error)
 	Delete(ctx context.Context, id int64) error
-	GetResourceAverageRating(ctx context.Context, resourceID int64) (float64, error)
+	GetRatingSummary(ctx context.Context, resourceID int64, months int) (*models.RatingSummary, error)
+	Reply(ctx context.Context, reviewID, ownerID int64, text string) (*models.Review, error)
+	Report(ctx context.Context, reviewID, userID int64, reason string) (*models.ReviewReport, error)
+	ModerationQueue(ctx context.Context, limit, offset int) ([]*models.ReviewModerationItem, error)
+	Approve(ctx context.Context, id int64) (*models.Review, error)
+	Hide(ctx context.Context, id int64) (*models.Review, error)
+	UploadPhoto(ctx context.Context, reviewID, userID int64, file io.Reader, fileName string) (*models.ReviewPhoto, error)
+	DeletePhoto(ctx context.Context, reviewID, photoID, userID int64) error
+	MarkHelpful(ctx context.Context, reviewID, userID int64) (*models.Review, error)
+	UnmarkHelpful(ctx context.Context, reviewID, userID int64) (*models.Review, error)
 }
 
 type reviewService struct {
-	reviewRepo reviewRepository
+	reviewRepo      reviewRepository
+	bookingRepo     repository.BookingRepository
+	resourceRepo    repository.ResourceRepository
+	storage         storage.StorageService
+	tx              repository.Transactor
+	publisher       events.Publisher
+	auditor         audit.Recorder
+	filter          *moderation.Filter
+	allowUnverified bool
+	maxPhotos       int
 }
 
-func NewReviewService(reviewRepo repository.ReviewRepository) ReviewService {
+// NewReviewService creates a new ReviewService instance. Отзывы, которые filter
+// считает подозрительными, ждут модерации. При allowUnverified отзыв можно
+// оставить без бронирования, он сохраняется неподтвержденным. К отзыву можно
+// приложить не больше maxPhotos фотографий.
+func NewReviewService(reviewRepo repository.ReviewRepository, bookingRepo repository.BookingRepository, resourceRepo repository.ResourceRepository, storage storage.StorageService, tx repository.Transactor, publisher events.Publisher, auditor audit.Recorder, filter *moderation.Filter, allowUnverified bool, maxPhotos int) ReviewService {
 	return &reviewService{
-		reviewRepo: reviewRepo,
+		reviewRepo:      reviewRepo,
+		bookingRepo:     bookingRepo,
+		resourceRepo:    resourceRepo,
+		storage:         storage,
+		tx:              tx,
+		publisher:       publisher,
+		auditor:         auditor,
+		filter:          filter,
+		allowUnverified: allowUnverified,
+		maxPhotos:       maxPhotos,
 	}
 }
 
-func (s *reviewService) Create(ctx context.Context, userID, resourceID int64, bookingID *int64, rating int, comment string) (*models.Review, error) {
-	if rating < 1 || rating > 5 {
+// Create создает отзыв. Отзыв по бронированию подтвержден: бронирование должно
+// принадлежать автору, относиться к тому же ресурсу и уже закончиться. На одно
+// бронирование допускается один отзыв. Подозрительный отзыв публикуется только
+// после одобрения модератором.
+func (s *reviewService) Create(ctx context.Context, userID, resourceID int64, bookingID *int64, rating int, sub models.SubRatings, comment string) (*models.Review, error) {
+	if !validRatings(rating, sub) {
 		return nil, ErrInvalidRating
 	}
 
@@ -43,29 +122,125 @@ func (s *reviewService) Create(ctx context.Context, userID, resourceID int64, bo
 		BookingID:  bookingID,
 		Rating:     rating,
 		Comment:    comment,
+		Status:     s.screen(comment),
+		SubRatings: sub,
+		Photos:     []*models.ReviewPhoto{},
+	}
+
+	if bookingID == nil {
+		if !s.allowUnverified {
+			return nil, ErrReviewBookingRequired
+		}
+	} else {
+		booking, err := s.bookingRepo.GetByID(ctx, *bookingID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkReviewBooking(booking, userID, resourceID, time.Now()); err != nil {
+			return nil, err
+		}
+		review.ResourceID = booking.ResourceID
+		review.IsVerified = true
 	}
 
-	if err := s.reviewRepo.Create(ctx, review); err != nil {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Create(ctx, review); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionCreate, audit.EntityReview, review.ID, nil, review); err != nil {
+			return err
+		}
+		if review.Status != models.ReviewPublished {
+			return nil
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ReviewPosted, Review: review})
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return review, nil
 }
 
-func (s *reviewService) GetByID(ctx context.Context, id int64) (*models.Review, error) {
-	return s.reviewRepo.GetByID(ctx, id)
+// GetByID возвращает отзыв, если он виден viewerID (0 — анонимный запрос)
+func (s *reviewService) GetByID(ctx context.Context, id, viewerID int64) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewVisible(review, viewerID) {
+		return nil, repository.ErrReviewNotFound
+	}
+	return review, nil
 }
 
-func (s *reviewService) GetByResource(ctx context.Context, resourceID int64) ([]*models.Review, error) {
-	return s.reviewRepo.GetByResource(ctx, resourceID)
+func (s *reviewService) GetByResource(ctx context.Context, resourceID int64, query ReviewListQuery) (*models.ReviewPage, error) {
+	return s.listPage(query, func(filter repository.ReviewFilter) ([]*models.Review, error) {
+		return s.reviewRepo.GetByResource(ctx, resourceID, filter)
+	})
 }
 
-func (s *reviewService) GetByUser(ctx context.Context, userID int64) ([]*models.Review, error) {
-	return s.reviewRepo.GetByUser(ctx, userID)
+// GetByUser возвращает опубликованные отзывы пользователя; сам автор видит
+// и отзывы, ожидающие модерации
+func (s *reviewService) GetByUser(ctx context.Context, userID, viewerID int64, query ReviewListQuery) (*models.ReviewPage, error) {
+	return s.listPage(query, func(filter repository.ReviewFilter) ([]*models.Review, error) {
+		filter.IncludePending = viewerID != 0 && viewerID == userID
+		return s.reviewRepo.GetByUser(ctx, userID, filter)
+	})
 }
 
-func (s *reviewService) Update(ctx context.Context, id int64, rating int, comment string) (*models.Review, error) {
-	if rating < 1 || rating > 5 {
+// listPage проверяет параметры, запрашивает на один отзыв больше страницы,
+// чтобы узнать, есть ли следующая, и формирует курсор по последнему отзыву
+func (s *reviewService) listPage(query ReviewListQuery, list func(repository.ReviewFilter) ([]*models.Review, error)) (*models.ReviewPage, error) {
+	sort := repository.ReviewSort(query.Sort)
+	switch sort {
+	case "":
+		sort = repository.ReviewSortNewest
+	case repository.ReviewSortNewest, repository.ReviewSortHighest, repository.ReviewSortLowest, repository.ReviewSortHelpful:
+	default:
+		return nil, ErrInvalidReviewSort
+	}
+	if query.Rating != nil && (*query.Rating < 1 || *query.Rating > 5) {
+		return nil, ErrInvalidRating
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultReviewPageSize
+	}
+	if limit > maxReviewPageSize {
+		limit = maxReviewPageSize
+	}
+
+	filter := repository.ReviewFilter{
+		Verified: query.Verified,
+		Rating:   query.Rating,
+		Sort:     sort,
+		Limit:    limit + 1,
+	}
+	if query.Cursor != "" {
+		after, err := decodeReviewCursor(query.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	reviews, err := list(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ReviewPage{Reviews: reviews}
+	if len(reviews) > limit {
+		page.Reviews = reviews[:limit]
+		page.NextCursor = encodeReviewCursor(page.Reviews[limit-1], sort)
+	}
+	return page, nil
+}
+
+func (s *reviewService) Update(ctx context.Context, id int64, rating int, sub models.SubRatings, comment string) (*models.Review, error) {
+	if !validRatings(rating, sub) {
 		return nil, ErrInvalidRating
 	}
 
@@ -74,10 +249,28 @@ func (s *reviewService) Update(ctx context.Context, id int64, rating int, commen
 		return nil, err
 	}
 
+	before := *review
 	review.Rating = rating
+	review.SubRatings = sub
 	review.Comment = comment
+	// Скрытый модератором отзыв редактирование не возвращает
+	if review.Status != models.ReviewHidden {
+		review.Status = s.screen(comment)
+	}
 
-	if err := s.reviewRepo.Update(ctx, review); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Update(ctx, review); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityReview, review.ID, &before, review); err != nil {
+			return err
+		}
+		if review.Status != models.ReviewPublished {
+			return nil
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ReviewUpdated, Review: review})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -85,19 +278,453 @@ func (s *reviewService) Update(ctx context.Context, id int64, rating int, commen
 }
 
 func (s *reviewService) Delete(ctx context.Context, id int64) error {
-	return s.reviewRepo.Delete(ctx, id)
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionDelete, audit.EntityReview, id, review, nil); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ReviewDeleted, Review: review})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Строки фотографий удалены каскадом, файлы удаляются после фиксации
+	for _, photo := range review.Photos {
+		if err := s.storage.DeleteFile(ctx, photo.StorageKey); err != nil {
+			logger.Error("Failed to delete review %d photo %s from storage: %v", id, photo.StorageKey, err)
+		}
+	}
+	return nil
+}
+
+// UploadPhoto прикладывает фотографию к отзыву. Допустимы те же типы файлов,
+// что и для фотографий ресурса; EXIF и координаты съемки снимаются.
+func (s *reviewService) UploadPhoto(ctx context.Context, reviewID, userID int64, file io.Reader, fileName string) (*models.ReviewPhoto, error) {
+	src, err := decodePhoto(file)
+	if err != nil {
+		return nil, err
+	}
+	img, err := src.Original()
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID {
+		return nil, ErrNotReviewAuthor
+	}
+	if len(review.Photos) >= s.maxPhotos {
+		return nil, ErrTooManyReviewPhotos
+	}
+
+	result, err := s.storage.UploadFile(ctx, bytes.NewReader(img.Data), photoFileName(fileName, "", img), img.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки файла: %w", err)
+	}
+
+	photo := &models.ReviewPhoto{
+		ReviewID:   reviewID,
+		URL:        result.URL,
+		StorageKey: result.StorageKey,
+		FileName:   result.FileName,
+		FileSize:   int64(len(img.Data)),
+		MimeType:   img.ContentType,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		photos, err := s.reviewRepo.ListPhotosForUpdate(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+		if len(photos) >= s.maxPhotos {
+			return ErrTooManyReviewPhotos
+		}
+		if err := s.reviewRepo.CreatePhoto(ctx, photo); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionCreate, audit.EntityPhoto, photo.ID, nil, photo)
+	})
+	if err != nil {
+		// Запись не создана — файл в storage больше не нужен
+		_ = s.storage.DeleteFile(ctx, result.StorageKey)
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+// DeletePhoto удаляет фотографию отзыва из БД и из storage
+func (s *reviewService) DeletePhoto(ctx context.Context, reviewID, photoID, userID int64) error {
+	photo, err := s.reviewRepo.GetPhoto(ctx, photoID)
+	if err != nil {
+		return err
+	}
+	if photo.ReviewID != reviewID {
+		return repository.ErrReviewPhotoNotFound
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if review.UserID != userID {
+		return ErrNotReviewAuthor
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.DeletePhoto(ctx, photoID); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionDelete, audit.EntityPhoto, photoID, photo, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.DeleteFile(ctx, photo.StorageKey); err != nil {
+		logger.Error("Failed to delete review %d photo %s from storage: %v", reviewID, photo.StorageKey, err)
+	}
+	return nil
+}
+
+// GetRatingSummary возвращает сводку оценок ресурса и тренд средней оценки
+// за последние months месяцев, включая текущий
+func (s *reviewService) GetRatingSummary(ctx context.Context, resourceID int64, months int) (*models.RatingSummary, error) {
+	if months <= 0 {
+		months = defaultTrendMonths
+	}
+	if months > maxTrendMonths {
+		months = maxTrendMonths
+	}
+
+	resource, err := s.resourceRepo.GetByID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.reviewRepo.GetRatingSummary(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(resource.Location())
+	since := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, now.Location())
+	points, err := s.reviewRepo.GetRatingTrend(ctx, resourceID, since, resource.Location().String())
+	if err != nil {
+		return nil, err
+	}
+	summary.Trend = ratingTrend(points, since, months)
+
+	return summary, nil
+}
+
+// Reply публикует ответ владельца ресурса на отзыв или редактирует прежний.
+// Автор отзыва получает уведомление только о первом ответе.
+func (s *reviewService) Reply(ctx context.Context, reviewID, ownerID int64, text string) (*models.Review, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrReplyTextRequired
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := s.resourceRepo.GetByID(ctx, review.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if resource.OwnerID == nil || *resource.OwnerID != ownerID {
+		return nil, ErrNotResourceOwner
+	}
+
+	before := *review
+	reply := &models.ReviewReply{
+		ReviewID: reviewID,
+		OwnerID:  ownerID,
+		Text:     text,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.reviewRepo.SaveReply(ctx, reply)
+		if err != nil {
+			return err
+		}
+		review.Reply = reply
+		if err := s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityReview, review.ID, &before, review); err != nil {
+			return err
+		}
+		if !created {
+			return nil
+		}
+		return s.publisher.Publish(ctx, events.Event{Type: events.ReviewReplied, Review: review})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// Report сохраняет жалобу пользователя на отзыв; отзыв попадает в очередь модерации
+func (s *reviewService) Report(ctx context.Context, reviewID, userID int64, reason string) (*models.ReviewReport, error) {
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewVisible(review, userID) {
+		return nil, repository.ErrReviewNotFound
+	}
+
+	report := &models.ReviewReport{
+		ReviewID: reviewID,
+		UserID:   userID,
+		Reason:   truncateRunes(strings.TrimSpace(reason), maxReportReasonLength),
+	}
+	if err := s.reviewRepo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ModerationQueue возвращает отзывы, ждущие модерации или с открытыми жалобами
+func (s *reviewService) ModerationQueue(ctx context.Context, limit, offset int) ([]*models.ReviewModerationItem, error) {
+	if limit <= 0 {
+		limit = defaultModerationLimit
+	}
+	if limit > maxModerationLimit {
+		limit = maxModerationLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	reviews, err := s.reviewRepo.ListModerationQueue(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(reviews))
+	items := make([]*models.ReviewModerationItem, len(reviews))
+	byID := make(map[int64]*models.ReviewModerationItem, len(reviews))
+	for i, review := range reviews {
+		ids[i] = review.ID
+		items[i] = &models.ReviewModerationItem{Review: review, Reports: []*models.ReviewReport{}}
+		byID[review.ID] = items[i]
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	reports, err := s.reviewRepo.ListOpenReports(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, report := range reports {
+		byID[report.ReviewID].Reports = append(byID[report.ReviewID].Reports, report)
+	}
+
+	return items, nil
+}
+
+// Approve публикует отзыв и закрывает жалобы на него
+func (s *reviewService) Approve(ctx context.Context, id int64) (*models.Review, error) {
+	return s.moderate(ctx, id, models.ReviewPublished)
+}
+
+// Hide скрывает отзыв из списков и среднего рейтинга и закрывает жалобы на него
+func (s *reviewService) Hide(ctx context.Context, id int64) (*models.Review, error) {
+	return s.moderate(ctx, id, models.ReviewHidden)
+}
+
+func (s *reviewService) moderate(ctx context.Context, id int64, status models.ReviewStatus) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *review
+	review.Status = status
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Moderate(ctx, id, status); err != nil {
+			return err
+		}
+		if err := s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityReview, id, &before, review); err != nil {
+			return err
+		}
+
+		switch {
+		case before.Status == status:
+			return nil
+		case before.Status == models.ReviewPending && status == models.ReviewPublished:
+			return s.publisher.Publish(ctx, events.Event{Type: events.ReviewPosted, Review: review})
+		default:
+			return s.publisher.Publish(ctx, events.Event{Type: events.ReviewUpdated, Review: review})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// screen определяет статус нового или измененного отзыва по предварительному фильтру
+func (s *reviewService) screen(comment string) models.ReviewStatus {
+	if s.filter.Suspicious(comment) {
+		return models.ReviewPending
+	}
+	return models.ReviewPublished
+}
+
+// MarkHelpful отмечает опубликованный отзыв полезным; один пользователь — одна отметка
+func (s *reviewService) MarkHelpful(ctx context.Context, reviewID, userID int64) (*models.Review, error) {
+	return s.vote(ctx, reviewID, userID, s.reviewRepo.AddVote)
+}
+
+// UnmarkHelpful снимает отметку пользователя
+func (s *reviewService) UnmarkHelpful(ctx context.Context, reviewID, userID int64) (*models.Review, error) {
+	return s.vote(ctx, reviewID, userID, s.reviewRepo.RemoveVote)
+}
+
+func (s *reviewService) vote(ctx context.Context, reviewID, userID int64, apply func(ctx context.Context, reviewID, userID int64) error) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != models.ReviewPublished {
+		return nil, repository.ErrReviewNotFound
+	}
+	if review.UserID == userID {
+		return nil, ErrCannotVoteOwnReview
+	}
+
+	if err := apply(ctx, reviewID, userID); err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.GetByID(ctx, reviewID)
 }
 
-func (s *reviewService) GetResourceAverageRating(ctx context.Context, resourceID int64) (float64, error) {
-	return s.reviewRepo.GetAverageRating(ctx, resourceID)
+// reviewCursor сериализованная позиция в списке отзывов
+type reviewCursor struct {
+	Sort      repository.ReviewSort `json:"s"`
+	Key       int                   `json:"k,omitempty"`
+	CreatedAt time.Time             `json:"t"`
+	ID        int64                 `json:"i"`
+}
+
+func encodeReviewCursor(review *models.Review, sort repository.ReviewSort) string {
+	c := reviewCursor{Sort: sort, CreatedAt: review.CreatedAt, ID: review.ID}
+	switch sort {
+	case repository.ReviewSortHighest, repository.ReviewSortLowest:
+		c.Key = review.Rating
+	case repository.ReviewSortHelpful:
+		c.Key = review.HelpfulCount
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeReviewCursor(value string, sort repository.ReviewSort) (*repository.ReviewCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidReviewCursor
+	}
+	var c reviewCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || c.ID <= 0 {
+		return nil, ErrInvalidReviewCursor
+	}
+	return &repository.ReviewCursor{Key: c.Key, CreatedAt: c.CreatedAt, ID: c.ID}, nil
+}
+
+// ratingTrend дополняет помесячные точки месяцами без отзывов
+func ratingTrend(points []models.RatingTrendPoint, since time.Time, months int) []models.RatingTrendPoint {
+	byMonth := make(map[string]models.RatingTrendPoint, len(points))
+	for _, p := range points {
+		byMonth[p.Month] = p
+	}
+
+	trend := make([]models.RatingTrendPoint, 0, months)
+	for i := 0; i < months; i++ {
+		month := since.AddDate(0, i, 0).Format("2006-01")
+		point, ok := byMonth[month]
+		if !ok {
+			point = models.RatingTrendPoint{Month: month}
+		}
+		trend = append(trend, point)
+	}
+	return trend
+}
+
+// reviewVisible сообщает, можно ли показать отзыв viewerID: опубликованный
+// виден всем, ожидающий модерации — только автору, скрытый — никому
+func reviewVisible(review *models.Review, viewerID int64) bool {
+	switch review.Status {
+	case models.ReviewPublished:
+		return true
+	case models.ReviewPending:
+		return viewerID != 0 && viewerID == review.UserID
+	default:
+		return false
+	}
+}
+
+func validRatings(rating int, sub models.SubRatings) bool {
+	for _, r := range []*int{&rating, sub.Cleanliness, sub.Value, sub.Location} {
+		if r != nil && (*r < 1 || *r > 5) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkReviewBooking проверяет, что по бронированию можно оставить подтвержденный
+// отзыв. resourceID == 0 означает ресурс бронирования.
+func checkReviewBooking(booking *models.Booking, userID, resourceID int64, now time.Time) error {
+	if booking.UserID != userID {
+		return ErrReviewBookingNotOwned
+	}
+	if resourceID != 0 && booking.ResourceID != resourceID {
+		return ErrReviewBookingMismatch
+	}
+	if booking.Status != models.StatusConfirmed || booking.EndTime.After(now) {
+		return ErrReviewBookingNotFinished
+	}
+	return nil
 }
 
 type reviewRepository interface {
 	Create(ctx context.Context, review *models.Review) error
 	GetByID(ctx context.Context, id int64) (*models.Review, error)
-	GetByResource(ctx context.Context, resourceID int64) ([]*models.Review, error)
-	GetByUser(ctx context.Context, userID int64) ([]*models.Review, error)
+	GetByResource(ctx context.Context, resourceID int64, filter repository.ReviewFilter) ([]*models.Review, error)
+	GetByUser(ctx context.Context, userID int64, filter repository.ReviewFilter) ([]*models.Review, error)
 	Update(ctx context.Context, review *models.Review) error
 	Delete(ctx context.Context, id int64) error
-	GetAverageRating(ctx context.Context, resourceID int64) (float64, error)
+	GetRatingSummary(ctx context.Context, resourceID int64) (*models.RatingSummary, error)
+	GetRatingTrend(ctx context.Context, resourceID int64, since time.Time, timezone string) ([]models.RatingTrendPoint, error)
+	SaveReply(ctx context.Context, reply *models.ReviewReply) (created bool, err error)
+	CreateReport(ctx context.Context, report *models.ReviewReport) error
+	ListModerationQueue(ctx context.Context, limit, offset int) ([]*models.Review, error)
+	ListOpenReports(ctx context.Context, reviewIDs []int64) ([]*models.ReviewReport, error)
+	Moderate(ctx context.Context, id int64, status models.ReviewStatus) error
+	CreatePhoto(ctx context.Context, photo *models.ReviewPhoto) error
+	GetPhoto(ctx context.Context, id int64) (*models.ReviewPhoto, error)
+	ListPhotosForUpdate(ctx context.Context, reviewID int64) ([]*models.ReviewPhoto, error)
+	DeletePhoto(ctx context.Context, id int64) error
+	AddVote(ctx context.Context, reviewID, userID int64) error
+	RemoveVote(ctx context.Context, reviewID, userID int64) error
 }
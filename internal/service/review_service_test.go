@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+)
+
+// visibilityReviewRepo отдает отзывы из памяти и запоминает фильтр списка
+type visibilityReviewRepo struct {
+	repository.ReviewRepository
+	reviews map[int64]*models.Review
+	filter  repository.ReviewFilter
+}
+
+func (r *visibilityReviewRepo) GetByID(ctx context.Context, id int64) (*models.Review, error) {
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, repository.ErrReviewNotFound
+	}
+	return review, nil
+}
+
+func (r *visibilityReviewRepo) GetByUser(ctx context.Context, userID int64, filter repository.ReviewFilter) ([]*models.Review, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func TestReviewVisibility(t *testing.T) {
+	const author, stranger = 7, 8
+	repo := &visibilityReviewRepo{reviews: map[int64]*models.Review{
+		1: {ID: 1, UserID: author, Status: models.ReviewPublished},
+		2: {ID: 2, UserID: author, Status: models.ReviewPending},
+		3: {ID: 3, UserID: author, Status: models.ReviewHidden},
+	}}
+	svc := &reviewService{reviewRepo: repo}
+	ctx := context.Background()
+
+	t.Run("GetByID", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			id       int64
+			viewerID int64
+			visible  bool
+		}{
+			{"published, anonymous", 1, 0, true},
+			{"published, stranger", 1, stranger, true},
+			{"pending, anonymous", 2, 0, false},
+			{"pending, stranger", 2, stranger, false},
+			{"pending, author", 2, author, true},
+			{"hidden, stranger", 3, stranger, false},
+			{"hidden, author", 3, author, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				review, err := svc.GetByID(ctx, tt.id, tt.viewerID)
+				if tt.visible && (err != nil || review.ID != tt.id) {
+					t.Errorf("GetByID = %v, %v; want review %d", review, err, tt.id)
+				}
+				if !tt.visible && !errors.Is(err, repository.ErrReviewNotFound) {
+					t.Errorf("GetByID = %v, %v; want ErrReviewNotFound", review, err)
+				}
+			})
+		}
+	})
+
+	t.Run("GetByUser", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			viewerID    int64
+			wantPending bool
+		}{
+			{"anonymous", 0, false},
+			{"stranger", stranger, false},
+			{"author", author, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := svc.GetByUser(ctx, author, tt.viewerID, ReviewListQuery{}); err != nil {
+					t.Fatalf("GetByUser: %v", err)
+				}
+				if repo.filter.IncludePending != tt.wantPending {
+					t.Errorf("IncludePending = %v, want %v", repo.filter.IncludePending, tt.wantPending)
+				}
+			})
+		}
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		for _, id := range []int64{2, 3} {
+			if _, err := svc.Report(ctx, id, stranger, "spam"); !errors.Is(err, repository.ErrReviewNotFound) {
+				t.Errorf("Report on review %d = %v, want ErrReviewNotFound", id, err)
+			}
+		}
+	})
+}
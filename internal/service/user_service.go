@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"smartbooking/internal/audit"
 	"smartbooking/internal/models"
 	"smartbooking/internal/repository"
 )
@@ -17,12 +18,16 @@ type UserService interface {
 
 type userService struct {
 	userRepo repository.UserRepository
+	tx       repository.Transactor
+	auditor  audit.Recorder
 }
 
 // NewUserService creates a new UserService instance
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, tx repository.Transactor, auditor audit.Recorder) UserService {
 	return &userService{
 		userRepo: userRepo,
+		tx:       tx,
+		auditor:  auditor,
 	}
 }
 
@@ -31,11 +36,31 @@ func (s *userService) GetByID(ctx context.Context, id int64) (*models.User, erro
 }
 
 func (s *userService) Update(ctx context.Context, user *models.User) error {
-	return s.userRepo.Update(ctx, user)
+	before, err := s.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionUpdate, audit.EntityUser, user.ID, before, user)
+	})
 }
 
 func (s *userService) Delete(ctx context.Context, id int64) error {
-	return s.userRepo.Delete(ctx, id)
+	before, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.auditor.Record(ctx, audit.ActionDelete, audit.EntityUser, id, before, nil)
+	})
 }
 
 func (s *userService) List(ctx context.Context) ([]*models.User, error) {
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"smartbooking/internal/events"
+	"smartbooking/internal/logger"
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/retry"
+	"smartbooking/internal/webhook"
+)
+
+var (
+	ErrInvalidWebhookURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookEvent = errors.New("unknown webhook event type")
+)
+
+const (
+	webhookBatchSize           = 20
+	webhookLease               = 2 * time.Minute
+	defaultWebhookDeliveryPage = 50
+	maxWebhookDeliveryPage     = 200
+)
+
+// webhookBackoff задержка повтора доставки: 30с, 1м, 2м, ... но не больше 12 часов
+var webhookBackoff = retry.Backoff{Base: 30 * time.Second, Max: 12 * time.Hour}
+
+// WebhookService manages owner webhook subscriptions and delivers events to them
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, ownerID int64, rawURL string, eventTypes []string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, ownerID int64) ([]*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, ownerID, id int64) error
+	ListDeliveries(ctx context.Context, ownerID, subscriptionID int64, limit, offset int) ([]*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, ownerID, subscriptionID, deliveryID int64) error
+	HandleEvent(ctx context.Context, event events.Event) error
+	ProcessDeliveries(ctx context.Context) (int, error)
+}
+
+type webhookService struct {
+	webhookRepo  repository.WebhookRepository
+	resourceRepo repository.ResourceRepository
+	client       *webhook.Client
+	maxAttempts  int
+}
+
+// NewWebhookService creates a new WebhookService instance. HandleEvent только
+// записывает доставки в журнал, отправляет их ProcessDeliveries из фонового воркера.
+func NewWebhookService(webhookRepo repository.WebhookRepository, resourceRepo repository.ResourceRepository, client *webhook.Client, maxAttempts int) WebhookService {
+	return &webhookService{
+		webhookRepo:  webhookRepo,
+		resourceRepo: resourceRepo,
+		client:       client,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// WebhookEvents перечисляет события, которые можно получать вебхуками
+var WebhookEvents = []events.Type{
+	events.BookingCreated,
+	events.BookingConfirmed,
+	events.BookingCancelled,
+	events.BookingReminder,
+	events.ReviewPosted,
+	events.ReviewUpdated,
+	events.ReviewDeleted,
+	events.ReviewReplied,
+	events.ResourceCreated,
+	events.ResourceUpdated,
+	events.ResourceDeleted,
+}
+
+// CreateSubscription создает подписку и генерирует секрет подписи.
+// Пустой список eventTypes означает подписку на все события.
+func (s *webhookService) CreateSubscription(ctx context.Context, ownerID int64, rawURL string, eventTypes []string) (*models.WebhookSubscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	for _, t := range eventTypes {
+		if !slices.Contains(WebhookEvents, events.Type(t)) {
+			return nil, ErrInvalidWebhookEvent
+		}
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	sub := &models.WebhookSubscription{
+		OwnerID:    ownerID,
+		URL:        u.String(),
+		Secret:     "whsec_" + hex.EncodeToString(raw),
+		EventTypes: slices.Compact(slices.Sorted(slices.Values(eventTypes))),
+		IsActive:   true,
+	}
+	if err := s.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// ListSubscriptions возвращает подписки владельца без секретов
+func (s *webhookService) ListSubscriptions(ctx context.Context, ownerID int64) ([]*models.WebhookSubscription, error) {
+	subs, err := s.webhookRepo.ListSubscriptionsByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, ownerID, id int64) error {
+	if _, err := s.ownedSubscription(ctx, ownerID, id); err != nil {
+		return err
+	}
+	return s.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, ownerID, subscriptionID int64, limit, offset int) ([]*models.WebhookDelivery, error) {
+	if _, err := s.ownedSubscription(ctx, ownerID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryPage
+	}
+	if limit > maxWebhookDeliveryPage {
+		limit = maxWebhookDeliveryPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.webhookRepo.ListDeliveries(ctx, subscriptionID, limit, offset)
+}
+
+// Redeliver повторно ставит доставку в очередь, например после исправления получателя
+func (s *webhookService) Redeliver(ctx context.Context, ownerID, subscriptionID, deliveryID int64) error {
+	if _, err := s.ownedSubscription(ctx, ownerID, subscriptionID); err != nil {
+		return err
+	}
+	return s.webhookRepo.Redeliver(ctx, deliveryID, subscriptionID)
+}
+
+// ownedSubscription возвращает подписку владельца; чужие подписки считаются ненайденными
+func (s *webhookService) ownedSubscription(ctx context.Context, ownerID, id int64) (*models.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.OwnerID != ownerID {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+// HandleEvent записывает доставку события во все подходящие подписки владельца ресурса
+func (s *webhookService) HandleEvent(ctx context.Context, event events.Event) error {
+	ownerID, err := s.eventOwner(ctx, event)
+	if err != nil || ownerID == 0 {
+		return err
+	}
+
+	subs, err := s.webhookRepo.ListSubscriptionsForEvent(ctx, ownerID, string(event.Type))
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		err := s.webhookRepo.EnqueueDelivery(ctx, &models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      string(event.Type),
+			Payload:        payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eventOwner определяет владельца ресурса, к которому относится событие; 0 — владельца нет
+func (s *webhookService) eventOwner(ctx context.Context, event events.Event) (int64, error) {
+	var resourceID int64
+	switch {
+	case event.Resource != nil:
+		if event.Resource.OwnerID != nil {
+			return *event.Resource.OwnerID, nil
+		}
+		return 0, nil
+	case event.Booking != nil:
+		resourceID = event.Booking.ResourceID
+	case event.Review != nil:
+		resourceID = event.Review.ResourceID
+	default:
+		return 0, nil
+	}
+
+	resource, err := s.resourceRepo.GetByID(ctx, resourceID)
+	if errors.Is(err, repository.ErrResourceNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if resource.OwnerID == nil {
+		return 0, nil
+	}
+	return *resource.OwnerID, nil
+}
+
+// ProcessDeliveries отправляет очередную пачку вебхуков и возвращает число доставленных.
+// После ошибки доставка откладывается с экспоненциальной задержкой, после
+// maxAttempts попыток помечается как failed.
+func (s *webhookService) ProcessDeliveries(ctx context.Context) (int, error) {
+	batch, err := s.webhookRepo.ClaimDueDeliveries(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// resp — ответ получателя на текущую доставку, сохраняется в журнал
+	var resp *webhook.Response
+	queue := &retry.Queue[*models.WebhookDelivery]{
+		Backoff:     webhookBackoff,
+		MaxAttempts: s.maxAttempts,
+		Attempts:    func(d *models.WebhookDelivery) int { return d.Attempts },
+		Send: func(ctx context.Context, d *models.WebhookDelivery) error {
+			var err error
+			resp, err = s.client.Send(ctx, &webhook.Request{
+				URL:        d.URL,
+				Secret:     d.Secret,
+				EventType:  d.EventType,
+				DeliveryID: d.ID,
+				Payload:    d.Payload,
+			})
+			return err
+		},
+		Done: func(ctx context.Context, d *models.WebhookDelivery) error {
+			return s.webhookRepo.MarkDelivered(ctx, d.ID, resp.Status, resp.Body)
+		},
+		Failed: func(ctx context.Context, d *models.WebhookDelivery, attempt int, err error, next *time.Time) error {
+			logger.Error("Webhook delivery %d to %s failed (attempt %d/%d): %v", d.ID, d.URL, attempt, s.maxAttempts, err)
+			return s.webhookRepo.MarkDeliveryFailed(ctx, d.ID, resp.Status, resp.Body, err.Error(), next)
+		},
+	}
+
+	return queue.Process(ctx, batch)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"smartbooking/internal/models"
+	"smartbooking/internal/repository"
+	"smartbooking/internal/webhook"
+)
+
+// memoryWebhookRepo хранит одну подписку и журнал доставок в памяти.
+// ClaimDueDeliveries не смотрит на next_attempt_at: тест сам решает, когда
+// запускать очередной проход, а задержки проверяет по сохраненному времени.
+type memoryWebhookRepo struct {
+	repository.WebhookRepository
+	sub        *models.WebhookSubscription
+	deliveries []*models.WebhookDelivery
+}
+
+func (r *memoryWebhookRepo) GetSubscription(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	if r.sub == nil || r.sub.ID != id {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return r.sub, nil
+}
+
+func (r *memoryWebhookRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	var batch []*models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == models.WebhookDeliveryPending && len(batch) < limit {
+			claimed := *d
+			claimed.URL = r.sub.URL
+			claimed.Secret = r.sub.Secret
+			batch = append(batch, &claimed)
+		}
+	}
+	return batch, nil
+}
+
+func (r *memoryWebhookRepo) delivery(id int64) *models.WebhookDelivery {
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (r *memoryWebhookRepo) MarkDelivered(ctx context.Context, id int64, responseStatus int, responseBody string) error {
+	d := r.delivery(id)
+	now := time.Now()
+	d.Status = models.WebhookDeliveryDelivered
+	d.Attempts++
+	d.ResponseStatus = &responseStatus
+	d.DeliveredAt = &now
+	return nil
+}
+
+func (r *memoryWebhookRepo) MarkDeliveryFailed(ctx context.Context, id int64, responseStatus int, responseBody, lastError string, nextAttemptAt *time.Time) error {
+	d := r.delivery(id)
+	d.Attempts++
+	d.ResponseStatus = &responseStatus
+	d.LastError = lastError
+	if nextAttemptAt == nil {
+		d.Status = models.WebhookDeliveryFailed
+		return nil
+	}
+	d.NextAttemptAt = *nextAttemptAt
+	return nil
+}
+
+func (r *memoryWebhookRepo) Redeliver(ctx context.Context, id, subscriptionID int64) error {
+	d := r.delivery(id)
+	if d == nil || d.SubscriptionID != subscriptionID {
+		return repository.ErrWebhookDeliveryNotFound
+	}
+	d.Status = models.WebhookDeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	d.DeliveredAt = nil
+	return nil
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{10, 256 * time.Minute},
+		{12, 12 * time.Hour},
+		{100, 12 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff.Delay(tt.attempt); got != tt.want {
+			t.Errorf("webhookBackoff.Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestProcessDeliveriesBackoffAndRedeliver(t *testing.T) {
+	// Получатель отвечает 503, пока его не "починят"
+	var healthy atomic.Bool
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get(webhook.HeaderSignature) == "" {
+			t.Error("request without signature header")
+		}
+		if !healthy.Load() {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := &memoryWebhookRepo{
+		sub: &models.WebhookSubscription{ID: 5, OwnerID: 1, URL: srv.URL, Secret: "whsec_test", IsActive: true},
+		deliveries: []*models.WebhookDelivery{{
+			ID: 9, SubscriptionID: 5, EventType: "booking.created",
+			Payload: []byte(`{"id":1}`), Status: models.WebhookDeliveryPending,
+		}},
+	}
+	svc := NewWebhookService(repo, nil, webhook.NewClient(time.Second), 3)
+	ctx := context.Background()
+	d := repo.deliveries[0]
+
+	// Первые попытки откладываются на 30с и 1м, третья — последняя
+	for attempt, wantDelay := range []time.Duration{30 * time.Second, time.Minute} {
+		before := time.Now()
+		if _, err := svc.ProcessDeliveries(ctx); err != nil {
+			t.Fatalf("ProcessDeliveries: %v", err)
+		}
+		if d.Status != models.WebhookDeliveryPending || d.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d: status %s, attempts %d", attempt+1, d.Status, d.Attempts)
+		}
+		if delay := d.NextAttemptAt.Sub(before); delay < wantDelay || delay > wantDelay+time.Second {
+			t.Errorf("attempt %d retried after %v, want %v", attempt+1, delay, wantDelay)
+		}
+	}
+	if _, err := svc.ProcessDeliveries(ctx); err != nil {
+		t.Fatalf("ProcessDeliveries: %v", err)
+	}
+	if d.Status != models.WebhookDeliveryFailed || d.Attempts != 3 {
+		t.Fatalf("after max attempts: status %s, attempts %d, want failed after 3", d.Status, d.Attempts)
+	}
+	if n, _ := svc.ProcessDeliveries(ctx); n != 0 || requests.Load() != 3 {
+		t.Fatalf("failed delivery was sent again: delivered %d, requests %d", n, requests.Load())
+	}
+
+	// Чужой владелец не может поставить доставку в очередь
+	if err := svc.Redeliver(ctx, 2, 5, 9); !errors.Is(err, repository.ErrWebhookNotFound) {
+		t.Errorf("Redeliver by another owner = %v, want ErrWebhookNotFound", err)
+	}
+	if err := svc.Redeliver(ctx, 1, 5, 404); !errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+		t.Errorf("Redeliver of unknown delivery = %v, want ErrWebhookDeliveryNotFound", err)
+	}
+
+	healthy.Store(true)
+	if err := svc.Redeliver(ctx, 1, 5, 9); err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if d.Status != models.WebhookDeliveryPending || d.Attempts != 0 {
+		t.Fatalf("after redeliver: status %s, attempts %d", d.Status, d.Attempts)
+	}
+
+	n, err := svc.ProcessDeliveries(ctx)
+	if err != nil {
+		t.Fatalf("ProcessDeliveries: %v", err)
+	}
+	if n != 1 || d.Status != models.WebhookDeliveryDelivered || *d.ResponseStatus != http.StatusNoContent {
+		t.Errorf("redelivered: delivered %d, status %s, response %d", n, d.Status, *d.ResponseStatus)
+	}
+}
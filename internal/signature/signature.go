@@ -0,0 +1,73 @@
+// Package signature подписывает и проверяет тела вебхуков: исходящих вебхуков
+// владельцам и входящих вебхуков платежного провайдера
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalid подпись не совпала, устарела или заголовок не разобран
+var ErrInvalid = errors.New("invalid signature")
+
+// Sign формирует заголовок подписи вида "t=<unix>,v1=<hex>".
+// Подписывается строка "<unix>.<payload>" алгоритмом HMAC-SHA256.
+func Sign(secret string, payload []byte, timestamp time.Time) string {
+	ts := timestamp.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", ts, compute(secret, ts, payload))
+}
+
+// Verify проверяет заголовок подписи и что метка времени отличается от now
+// не больше чем на tolerance. Подходит любая из подписей v1 в заголовке.
+func Verify(secret string, payload []byte, header string, now time.Time, tolerance time.Duration) error {
+	var ts int64
+	var signatures []string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalid
+			}
+			ts = parsed
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if ts == 0 || len(signatures) == 0 {
+		return ErrInvalid
+	}
+
+	age := now.Sub(time.Unix(ts, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrInvalid
+	}
+
+	expected := compute(secret, ts, payload)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+
+	return ErrInvalid
+}
+
+func compute(secret string, ts int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
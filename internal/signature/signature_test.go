@@ -0,0 +1,75 @@
+package signature
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_payments"
+	const tolerance = 5 * time.Minute
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	valid := Sign(secret, payload, now)
+	_, validSig, _ := strings.Cut(valid, ",v1=")
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		wantErr bool
+	}{
+		{"valid", payload, valid, false},
+		{"within tolerance", payload, Sign(secret, payload, now.Add(-tolerance+time.Second)), false},
+		{"clock skew within tolerance", payload, Sign(secret, payload, now.Add(tolerance-time.Second)), false},
+		{"spaces after commas", payload, strings.ReplaceAll(valid, ",", ", "), false},
+		{"one of several signatures matches", payload, valid + ",v1=deadbeef", false},
+
+		{"wrong secret", payload, Sign("other", payload, now), true},
+		{"tampered payload", []byte(`{"id":"evt_1","type":"payment.failed"}`), valid, true},
+		{"stale", payload, Sign(secret, payload, now.Add(-tolerance-time.Second)), true},
+		{"from the future", payload, Sign(secret, payload, now.Add(tolerance+time.Second)), true},
+		{"timestamp changed", payload, fmt.Sprintf("t=%d,v1=%s", now.Unix()+1, validSig), true},
+
+		{"empty header", payload, "", true},
+		{"no timestamp", payload, "v1=" + validSig, true},
+		{"no signature", payload, fmt.Sprintf("t=%d", now.Unix()), true},
+		{"non-numeric timestamp", payload, "t=yesterday,v1=" + validSig, true},
+		{"garbage", payload, "not a signature", true},
+		{"unknown scheme only", payload, fmt.Sprintf("t=%d,v0=%s", now.Unix(), validSig), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(secret, tt.payload, tt.header, now, tolerance)
+			if tt.wantErr && !errors.Is(err, ErrInvalid) {
+				t.Errorf("Verify(%q) = %v, want ErrInvalid", tt.header, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Verify(%q) = %v, want nil", tt.header, err)
+			}
+		})
+	}
+}
+
+func TestSignDependsOnSecretAndPayload(t *testing.T) {
+	ts := time.Unix(1_700_000_000, 0)
+	payload := []byte(`{"id":1}`)
+	sig := Sign("secret", payload, ts)
+
+	if !strings.HasPrefix(sig, "t=1700000000,v1=") {
+		t.Errorf("Sign = %q, want t=1700000000,v1=<hex>", sig)
+	}
+	if Sign("other", payload, ts) == sig {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("secret", []byte(`{"id":2}`), ts) == sig {
+		t.Error("signature does not depend on the payload")
+	}
+	if Sign("secret", payload, ts.Add(time.Second)) == sig {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocalConfig настройки хранения файлов на диске сервера
+type LocalConfig struct {
+	BasePath   string // каталог с файлами
+	BaseURL    string // адрес, по которому Handler отдает каталог
+	Private    bool   // отдавать файлы только по подписанным ссылкам
+	SigningKey string // ключ HMAC для подписанных ссылок
+}
+
+// LocalStorage хранит файлы в каталоге на диске. Все операции идут через
+// os.Root, поэтому ключ не может указать на файл вне каталога, в том числе
+// через символические ссылки.
+type LocalStorage struct {
+	root       *os.Root
+	baseURL    string
+	private    bool
+	signingKey []byte
+}
+
+func NewLocalStorage(cfg LocalConfig) (*LocalStorage, error) {
+	if cfg.SigningKey == "" {
+		return nil, errors.New("local storage signing key is required")
+	}
+	if err := os.MkdirAll(cfg.BasePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	root, err := os.OpenRoot(cfg.BasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage directory: %w", err)
+	}
+
+	return &LocalStorage{
+		root:       root,
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		private:    cfg.Private,
+		signingKey: []byte(cfg.SigningKey),
+	}, nil
+}
+
+// UploadFile пишет файл во временный файл рядом с итоговым и переименовывает
+// его только после успешной записи, так что читатели никогда не видят
+// недописанный файл
+func (l *LocalStorage) UploadFile(ctx context.Context, file io.Reader, fileName string, contentType string) (*UploadResult, error) {
+	storageKey := newStorageKey(fileName)
+	dir := path.Dir(storageKey)
+	if err := l.root.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmpName := path.Join(dir, "."+uuid.New().String()+".tmp")
+	tmp, err := l.root.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			_ = l.root.Remove(tmpName)
+		}
+	}()
+
+	fileSize, err := io.Copy(tmp, io.LimitReader(file, maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if fileSize > maxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if fileSize == 0 {
+		return nil, ErrEmptyFile
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := l.root.Rename(tmpName, storageKey); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	committed = true
+
+	return &UploadResult{
+		URL:        l.GetFileURL(storageKey),
+		StorageKey: storageKey,
+		FileName:   fileName,
+		FileSize:   fileSize,
+	}, nil
+}
+
+// DeleteFile удаляет файл. Отсутствующий файл не считается ошибкой, как и в S3.
+func (l *LocalStorage) DeleteFile(ctx context.Context, storageKey string) error {
+	if !validKey(storageKey) {
+		return ErrInvalidKey
+	}
+	if err := l.root.Remove(storageKey); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalStorage) GetFileURL(storageKey string) string {
+	return fmt.Sprintf("%s/%s", l.baseURL, storageKey)
+}
+
+// GetSignedURL возвращает ссылку, которую Handler примет до истечения
+// expiration. Подписывается строка "<expires>.<storageKey>" алгоритмом HMAC-SHA256.
+func (l *LocalStorage) GetSignedURL(storageKey string, expiration time.Duration) (string, error) {
+	if !validKey(storageKey) {
+		return "", ErrInvalidKey
+	}
+
+	expires := time.Now().Add(expiration).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.sign(storageKey, expires))
+
+	return l.GetFileURL(storageKey) + "?" + query.Encode(), nil
+}
+
+// verify проверяет подпись и срок действия ссылки
+func (l *LocalStorage) verify(storageKey string, query url.Values, now time.Time) error {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || now.Unix() > expires {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(l.sign(storageKey, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (l *LocalStorage) sign(storageKey string, expires int64) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	fmt.Fprintf(mac, "%d.%s", expires, storageKey)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handler отдает файлы по ключу из пути запроса; префикс (например /uploads/)
+// нужно снять через http.StripPrefix. Ключи уникальны и файлы не меняются,
+// поэтому публичные ответы кешируются надолго. Ссылка с подписью проверяется
+// всегда, без подписи доступна только если хранилище не приватное.
+func (l *LocalStorage) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		storageKey := r.URL.Path
+		if !validKey(storageKey) || strings.HasPrefix(path.Base(storageKey), ".") {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		signed := query.Has("signature")
+		if signed || l.private {
+			if err := l.verify(storageKey, query, time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+
+		f, err := l.root.Open(storageKey)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		if signed {
+			// Ответ живет не дольше подписи и не оседает в общих кешах
+			expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+			maxAge := max(0, expires-time.Now().Unix())
+			w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+		} else {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		// Content-Type определяется по расширению ключа, иначе по содержимому
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	})
+}
+
+// validKey проверяет, что ключ — относительный путь без "." и ".." в
+// составе. os.Root и так не выпустит за пределы каталога, но явная проверка
+// дает понятную ошибку.
+func validKey(storageKey string) bool {
+	return storageKey != "" && fs.ValidPath(storageKey) && !strings.Contains(storageKey, `\`) && storageKey != "."
+}
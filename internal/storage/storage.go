@@ -1,20 +1,34 @@
 package storage
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/google/uuid"
 )
 
+// maxFileSize предельный размер загружаемого файла
+const maxFileSize = 10 * 1024 * 1024
+
+var (
+	ErrFileTooLarge     = errors.New("file size exceeds 10MB limit")
+	ErrEmptyFile        = errors.New("file is empty")
+	ErrInvalidKey       = errors.New("invalid storage key")
+	ErrInvalidSignature = errors.New("invalid or expired signed URL")
+	ErrFileNotFound     = errors.New("file not found in storage")
+)
+
 type StorageService interface {
 	UploadFile(ctx context.Context, file io.Reader, fileName string, contentType string) (*UploadResult, error)
 	DeleteFile(ctx context.Context, storageKey string) error
@@ -22,6 +36,13 @@ type StorageService interface {
 	GetSignedURL(storageKey string, expiration time.Duration) (string, error)
 }
 
+// DirectUploader хранилище, в которое клиент загружает файл сам по
+// подписанной ссылке, минуя сервер. Поддерживается только S3/MinIO.
+type DirectUploader interface {
+	PresignUpload(storageKey, contentType string, expiration time.Duration) (string, error)
+	OpenFile(ctx context.Context, storageKey string) (io.ReadCloser, error)
+}
+
 type UploadResult struct {
 	URL        string
 	StorageKey string
@@ -41,6 +62,7 @@ type S3Config struct {
 
 type s3Storage struct {
 	client     *s3.S3
+	uploader   *s3manager.Uploader
 	bucketName string
 	publicURL  string
 }
@@ -84,48 +106,43 @@ func NewS3Storage(cfg S3Config) (StorageService, error) {
 
 	return &s3Storage{
 		client:     client,
+		uploader:   s3manager.NewUploaderWithClient(client),
 		bucketName: cfg.BucketName,
 		publicURL:  cfg.PublicURL,
 	}, nil
 }
 
+// UploadFile передает файл в S3 потоком: s3manager читает его частями и при
+// размере больше одной части использует multipart upload, так что в памяти
+// не держится весь файл
 func (s *s3Storage) UploadFile(ctx context.Context, file io.Reader, fileName string, contentType string) (*UploadResult, error) {
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	fileSize := int64(len(data))
-
-	if fileSize > 10*1024*1024 {
-		return nil, fmt.Errorf("file size exceeds 10MB limit")
-	}
+	storageKey := newStorageKey(fileName)
+	body := &sizeLimitedReader{r: file, limit: maxFileSize}
 
-	ext := filepath.Ext(fileName)
-	storageKey := fmt.Sprintf("resources/%s/%s%s",
-		time.Now().Format("2006/01"),
-		uuid.New().String(),
-		ext,
-	)
-
-	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 		Bucket:      aws.String(s.bucketName),
 		Key:         aws.String(storageKey),
-		Body:        bytes.NewReader(data),
+		Body:        body,
 		ContentType: aws.String(contentType),
 		ACL:         aws.String("public-read"),
 	})
+	if body.exceeded {
+		// s3manager прерывает multipart upload сам, но одиночный PUT мог не начаться
+		return nil, ErrFileTooLarge
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file to S3: %w", err)
 	}
-
-	url := s.GetFileURL(storageKey)
+	if body.read == 0 {
+		_ = s.DeleteFile(ctx, storageKey)
+		return nil, ErrEmptyFile
+	}
 
 	return &UploadResult{
-		URL:        url,
+		URL:        s.GetFileURL(storageKey),
 		StorageKey: storageKey,
 		FileName:   fileName,
-		FileSize:   fileSize,
+		FileSize:   body.read,
 	}, nil
 }
 
@@ -161,36 +178,78 @@ func (s *s3Storage) GetSignedURL(storageKey string, expiration time.Duration) (s
 	return url, nil
 }
 
-type localStorage struct {
-	baseURL  string
-	basePath string
+// PresignUpload возвращает ссылку для PUT напрямую в бакет. Content-Type
+// входит в подпись, клиент должен отправить тот же заголовок.
+func (s *s3Storage) PresignUpload(storageKey, contentType string, expiration time.Duration) (string, error) {
+	req, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(storageKey),
+		ContentType: aws.String(contentType),
+	})
+
+	url, err := req.Presign(expiration)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload URL: %w", err)
+	}
+
+	return url, nil
 }
 
-func NewLocalStorage(basePath, baseURL string) StorageService {
-	return &localStorage{
-		basePath: basePath,
-		baseURL:  baseURL,
+func (s *s3Storage) OpenFile(ctx context.Context, storageKey string) (io.ReadCloser, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(storageKey),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && (aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound") {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file from S3: %w", err)
 	}
+	if aws.Int64Value(out.ContentLength) > maxFileSize {
+		out.Body.Close()
+		return nil, ErrFileTooLarge
+	}
+
+	return out.Body, nil
 }
 
-func (l *localStorage) UploadFile(ctx context.Context, file io.Reader, fileName string, contentType string) (*UploadResult, error) {
-	storageKey := fmt.Sprintf("resources/%s/%s", time.Now().Format("2006/01"), fileName)
-	return &UploadResult{
-		URL:        fmt.Sprintf("%s/%s", l.baseURL, storageKey),
-		StorageKey: storageKey,
-		FileName:   fileName,
-		FileSize:   0,
-	}, nil
+// sizeLimitedReader считает прочитанные байты и возвращает ErrFileTooLarge,
+// как только их становится больше limit
+type sizeLimitedReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
 }
 
-func (l *localStorage) DeleteFile(ctx context.Context, storageKey string) error {
-	return nil
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		l.exceeded = true
+		return n, ErrFileTooLarge
+	}
+	return n, err
+}
+
+// newStorageKey генерирует уникальный ключ файла с расширением исходного имени
+func newStorageKey(fileName string) string {
+	return fmt.Sprintf("resources/%s/%s%s",
+		time.Now().Format("2006/01"),
+		uuid.New().String(),
+		strings.ToLower(filepath.Ext(fileName)),
+	)
 }
 
-func (l *localStorage) GetFileURL(storageKey string) string {
-	return fmt.Sprintf("%s/%s", l.baseURL, storageKey)
+// IncomingPrefix каталог, куда клиент загружает файлы по подписанной ссылке
+// до подтверждения; scope отделяет загрузки разных владельцев
+func IncomingPrefix(scope string) string {
+	return fmt.Sprintf("incoming/%s/", scope)
 }
 
-func (l *localStorage) GetSignedURL(storageKey string, expiration time.Duration) (string, error) {
-	return l.GetFileURL(storageKey), nil
+// NewIncomingKey генерирует ключ для прямой загрузки в IncomingPrefix(scope)
+func NewIncomingKey(scope, ext string) string {
+	return IncomingPrefix(scope) + uuid.New().String() + ext
 }
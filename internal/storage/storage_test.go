@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 минимальная замена S3 для path-style запросов SDK: бакет, объекты,
+// multipart upload. Подписи не проверяются, кроме наличия у presigned ссылки.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	uploads map[string]map[int][]byte
+	aborted []string
+	nextID  int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	f := &fakeS3{
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+		uploads: make(map[string]map[int][]byte),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	if key == "" {
+		// HEAD и PUT бакета
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[id] = make(map[int][]byte)
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, bucket, key, id)
+
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		parts[n], _ = io.ReadAll(r.Body)
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, n))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		id := query.Get("uploadId")
+		parts, ok := f.uploads[id]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var data []byte
+		for _, n := range slices.Sorted(maps.Keys(parts)) {
+			data = append(data, parts[n]...)
+		}
+		delete(f.uploads, id)
+		f.objects[key] = data
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>"complete"</ETag></CompleteMultipartUploadResult>`, bucket, key)
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		f.aborted = append(f.aborted, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+		f.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"object"`)
+
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func newTestS3Storage(t *testing.T, endpoint string) *s3Storage {
+	t.Helper()
+	svc, err := NewS3Storage(S3Config{
+		Endpoint:        endpoint,
+		AccessKeyID:     "test",
+		SecretAccessKey: "test",
+		BucketName:      "photos",
+		Region:          "us-east-1",
+		PublicURL:       "http://cdn.example.com",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return svc.(*s3Storage)
+}
+
+func TestS3UploadFileStreams(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s := newTestS3Storage(t, srv.URL)
+
+	// Больше одной части s3manager: файл уходит через multipart upload
+	data := bytes.Repeat([]byte("x"), 6<<20)
+	result, err := s.UploadFile(context.Background(), io.MultiReader(bytes.NewReader(data)), "photo.JPG", "image/jpeg")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if result.FileSize != int64(len(data)) || !strings.HasSuffix(result.StorageKey, ".jpg") {
+		t.Errorf("result = %+v, want %d bytes under a .jpg key", result, len(data))
+	}
+	if !bytes.Equal(fake.objects[result.StorageKey], data) {
+		t.Errorf("stored %d bytes, want %d", len(fake.objects[result.StorageKey]), len(data))
+	}
+}
+
+func TestS3UploadFileRejectsOversizedStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		partSize int64
+	}{
+		{"multipart upload", 0},
+		// Часть больше лимита: лимит срабатывает до единственного PUT
+		{"single part", maxFileSize + 1<<20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, srv := newFakeS3(t)
+			s := newTestS3Storage(t, srv.URL)
+			if tt.partSize > 0 {
+				s.uploader.PartSize = tt.partSize
+			}
+
+			// Поток без Seek и без известной длины, как тело multipart-формы
+			body := io.LimitReader(zeroReader{}, maxFileSize+1)
+			_, err := s.UploadFile(context.Background(), body, "big.png", "image/png")
+			if !errors.Is(err, ErrFileTooLarge) {
+				t.Fatalf("UploadFile = %v, want ErrFileTooLarge", err)
+			}
+			if len(fake.objects) != 0 {
+				t.Errorf("oversized file was stored: %d objects", len(fake.objects))
+			}
+			if len(fake.uploads) != 0 {
+				t.Errorf("multipart uploads left open: %v", fake.uploads)
+			}
+			if tt.partSize == 0 && len(fake.aborted) != 1 {
+				t.Errorf("aborted uploads = %v, want one", fake.aborted)
+			}
+		})
+	}
+}
+
+func TestS3PresignedUploadThenOpen(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s := newTestS3Storage(t, srv.URL)
+	ctx := context.Background()
+	key := NewIncomingKey("resources/1", ".png")
+
+	uploadURL, err := s.PresignUpload(key, "image/png", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignUpload: %v", err)
+	}
+	if !strings.HasPrefix(uploadURL, srv.URL+"/photos/"+key) || !strings.Contains(uploadURL, "X-Amz-Signature=") {
+		t.Fatalf("upload url = %s, want a presigned url for %s", uploadURL, key)
+	}
+	if !strings.Contains(uploadURL, "content-type") {
+		t.Errorf("upload url %s does not sign Content-Type", uploadURL)
+	}
+
+	// Объект еще не загружен
+	if _, err := s.OpenFile(ctx, key); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("OpenFile before upload = %v, want ErrFileNotFound", err)
+	}
+
+	// Клиент загружает файл по ссылке сам
+	req, _ := http.NewRequest(http.MethodPut, uploadURL, strings.NewReader("png bytes"))
+	req.Header.Set("Content-Type", "image/png")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT presigned url: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || fake.types[key] != "image/png" {
+		t.Fatalf("PUT status %d, content type %q", resp.StatusCode, fake.types[key])
+	}
+
+	body, err := s.OpenFile(ctx, key)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	defer body.Close()
+	if data, _ := io.ReadAll(body); string(data) != "png bytes" {
+		t.Errorf("OpenFile read %q", data)
+	}
+}
+
+func TestS3OpenFileRejectsOversizedObject(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s := newTestS3Storage(t, srv.URL)
+
+	// Клиент загрузил по ссылке файл больше лимита
+	key := NewIncomingKey("resources/1", ".png")
+	fake.objects[key] = make([]byte, maxFileSize+1)
+
+	if _, err := s.OpenFile(context.Background(), key); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("OpenFile = %v, want ErrFileTooLarge", err)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
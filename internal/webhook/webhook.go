@@ -0,0 +1,81 @@
+// Package webhook подписывает и отправляет исходящие вебхуки владельцам
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"smartbooking/internal/signature"
+)
+
+// Заголовки исходящего запроса
+const (
+	HeaderEvent     = "X-SmartBooking-Event"
+	HeaderDelivery  = "X-SmartBooking-Delivery"
+	HeaderSignature = "X-SmartBooking-Signature"
+)
+
+// maxResponseBody сколько байт ответа получателя сохраняется в журнал
+const maxResponseBody = 1024
+
+// Request исходящий вебхук
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID int64
+	Payload    []byte
+}
+
+// Response ответ получателя. Status == 0, если ответа не было.
+type Response struct {
+	Status int
+	Body   string
+}
+
+// Client отправляет вебхуки по HTTP
+type Client struct {
+	http *http.Client
+	now  func() time.Time
+}
+
+// NewClient creates a webhook client with the given request timeout
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		http: &http.Client{Timeout: timeout},
+		now:  time.Now,
+	}
+}
+
+// Send отправляет подписанный POST. Ответ не из диапазона 2xx считается ошибкой,
+// но возвращается вместе с ней для журнала доставок.
+func (c *Client) Send(ctx context.Context, req *Request) (*Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return &Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "SmartBooking-Webhooks/1.0")
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, strconv.FormatInt(req.DeliveryID, 10))
+	httpReq.Header.Set(HeaderSignature, signature.Sign(req.Secret, req.Payload, c.now()))
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return &Response{}, err
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBody))
+	resp := &Response{Status: httpResp.StatusCode, Body: string(body)}
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return resp, fmt.Errorf("unexpected response status %d", httpResp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verify проверяет подпись так, как это делает получатель: по заголовку и своему секрету
+func verify(t *testing.T, header, secret string, body []byte) time.Time {
+	t.Helper()
+
+	var ts int64
+	var sig string
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			sig = value
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", ts, body)
+	if !hmac.Equal([]byte(sig), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		t.Fatalf("signature %q does not match payload", header)
+	}
+	return time.Unix(ts, 0)
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"type":"booking.created","id":42}`)
+	sentAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: body}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := NewClient(time.Second)
+	client.now = func() time.Time { return sentAt }
+
+	resp, err := client.Send(context.Background(), &Request{
+		URL:        srv.URL,
+		Secret:     secret,
+		EventType:  "booking.created",
+		DeliveryID: 7,
+		Payload:    payload,
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if resp.Status != http.StatusOK || resp.Body != "ok" {
+		t.Errorf("response = %+v, want 200 ok", resp)
+	}
+
+	r := <-got
+	if string(r.body) != string(payload) {
+		t.Errorf("body = %s, want %s", r.body, payload)
+	}
+	if ev := r.header.Get(HeaderEvent); ev != "booking.created" {
+		t.Errorf("%s = %q", HeaderEvent, ev)
+	}
+	if id := r.header.Get(HeaderDelivery); id != "7" {
+		t.Errorf("%s = %q, want 7", HeaderDelivery, id)
+	}
+	if ts := verify(t, r.header.Get(HeaderSignature), secret, r.body); !ts.Equal(sentAt) {
+		t.Errorf("signature timestamp = %v, want %v", ts, sentAt)
+	}
+}
+
+func TestSendNon2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, strings.Repeat("x", 2*maxResponseBody), http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(time.Second).Send(context.Background(), &Request{URL: srv.URL, Payload: []byte(`{}`)})
+	if err == nil {
+		t.Fatal("Send succeeded on 502")
+	}
+	if resp.Status != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", resp.Status)
+	}
+	if len(resp.Body) != maxResponseBody {
+		t.Errorf("body length = %d, want it capped at %d", len(resp.Body), maxResponseBody)
+	}
+}
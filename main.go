@@ -9,13 +9,22 @@ import (
 
 	"smartbooking/config"
 	_ "smartbooking/docs"
+	"smartbooking/internal/audit"
 	"smartbooking/internal/database"
+	"smartbooking/internal/email"
+	"smartbooking/internal/events"
 	"smartbooking/internal/handler"
+	"smartbooking/internal/ical"
+	"smartbooking/internal/invoice"
 	"smartbooking/internal/logger"
 	"smartbooking/internal/middleware"
+	"smartbooking/internal/moderation"
+	"smartbooking/internal/money"
+	"smartbooking/internal/payment"
 	"smartbooking/internal/repository"
 	"smartbooking/internal/service"
 	"smartbooking/internal/storage"
+	"smartbooking/internal/webhook"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -60,10 +69,21 @@ func main() {
 
 	// Инициализируем storage (S3/MinIO)
 	var storageService storage.StorageService
+	var localStorage *storage.LocalStorage
 	if cfg.Storage.Type == "local" {
-		log.Println("Using local storage")
-		logger.Info("Initializing local storage")
-		storageService = storage.NewLocalStorage("/uploads", "http://localhost:8080/uploads")
+		log.Printf("Using local storage at %s", cfg.Storage.LocalPath)
+		logger.Info("Initializing local storage at %s", cfg.Storage.LocalPath)
+		localStorage, err = storage.NewLocalStorage(storage.LocalConfig{
+			BasePath:   cfg.Storage.LocalPath,
+			BaseURL:    cfg.Storage.LocalURL,
+			Private:    cfg.Storage.LocalPrivate,
+			SigningKey: cfg.Storage.SigningKey,
+		})
+		if err != nil {
+			logger.Error("Failed to initialize storage: %v", err)
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		storageService = localStorage
 	} else {
 		log.Printf("Connecting to %s storage at %s", cfg.Storage.Type, cfg.Storage.Endpoint)
 		logger.Info("Initializing %s storage at %s", cfg.Storage.Type, cfg.Storage.Endpoint)
@@ -84,6 +104,46 @@ func main() {
 		logger.Info("Storage initialized successfully")
 	}
 
+	// Инициализируем платежный провайдер
+	var paymentProvider payment.PaymentProvider
+	var fakePaymentProvider *payment.FakeProvider
+	switch cfg.Payment.Provider {
+	case "fake":
+		log.Println("Using fake in-process payment provider")
+		logger.Info("Initializing fake payment provider")
+		fakePaymentProvider = payment.NewFakeProvider(cfg.Payment.WebhookSecret)
+		paymentProvider = fakePaymentProvider
+	default:
+		log.Fatalf("Unsupported payment provider: %s", cfg.Payment.Provider)
+	}
+
+	// Инициализируем отправку писем
+	var emailSender email.EmailSender
+	switch cfg.Email.Transport {
+	case "log":
+		log.Println("Using log email transport")
+		logger.Info("Initializing log email transport")
+		emailSender = email.NewLogSender(cfg.Email.OutboxDir)
+	case "smtp":
+		log.Printf("Using SMTP email transport at %s:%d", cfg.Email.SMTPHost, cfg.Email.SMTPPort)
+		logger.Info("Initializing SMTP email transport at %s:%d", cfg.Email.SMTPHost, cfg.Email.SMTPPort)
+		emailSender = email.NewSMTPSender(email.SMTPConfig{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+		})
+	default:
+		log.Fatalf("Unsupported email transport: %s", cfg.Email.Transport)
+	}
+	emailRenderer, err := email.NewRenderer()
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+
+	// Курсы для сводной выручки в базовой валюте
+	rates := money.NewRates(cfg.Currency.Base, cfg.Currency.Rates)
+
 	userRepo := repository.NewUserRepository(db.DB)
 	resourceRepo := repository.NewResourceRepository(db.DB)
 	bookingRepo := repository.NewBookingRepository(db.DB)
@@ -92,16 +152,58 @@ func main() {
 	categoryRepo := repository.NewCategoryRepository(db.DB)
 	ownerRepo := repository.NewOwnerRepository(db.DB)
 	adminRepo := repository.NewAdminRepository(db.DB)
-
-	authService := service.NewAuthService(userRepo)
-	userService := service.NewUserService(userRepo)
-	resourceService := service.NewResourceService(resourceRepo)
-	bookingService := service.NewBookingService(bookingRepo, resourceRepo)
-	photoService := service.NewPhotoService(photoRepo, storageService)
-	reviewService := service.NewReviewService(reviewRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
-	ownerService := service.NewOwnerService(ownerRepo)
-	adminService := service.NewAdminService(adminRepo)
+	couponRepo := repository.NewCouponRepository(db.DB)
+	paymentRepo := repository.NewPaymentRepository(db.DB)
+	invoiceRepo := repository.NewInvoiceRepository(db.DB)
+	notificationRepo := repository.NewNotificationRepository(db.DB)
+	emailOutboxRepo := repository.NewEmailOutboxRepository(db.DB)
+	passwordResetRepo := repository.NewPasswordResetRepository(db.DB)
+	eventOutboxRepo := repository.NewEventOutboxRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+	reminderRepo := repository.NewReminderRepository(db.DB)
+	auditRepo := repository.NewAuditRepository(db.DB)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(db.DB)
+	externalCalendarRepo := repository.NewExternalCalendarRepository(db.DB)
+	auditor := audit.NewRecorder(auditRepo)
+	transactor := repository.NewTransactor(db.DB)
+
+	// Доменные события: сервисы пишут их в event_outbox в той же транзакции,
+	// что и изменения данных, диспетчер доставляет их подписчикам шины
+	eventPublisher := events.NewOutboxPublisher(eventOutboxRepo)
+	eventBus := events.NewBus()
+	notificationService := service.NewNotificationService(notificationRepo, resourceRepo, transactor)
+	eventBus.Subscribe("notifications", notificationService.HandleEvent, service.NotificationEvents...)
+	emailService := service.NewEmailService(emailOutboxRepo, userRepo, resourceRepo, emailSender, emailRenderer,
+		cfg.Email.From, cfg.Email.MaxAttempts)
+	eventBus.Subscribe("email", emailService.HandleEvent, service.EmailEvents...)
+	webhookService := service.NewWebhookService(webhookRepo, resourceRepo,
+		webhook.NewClient(time.Duration(cfg.Webhooks.Timeout)*time.Second), cfg.Webhooks.MaxAttempts)
+	eventBus.Subscribe("webhooks", webhookService.HandleEvent, service.WebhookEvents...)
+	eventDispatcher := events.NewDispatcher(eventOutboxRepo, eventBus, cfg.Events.MaxAttempts)
+
+	authService := service.NewAuthService(userRepo, passwordResetRepo, emailService, transactor, auditor,
+		cfg.Email.PasswordResetURL, time.Duration(cfg.Email.PasswordResetTTL)*time.Minute)
+	userService := service.NewUserService(userRepo, transactor, auditor)
+	resourceService := service.NewResourceService(resourceRepo, transactor, eventPublisher, auditor)
+	couponService := service.NewCouponService(couponRepo)
+	bookingService := service.NewBookingService(bookingRepo, resourceRepo, couponService, transactor, eventPublisher, auditor)
+	photoService := service.NewPhotoService(photoRepo, storageService, transactor, auditor, cfg.Photos.MaxPerResource)
+	reviewFilter, err := moderation.NewFilter(cfg.Reviews.BlockedWords, cfg.Reviews.BlockedPattern)
+	if err != nil {
+		log.Fatalf("Failed to configure review moderation: %v", err)
+	}
+	reviewService := service.NewReviewService(reviewRepo, bookingRepo, resourceRepo, storageService, transactor, eventPublisher, auditor,
+		reviewFilter, cfg.Reviews.AllowUnverified, cfg.Reviews.MaxPhotos)
+	categoryService := service.NewCategoryService(categoryRepo, transactor, auditor)
+	ownerService := service.NewOwnerService(ownerRepo, rates)
+	adminService := service.NewAdminService(adminRepo, auditRepo, rates)
+	paymentService := service.NewPaymentService(paymentRepo, bookingRepo, resourceRepo, paymentProvider, transactor, eventPublisher)
+	calendarService := service.NewCalendarService(calendarFeedRepo, bookingRepo, resourceRepo, userRepo)
+	calendarImportService := service.NewCalendarImportService(externalCalendarRepo, resourceRepo, transactor,
+		ical.NewFetcher(time.Duration(cfg.Calendar.FetchTimeout)*time.Second), time.Duration(cfg.Calendar.SyncInterval)*time.Minute)
+	reminderService := service.NewReminderService(reminderRepo, bookingRepo, transactor, eventPublisher, cfg.Reminder.Offsets)
+	invoiceService := service.NewInvoiceService(invoiceRepo, bookingRepo, resourceRepo, userRepo,
+		cfg.Invoice.NumberPrefix, cfg.Invoice.TaxRate)
 
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
@@ -112,6 +214,18 @@ func main() {
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	ownerHandler := handler.NewOwnerHandler(ownerService)
 	adminHandler := handler.NewAdminHandler(adminService)
+	couponHandler := handler.NewCouponHandler(couponService)
+	paymentHandler := handler.NewPaymentHandler(paymentService, fakePaymentProvider)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	calendarImportHandler := handler.NewCalendarImportHandler(calendarImportService)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService, invoice.Seller{
+		Name:    cfg.Invoice.SellerName,
+		Address: cfg.Invoice.SellerAddress,
+		TaxID:   cfg.Invoice.SellerTaxID,
+		TaxName: cfg.Invoice.TaxName,
+	})
 
 	mux := http.NewServeMux()
 
@@ -119,7 +233,7 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-User-ID")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -132,23 +246,58 @@ func main() {
 
 	mux.HandleFunc("POST /api/auth/register", authHandler.Register)
 	mux.HandleFunc("POST /api/auth/login", authHandler.Login)
+	mux.HandleFunc("POST /api/auth/password-reset", authHandler.RequestPasswordReset)
+	mux.HandleFunc("POST /api/auth/password-reset/confirm", authHandler.ResetPassword)
 
 	mux.HandleFunc("GET /api/users", userHandler.List)
 	mux.HandleFunc("GET /api/users/{id}", userHandler.GetByID)
 	mux.HandleFunc("GET /api/users/{id}/bookings", bookingHandler.ListByUser)
+	mux.HandleFunc("GET /api/users/{id}/bookings.ics", calendarHandler.UserFeed)
+	mux.HandleFunc("POST /api/users/{id}/calendar-feed", calendarHandler.CreateUserFeed)
+	mux.HandleFunc("GET /api/users/{id}/notifications", notificationHandler.List)
+	mux.HandleFunc("GET /api/users/{id}/notifications/unread-count", notificationHandler.CountUnread)
+	mux.HandleFunc("POST /api/users/{id}/notifications/{notification_id}/read", notificationHandler.MarkRead)
+	mux.HandleFunc("POST /api/users/{id}/notifications/read-all", notificationHandler.MarkAllRead)
 
 	mux.HandleFunc("GET /api/resources", resourceHandler.List)
 	mux.HandleFunc("POST /api/resources", resourceHandler.Create)
 	mux.HandleFunc("GET /api/resources/{id}", resourceHandler.GetByID)
+	mux.HandleFunc("PUT /api/resources/{id}", resourceHandler.Update)
 	mux.HandleFunc("DELETE /api/resources/{id}", resourceHandler.Delete)
+	mux.HandleFunc("GET /api/resources/{id}/calendar.ics", calendarHandler.ResourceFeed)
+	mux.HandleFunc("POST /api/resources/{id}/calendar-feed", calendarHandler.CreateResourceFeed)
+	mux.HandleFunc("GET /api/resources/{id}/external-calendars", calendarImportHandler.List)
+	mux.HandleFunc("POST /api/resources/{id}/external-calendars", calendarImportHandler.AddURL)
+	mux.HandleFunc("POST /api/resources/{id}/external-calendars/upload", calendarImportHandler.Upload)
+	mux.HandleFunc("POST /api/resources/{id}/external-calendars/{calendar_id}/sync", calendarImportHandler.Sync)
+	mux.HandleFunc("DELETE /api/resources/{id}/external-calendars/{calendar_id}", calendarImportHandler.Delete)
+	mux.HandleFunc("GET /api/resources/{id}/blocks", calendarImportHandler.ListBlocks)
 
 	mux.HandleFunc("GET /api/bookings", bookingHandler.ListAll)
 	mux.HandleFunc("POST /api/bookings", bookingHandler.Create)
+	mux.HandleFunc("POST /api/bookings/quote", bookingHandler.Quote)
 	mux.HandleFunc("GET /api/bookings/{id}", bookingHandler.GetByID)
 	mux.HandleFunc("POST /api/bookings/{id}/cancel", bookingHandler.Cancel)
+	mux.HandleFunc("GET /api/bookings/{id}/payments", paymentHandler.ListByBooking)
+	mux.HandleFunc("POST /api/bookings/{id}/payments", paymentHandler.CreateForBooking)
+	mux.HandleFunc("GET /api/bookings/{id}/payment-schedule", paymentHandler.GetSchedule)
+	mux.HandleFunc("GET /api/bookings/{id}/invoice", invoiceHandler.GetForBooking)
+
+	mux.HandleFunc("POST /api/payments/webhook", paymentHandler.Webhook)
+	mux.HandleFunc("GET /api/payments/{id}", paymentHandler.GetByID)
+	mux.HandleFunc("POST /api/payments/{id}/capture", paymentHandler.Capture)
+	mux.HandleFunc("POST /api/payments/{id}/refund", paymentHandler.Refund)
+	if fakePaymentProvider != nil {
+		mux.HandleFunc("POST /api/payments/{id}/simulate", paymentHandler.Simulate)
+	}
 
 	mux.HandleFunc("POST /api/photos/upload", photoHandler.UploadPhoto)
+	mux.HandleFunc("POST /api/photos/upload-url", photoHandler.CreateUploadURL)
+	mux.HandleFunc("POST /api/photos/confirm", photoHandler.ConfirmUpload)
 	mux.HandleFunc("GET /api/resources/{resource_id}/photos", photoHandler.GetResourcePhotos)
+	mux.HandleFunc("POST /api/resources/{id}/photos", photoHandler.UploadPhotos)
+	mux.HandleFunc("PUT /api/resources/{id}/photos/order", photoHandler.SetPhotoOrder)
+	mux.HandleFunc("PUT /api/photos/{id}", photoHandler.UpdatePhotoDetails)
 	mux.HandleFunc("DELETE /api/photos/{id}", photoHandler.DeletePhoto)
 	mux.HandleFunc("PUT /api/photos/{id}/primary", photoHandler.SetPrimaryPhoto)
 
@@ -157,8 +306,14 @@ func main() {
 	mux.HandleFunc("GET /api/reviews/{id}", reviewHandler.GetByID)
 	mux.HandleFunc("PUT /api/reviews/{id}", reviewHandler.Update)
 	mux.HandleFunc("DELETE /api/reviews/{id}", reviewHandler.Delete)
+	mux.HandleFunc("PUT /api/reviews/{id}/reply", reviewHandler.Reply)
+	mux.HandleFunc("POST /api/reviews/{id}/reports", reviewHandler.Report)
+	mux.HandleFunc("POST /api/reviews/{id}/helpful", reviewHandler.MarkHelpful)
+	mux.HandleFunc("DELETE /api/reviews/{id}/helpful", reviewHandler.UnmarkHelpful)
+	mux.HandleFunc("POST /api/reviews/{id}/photos", reviewHandler.UploadPhoto)
+	mux.HandleFunc("DELETE /api/reviews/{id}/photos/{photo_id}", reviewHandler.DeletePhoto)
 	mux.HandleFunc("GET /api/resources/{resource_id}/reviews", reviewHandler.GetByResource)
-	mux.HandleFunc("GET /api/resources/{resource_id}/rating", reviewHandler.GetResourceAverageRating)
+	mux.HandleFunc("GET /api/resources/{resource_id}/rating", reviewHandler.GetRatingSummary)
 	mux.HandleFunc("GET /api/users/{user_id}/reviews", reviewHandler.GetByUser)
 
 	mux.HandleFunc("GET /api/categories", categoryHandler.List)
@@ -167,15 +322,33 @@ func main() {
 	mux.HandleFunc("PUT /api/categories/{id}", categoryHandler.Update)
 	mux.HandleFunc("DELETE /api/categories/{id}", categoryHandler.Delete)
 
+	mux.HandleFunc("GET /api/coupons", couponHandler.List)
+	mux.HandleFunc("POST /api/coupons", couponHandler.Create)
+	mux.HandleFunc("GET /api/coupons/{id}", couponHandler.GetByID)
+	mux.HandleFunc("PUT /api/coupons/{id}", couponHandler.Update)
+	mux.HandleFunc("DELETE /api/coupons/{id}", couponHandler.Delete)
+
 	mux.HandleFunc("GET /api/owners/{id}/resources", ownerHandler.GetOwnerResources)
 	mux.HandleFunc("GET /api/owners/{id}/bookings", ownerHandler.GetOwnerBookings)
 	mux.HandleFunc("GET /api/owners/{id}/statistics", ownerHandler.GetOwnerStatistics)
+	mux.HandleFunc("GET /api/owners/{id}/calendar", ownerHandler.GetOwnerCalendar)
+	mux.HandleFunc("GET /api/owners/{id}/invoices", invoiceHandler.ListByOwner)
+	mux.HandleFunc("GET /api/owners/{id}/webhooks", webhookHandler.List)
+	mux.HandleFunc("POST /api/owners/{id}/webhooks", webhookHandler.Create)
+	mux.HandleFunc("DELETE /api/owners/{id}/webhooks/{webhook_id}", webhookHandler.Delete)
+	mux.HandleFunc("GET /api/owners/{id}/webhooks/{webhook_id}/deliveries", webhookHandler.ListDeliveries)
+	mux.HandleFunc("POST /api/owners/{id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", webhookHandler.Redeliver)
 
 	mux.HandleFunc("GET /api/admin/statistics", adminHandler.GetSystemStatistics)
 	mux.HandleFunc("GET /api/admin/bookings/by-status", adminHandler.GetBookingsByStatus)
 	mux.HandleFunc("GET /api/admin/resources/by-category", adminHandler.GetResourcesByCategory)
 	mux.HandleFunc("GET /api/admin/revenue/by-month", adminHandler.GetRevenueByMonth)
 	mux.HandleFunc("GET /api/admin/bookings/by-day", adminHandler.GetBookingsByDay)
+	mux.HandleFunc("GET /api/admin/coupons/usage", adminHandler.GetCouponUsage)
+	mux.HandleFunc("GET /api/admin/audit-logs", adminHandler.GetAuditLogs)
+	mux.HandleFunc("GET /api/admin/reviews/moderation", reviewHandler.ModerationQueue)
+	mux.HandleFunc("POST /api/admin/reviews/{id}/approve", reviewHandler.Approve)
+	mux.HandleFunc("POST /api/admin/reviews/{id}/hide", reviewHandler.Hide)
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -183,33 +356,64 @@ func main() {
 	})
 
 	mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
+	if localStorage != nil {
+		mux.Handle("GET /uploads/", http.StripPrefix("/uploads/", localStorage.Handler()))
+	}
 
 	go startStatisticsWorker(bookingService, resourceService, userService)
+	go startEmailWorker(emailService, time.Duration(cfg.Email.PollInterval)*time.Second)
+	go startEventDispatcher(eventDispatcher, time.Duration(cfg.Events.PollInterval)*time.Second)
+	go startWebhookWorker(webhookService, time.Duration(cfg.Webhooks.PollInterval)*time.Second)
+	go startReminderScheduler(reminderService, time.Duration(cfg.Reminder.PollInterval)*time.Second)
+	go startCalendarSyncWorker(calendarImportService, time.Minute)
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("SmartBooking server starting on %s", addr)
 	log.Printf("Available endpoints:")
 	log.Printf("  POST /api/auth/register              - Register new user")
 	log.Printf("  POST /api/auth/login                 - User login")
+	log.Printf("  POST /api/auth/password-reset        - Request password reset email")
 	log.Printf("  GET  /api/users                      - List all users")
 	log.Printf("  GET  /api/users/{id}                 - Get user by ID")
+	log.Printf("  GET  /api/users/{id}/notifications   - User notifications")
+	log.Printf("  GET  /api/users/{id}/bookings.ics    - User bookings calendar feed")
 	log.Printf("  GET  /api/resources                  - List all resources")
 	log.Printf("  POST /api/resources                  - Create resource")
+	log.Printf("  PUT  /api/resources/{id}             - Update resource")
+	log.Printf("  GET  /api/resources/{id}/calendar.ics - Resource calendar feed")
+	log.Printf("  POST /api/resources/{id}/external-calendars - Import external iCal calendar")
 	log.Printf("  GET  /api/bookings                   - List all bookings")
 	log.Printf("  POST /api/bookings                   - Create booking")
+	log.Printf("  POST /api/bookings/quote             - Quote booking price")
+	log.Printf("  GET  /api/coupons                    - List promo codes")
+	log.Printf("  POST /api/bookings/{id}/payments     - Pay for booking")
+	log.Printf("  GET  /api/bookings/{id}/payment-schedule - Deposit and balance schedule")
+	log.Printf("  GET  /api/bookings/{id}/invoice      - Booking invoice (html/pdf/json)")
+	log.Printf("  POST /api/payments/webhook           - Payment provider webhook")
 	log.Printf("  POST /api/photos/upload              - Upload photo")
+	log.Printf("  POST /api/photos/upload-url          - Presigned URL for direct upload")
+	log.Printf("  POST /api/photos/confirm             - Register a directly uploaded photo")
 	log.Printf("  GET  /api/resources/{id}/photos      - Get resource photos")
+	log.Printf("  POST /api/resources/{id}/photos      - Upload several photos")
+	log.Printf("  PUT  /api/resources/{id}/photos/order - Reorder resource photos")
+	log.Printf("  PUT  /api/photos/{id}                - Update photo caption and alt text")
 	log.Printf("  DELETE /api/photos/{id}              - Delete photo")
 	log.Printf("  GET  /api/owners/{id}/resources      - Get owner's resources")
 	log.Printf("  GET  /api/owners/{id}/bookings       - Get owner's bookings")
 	log.Printf("  GET  /api/owners/{id}/statistics     - Get owner's statistics")
+	log.Printf("  GET  /api/owners/{id}/calendar       - Owner calendar by resource and day")
+	log.Printf("  GET  /api/owners/{id}/invoices       - Get owner's invoices")
+	log.Printf("  POST /api/owners/{id}/webhooks       - Subscribe to webhooks")
 	log.Printf("  GET  /health                         - Health check")
 	log.Printf("  GET  /swagger/                       - API documentation")
+	if localStorage != nil {
+		log.Printf("  GET  /uploads/{key}                  - Uploaded files")
+	}
 	if cfg.Storage.Type == "minio" {
 		log.Printf("  MinIO Console: http://localhost:9001 (admin/admin)")
 	}
 
 	// Apply logging middleware
-	handler := middleware.LoggingMiddleware(corsMiddleware(mux))
+	handler := middleware.LoggingMiddleware(corsMiddleware(middleware.AuditMiddleware(mux)))
 
 	logger.Info("SmartBooking server starting on %s", addr)
 	if err := http.ListenAndServe(addr, handler); err != nil {
@@ -242,3 +446,108 @@ func startStatisticsWorker(bookingService service.BookingService, resourceServic
 			len(users), len(resources), len(bookings), activeBookings)
 	}
 }
+
+// startEmailWorker отправляет письма из очереди email_outbox
+func startEmailWorker(emailService service.EmailService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Background email worker started")
+
+	for range ticker.C {
+		ctx := context.Background()
+
+		sent, err := emailService.ProcessOutbox(ctx)
+		if err != nil {
+			logger.Error("Email outbox processing failed: %v", err)
+			continue
+		}
+		if sent > 0 {
+			logger.Info("Email outbox: sent %d message(s)", sent)
+		}
+	}
+}
+
+// startEventDispatcher доставляет события из event_outbox подписчикам
+func startEventDispatcher(dispatcher *events.Dispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Background event dispatcher started")
+
+	for range ticker.C {
+		ctx := context.Background()
+
+		dispatched, err := dispatcher.DispatchPending(ctx)
+		if err != nil {
+			logger.Error("Event outbox dispatch failed: %v", err)
+			continue
+		}
+		if dispatched > 0 {
+			logger.Info("Event outbox: dispatched %d event(s)", dispatched)
+		}
+	}
+}
+
+// startWebhookWorker отправляет исходящие вебхуки из журнала доставок
+func startWebhookWorker(webhookService service.WebhookService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Background webhook worker started")
+
+	for range ticker.C {
+		ctx := context.Background()
+
+		delivered, err := webhookService.ProcessDeliveries(ctx)
+		if err != nil {
+			logger.Error("Webhook delivery processing failed: %v", err)
+			continue
+		}
+		if delivered > 0 {
+			logger.Info("Webhooks: delivered %d request(s)", delivered)
+		}
+	}
+}
+
+// startReminderScheduler публикует напоминания о предстоящих бронированиях
+func startReminderScheduler(reminderService service.ReminderService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Background reminder scheduler started")
+
+	for range ticker.C {
+		ctx := context.Background()
+
+		sent, err := reminderService.SendDue(ctx)
+		if err != nil {
+			logger.Error("Booking reminders failed: %v", err)
+			continue
+		}
+		if sent > 0 {
+			logger.Info("Booking reminders: scheduled %d reminder(s)", sent)
+		}
+	}
+}
+
+// startCalendarSyncWorker перезагружает внешние календари, подошедшие к синхронизации
+func startCalendarSyncWorker(importService service.CalendarImportService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Background calendar sync worker started")
+
+	for range ticker.C {
+		ctx := context.Background()
+
+		synced, err := importService.SyncDue(ctx)
+		if err != nil {
+			logger.Error("External calendar sync failed: %v", err)
+			continue
+		}
+		if synced > 0 {
+			logger.Info("External calendars: synced %d calendar(s)", synced)
+		}
+	}
+}
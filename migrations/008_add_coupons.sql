@@ -0,0 +1,75 @@
+-- Промокоды и купоны на скидку
+
+-- Таблица купонов
+CREATE TABLE IF NOT EXISTS coupons (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE CHECK (LENGTH(TRIM(code)) > 0),
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    max_uses INT CHECK (max_uses > 0),
+    max_uses_per_user INT CHECK (max_uses_per_user > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Процентная скидка не может превышать 100%
+    CONSTRAINT chk_coupon_percent CHECK (discount_type <> 'percent' OR discount_value <= 100),
+
+    CONSTRAINT chk_coupon_validity CHECK (valid_until IS NULL OR valid_from IS NULL OR valid_until > valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupons_code ON coupons(code);
+CREATE INDEX IF NOT EXISTS idx_coupons_is_active ON coupons(is_active);
+
+CREATE TRIGGER set_timestamp_coupons
+    BEFORE UPDATE ON coupons
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE coupons IS 'Промокоды для скидок на бронирования';
+COMMENT ON COLUMN coupons.discount_type IS 'Тип скидки: percent (процент) или fixed (фиксированная сумма)';
+COMMENT ON COLUMN coupons.max_uses IS 'Общий лимит использований (NULL = без ограничений)';
+COMMENT ON COLUMN coupons.max_uses_per_user IS 'Лимит использований одним пользователем (NULL = без ограничений)';
+
+-- Ограничение купона по категориям
+CREATE TABLE IF NOT EXISTS coupon_categories (
+    coupon_id INT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    category_id INT NOT NULL REFERENCES resource_categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+COMMENT ON TABLE coupon_categories IS 'Категории, на которые действует купон (пусто = все)';
+
+-- Ограничение купона по ресурсам
+CREATE TABLE IF NOT EXISTS coupon_resources (
+    coupon_id INT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    resource_id INT NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, resource_id)
+);
+
+COMMENT ON TABLE coupon_resources IS 'Ресурсы, на которые действует купон (пусто = все)';
+
+-- История применения купонов
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id INT NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    discount_amount DECIMAL(10, 2) NOT NULL CHECK (discount_amount >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(booking_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_redemptions_coupon ON coupon_redemptions(coupon_id);
+CREATE INDEX IF NOT EXISTS idx_redemptions_coupon_user ON coupon_redemptions(coupon_id, user_id);
+
+COMMENT ON TABLE coupon_redemptions IS 'Применения промокодов к бронированиям';
+
+-- Скидка, примененная к бронированию
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN bookings.discount_amount IS 'Сумма скидки по промокоду (total_price уже учитывает скидку)';
@@ -0,0 +1,49 @@
+-- Платежи по бронированиям
+
+-- Ресурсы, для которых бронирование подтверждается только после оплаты
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS requires_prepayment BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN resources.requires_prepayment IS 'Бронирование подтверждается только после успешной оплаты';
+
+-- Таблица платежей
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    provider_payment_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'KZT',
+    status VARCHAR(30) NOT NULL DEFAULT 'requires_payment'
+        CHECK (status IN ('requires_payment', 'requires_capture', 'succeeded', 'failed', 'canceled', 'partially_refunded', 'refunded')),
+    failure_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_payment_refund CHECK (refunded_amount <= amount),
+    UNIQUE(provider, provider_payment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_booking ON payments(booking_id);
+CREATE INDEX IF NOT EXISTS idx_payments_status ON payments(status);
+
+CREATE TRIGGER set_timestamp_payments
+    BEFORE UPDATE ON payments
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE payments IS 'Платежи по бронированиям';
+COMMENT ON COLUMN payments.provider_payment_id IS 'ID платежного намерения у провайдера';
+
+-- Обработанные вебхуки провайдера (защита от повторной обработки)
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (provider, event_id)
+);
+
+COMMENT ON TABLE payment_webhook_events IS 'Журнал вебхуков платежного провайдера';
@@ -0,0 +1,55 @@
+-- Депозиты и частичная предоплата
+
+-- Настройки депозита для ресурса
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS deposit_percent DECIMAL(5, 2)
+    CHECK (deposit_percent > 0 AND deposit_percent < 100);
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS balance_due_hours INT NOT NULL DEFAULT 0
+    CHECK (balance_due_hours >= 0);
+
+COMMENT ON COLUMN resources.deposit_percent IS 'Процент депозита при бронировании (NULL = полная оплата)';
+COMMENT ON COLUMN resources.balance_due_hours IS 'За сколько часов до начала нужно доплатить остаток';
+
+-- Оплаченная сумма по бронированию (остаток = total_price - amount_paid)
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(10, 2) NOT NULL DEFAULT 0
+    CHECK (amount_paid >= 0);
+
+COMMENT ON COLUMN bookings.amount_paid IS 'Фактически оплаченная сумма за вычетом возвратов';
+
+-- График платежей по бронированию
+CREATE TABLE IF NOT EXISTS booking_installments (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('deposit', 'balance', 'full')),
+    amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+    due_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'refunded')),
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(booking_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_installments_booking ON booking_installments(booking_id);
+CREATE INDEX IF NOT EXISTS idx_installments_due ON booking_installments(status, due_at);
+
+CREATE TRIGGER set_timestamp_installments
+    BEFORE UPDATE ON booking_installments
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE booking_installments IS 'График платежей: депозит и остаток или полная оплата';
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS installment_id BIGINT REFERENCES booking_installments(id) ON DELETE SET NULL;
+
+-- Уже оплаченные бронирования считаем погашенными
+UPDATE bookings b
+SET amount_paid = COALESCE((
+    SELECT SUM(p.amount - p.refunded_amount)
+    FROM payments p
+    WHERE p.booking_id = b.id AND p.status IN ('succeeded', 'partially_refunded', 'refunded')
+), 0);
+
+-- Конференц-залы: депозит 30%, остаток за сутки до начала
+UPDATE resources SET deposit_percent = 30.00, balance_due_hours = 24
+WHERE category_id IN (SELECT id FROM resource_categories WHERE slug = 'conference');
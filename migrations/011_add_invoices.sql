@@ -0,0 +1,43 @@
+-- Счета (инвойсы) по бронированиям
+
+-- Сквозная нумерация счетов
+CREATE SEQUENCE IF NOT EXISTS invoice_number_seq START 1;
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGSERIAL PRIMARY KEY,
+    number VARCHAR(50) NOT NULL UNIQUE,
+    booking_id INT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE RESTRICT,
+    resource_id INT REFERENCES resources(id) ON DELETE SET NULL,
+    owner_id INT REFERENCES users(id) ON DELETE SET NULL,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_email VARCHAR(255) NOT NULL,
+    resource_name VARCHAR(255) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'KZT',
+    subtotal DECIMAL(10, 2) NOT NULL,
+    discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(10, 2) NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_owner ON invoices(owner_id, issued_at DESC);
+
+COMMENT ON TABLE invoices IS 'Счета по подтвержденным или оплаченным бронированиям (снимок на момент выставления)';
+COMMENT ON COLUMN invoices.tax_amount IS 'Налог, включенный в итоговую сумму';
+
+-- Позиции счета
+CREATE TABLE IF NOT EXISTS invoice_items (
+    id BIGSERIAL PRIMARY KEY,
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    description VARCHAR(500) NOT NULL,
+    quantity DECIMAL(10, 2) NOT NULL DEFAULT 1,
+    unit_price DECIMAL(10, 2) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+
+    UNIQUE(invoice_id, position)
+);
+
+COMMENT ON TABLE invoice_items IS 'Позиции счета: аренда, скидки';
@@ -0,0 +1,27 @@
+-- Мультивалютность: валюта ресурса, бронирования и фиксированных промокодов
+
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'KZT'
+    CHECK (currency ~ '^[A-Z]{3}$');
+
+COMMENT ON COLUMN resources.currency IS 'Валюта цены ресурса (ISO 4217)';
+
+-- Валюта фиксируется в бронировании на момент создания
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'KZT'
+    CHECK (currency ~ '^[A-Z]{3}$');
+
+UPDATE bookings b
+SET currency = r.currency
+FROM resources r
+WHERE b.resource_id = r.id;
+
+COMMENT ON COLUMN bookings.currency IS 'Валюта суммы бронирования (ISO 4217)';
+
+CREATE INDEX IF NOT EXISTS idx_bookings_currency ON bookings(currency);
+
+-- Фиксированная скидка задается в конкретной валюте
+ALTER TABLE coupons ADD COLUMN IF NOT EXISTS currency VARCHAR(3)
+    CHECK (currency ~ '^[A-Z]{3}$');
+
+UPDATE coupons SET currency = 'KZT' WHERE discount_type = 'fixed' AND currency IS NULL;
+
+COMMENT ON COLUMN coupons.currency IS 'Валюта фиксированной скидки (NULL для процентных купонов)';
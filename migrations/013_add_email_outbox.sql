@@ -0,0 +1,49 @@
+-- Email-уведомления: язык пользователя, исходящая очередь писем и сброс пароля
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(5) NOT NULL DEFAULT 'ru'
+    CHECK (locale IN ('ru', 'en'));
+
+COMMENT ON COLUMN users.locale IS 'Язык писем и уведомлений: ru или en';
+
+-- Очередь исходящих писем. Письмо сначала сохраняется здесь, а отправляет его
+-- фоновый воркер с повторными попытками, чтобы медленный SMTP не блокировал запросы.
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    to_address VARCHAR(255) NOT NULL,
+    subject VARCHAR(500) NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL DEFAULT '',
+    template VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(next_attempt_at) WHERE status = 'pending';
+
+CREATE TRIGGER set_timestamp_email_outbox
+    BEFORE UPDATE ON email_outbox
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE email_outbox IS 'Исходящие письма, отправляемые фоновым воркером';
+COMMENT ON COLUMN email_outbox.next_attempt_at IS 'Время следующей попытки (экспоненциальная задержка после ошибок)';
+
+-- Одноразовые токены сброса пароля; хранится только SHA-256 хеш токена
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens(user_id);
+
+COMMENT ON TABLE password_reset_tokens IS 'Токены сброса пароля (SHA-256 хеш, одноразовые)';
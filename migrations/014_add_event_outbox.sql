@@ -0,0 +1,23 @@
+-- Транзакционный outbox доменных событий.
+-- Событие записывается в одной транзакции с изменением данных, а фоновый
+-- диспетчер доставляет его подписчикам (at-least-once).
+
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'dispatched', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    completed_handlers TEXT[] NOT NULL DEFAULT '{}',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    dispatched_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_due ON event_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_event_outbox_type ON event_outbox(event_type, created_at DESC);
+
+COMMENT ON TABLE event_outbox IS 'Доменные события, ожидающие доставки подписчикам';
+COMMENT ON COLUMN event_outbox.completed_handlers IS 'Подписчики, уже успешно обработавшие событие (не вызываются повторно)';
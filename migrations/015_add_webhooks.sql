@@ -0,0 +1,57 @@
+-- Исходящие вебхуки: владельцы подписывают свои системы (CRM, замки и т.п.)
+-- на доменные события. Каждая доставка сохраняется в журнал и повторяется
+-- фоновым воркером с экспоненциальной задержкой.
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_owner ON webhook_subscriptions(owner_id);
+
+CREATE TRIGGER set_timestamp_webhook_subscriptions
+    BEFORE UPDATE ON webhook_subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE webhook_subscriptions IS 'Подписки владельцев на исходящие вебхуки';
+COMMENT ON COLUMN webhook_subscriptions.secret IS 'Ключ HMAC-SHA256 для заголовка X-SmartBooking-Signature';
+COMMENT ON COLUMN webhook_subscriptions.event_types IS 'Типы событий; пустой массив — все события';
+
+-- Журнал доставок. Одно событие доставляется в подписку не более одного раза
+-- (повторная обработка события диспетчером не создает дубликат).
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    response_status INT,
+    response_body TEXT,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+
+CREATE TRIGGER set_timestamp_webhook_deliveries
+    BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE webhook_deliveries IS 'Журнал доставок исходящих вебхуков';
+COMMENT ON COLUMN webhook_deliveries.response_body IS 'Начало ответа получателя (для диагностики)';
@@ -0,0 +1,17 @@
+-- Напоминания о бронированиях. Строка фиксирует, что напоминание с данным
+-- смещением уже запланировано; уникальный ключ гарантирует, что даже при
+-- нескольких экземплярах приложения оно отправится ровно один раз.
+
+CREATE TABLE IF NOT EXISTS booking_reminders (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    offset_minutes INT NOT NULL CHECK (offset_minutes > 0),
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (booking_id, offset_minutes)
+);
+
+-- Поиск предстоящих бронирований планировщиком
+CREATE INDEX IF NOT EXISTS idx_bookings_upcoming ON bookings(start_time) WHERE status <> 'cancelled';
+
+COMMENT ON TABLE booking_reminders IS 'Отправленные напоминания о бронированиях (по одному на смещение)';
+COMMENT ON COLUMN booking_reminders.offset_minutes IS 'За сколько минут до начала бронирования отправлено напоминание';
@@ -0,0 +1,15 @@
+-- Секретные токены для подписки на календари (.ics). Календарные клиенты не
+-- умеют передавать заголовки авторизации, поэтому доступ к ленте дает токен в URL.
+-- Хранится только SHA-256 хеш токена; новый токен заменяет прежний.
+
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('user', 'resource')),
+    entity_id INT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (scope, entity_id)
+);
+
+COMMENT ON TABLE calendar_feed_tokens IS 'Токены доступа к календарным лентам пользователей и ресурсов';
+COMMENT ON COLUMN calendar_feed_tokens.scope IS 'user — бронирования пользователя, resource — бронирования ресурса';
@@ -0,0 +1,53 @@
+-- Импорт внешних календарей (Airbnb, Booking.com, Google и т.п.).
+-- События внешних календарей становятся блокировками ресурса, которые
+-- учитываются при проверке пересечения бронирований.
+
+CREATE TABLE IF NOT EXISTS external_calendars (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id INT NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(20) NOT NULL CHECK (source IN ('url', 'upload')),
+    url VARCHAR(2048),
+    next_sync_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_synced_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_external_calendar_url CHECK (source <> 'url' OR url IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_calendars_resource ON external_calendars(resource_id);
+CREATE INDEX IF NOT EXISTS idx_external_calendars_due ON external_calendars(next_sync_at) WHERE source = 'url';
+
+CREATE TRIGGER set_timestamp_external_calendars
+    BEFORE UPDATE ON external_calendars
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE external_calendars IS 'Внешние календари ресурса: подписка по URL или загруженный .ics файл';
+COMMENT ON COLUMN external_calendars.next_sync_at IS 'Время следующей синхронизации для source = url';
+
+-- Периоды, когда ресурс недоступен для бронирования
+CREATE TABLE IF NOT EXISTS resource_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id INT NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    calendar_id BIGINT REFERENCES external_calendars(id) ON DELETE CASCADE,
+    uid VARCHAR(500) NOT NULL,
+    summary VARCHAR(500) NOT NULL DEFAULT '',
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_resource_block_times CHECK (end_time > start_time),
+    UNIQUE (calendar_id, uid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_blocks_time ON resource_blocks(resource_id, start_time, end_time);
+
+CREATE TRIGGER set_timestamp_resource_blocks
+    BEFORE UPDATE ON resource_blocks
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE resource_blocks IS 'Блокировки ресурса (занятость во внешних календарях)';
+COMMENT ON COLUMN resource_blocks.uid IS 'UID события во внешнем календаре; по нему блокировки сверяются при повторной синхронизации';
@@ -0,0 +1,29 @@
+-- Часовой пояс ресурса и хранение моментов времени с учетом часового пояса.
+-- TIMESTAMP отбрасывал смещение из RFC3339: бронирование из UTC+5 сохранялось
+-- сдвинутым. Существующие значения считаются записанными в UTC.
+
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+COMMENT ON COLUMN resources.timezone IS 'Часовой пояс IANA (Europe/Moscow, Asia/Almaty); в нем заданы расписание, тарифы и отчеты по дням';
+COMMENT ON COLUMN resource_schedules.open_time IS 'Местное время в часовом поясе ресурса';
+COMMENT ON COLUMN resource_schedules.close_time IS 'Местное время в часовом поясе ресурса';
+COMMENT ON COLUMN resource_pricing.time_from IS 'Местное время в часовом поясе ресурса';
+COMMENT ON COLUMN resource_pricing.time_to IS 'Местное время в часовом поясе ресурса';
+
+-- Все столбцы TIMESTAMP переводятся в TIMESTAMPTZ
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+            AND data_type = 'timestamp without time zone'
+    LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMPTZ USING %I AT TIME ZONE ''UTC''',
+            col.table_name, col.column_name, col.column_name
+        );
+    END LOOP;
+END $$;
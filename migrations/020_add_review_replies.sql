@@ -0,0 +1,18 @@
+-- Публичные ответы владельцев ресурсов на отзывы
+
+CREATE TABLE IF NOT EXISTS review_replies (
+    id BIGSERIAL PRIMARY KEY,
+    review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(review_id)
+);
+
+CREATE TRIGGER set_timestamp_review_replies
+    BEFORE UPDATE ON review_replies
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE review_replies IS 'Ответы владельцев ресурсов на отзывы, не больше одного на отзыв';
@@ -0,0 +1,28 @@
+-- Модерация отзывов: статусы и жалобы пользователей
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_review_status') THEN
+        ALTER TABLE reviews ADD CONSTRAINT chk_review_status CHECK (status IN ('published', 'pending', 'hidden'));
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_pending ON reviews(created_at) WHERE status = 'pending';
+
+COMMENT ON COLUMN reviews.status IS 'published - виден всем, pending - ждет модерации, hidden - скрыт модератором';
+
+CREATE TABLE IF NOT EXISTS review_reports (
+    id BIGSERIAL PRIMARY KEY,
+    review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMPTZ,
+    UNIQUE(review_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_reports_open ON review_reports(review_id) WHERE resolved_at IS NULL;
+
+COMMENT ON TABLE review_reports IS 'Жалобы пользователей на отзывы';
+COMMENT ON COLUMN review_reports.resolved_at IS 'Время решения модератора по отзыву';
@@ -0,0 +1,124 @@
+-- Оценки по отдельным критериям и сводка оценок ресурса. Сводка обновляется
+-- триггером при каждом изменении отзыва, поэтому списки ресурсов не считают
+-- AVG по отзывам для каждой строки.
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS cleanliness_rating SMALLINT CHECK (cleanliness_rating BETWEEN 1 AND 5);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS value_rating SMALLINT CHECK (value_rating BETWEEN 1 AND 5);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS location_rating SMALLINT CHECK (location_rating BETWEEN 1 AND 5);
+
+COMMENT ON COLUMN reviews.cleanliness_rating IS 'Необязательная оценка чистоты';
+COMMENT ON COLUMN reviews.value_rating IS 'Необязательная оценка соотношения цены и качества';
+COMMENT ON COLUMN reviews.location_rating IS 'Необязательная оценка расположения';
+
+CREATE TABLE IF NOT EXISTS resource_rating_stats (
+    resource_id INT PRIMARY KEY REFERENCES resources(id) ON DELETE CASCADE,
+    review_count INT NOT NULL DEFAULT 0,
+    rating_sum INT NOT NULL DEFAULT 0,
+    rating_1 INT NOT NULL DEFAULT 0,
+    rating_2 INT NOT NULL DEFAULT 0,
+    rating_3 INT NOT NULL DEFAULT 0,
+    rating_4 INT NOT NULL DEFAULT 0,
+    rating_5 INT NOT NULL DEFAULT 0,
+    verified_count INT NOT NULL DEFAULT 0,
+    verified_sum INT NOT NULL DEFAULT 0,
+    cleanliness_count INT NOT NULL DEFAULT 0,
+    cleanliness_sum INT NOT NULL DEFAULT 0,
+    value_count INT NOT NULL DEFAULT 0,
+    value_sum INT NOT NULL DEFAULT 0,
+    location_count INT NOT NULL DEFAULT 0,
+    location_sum INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE resource_rating_stats IS 'Сводка опубликованных отзывов ресурса, поддерживается триггером review_rating_stats';
+
+-- Добавляет (delta = 1) или вычитает (delta = -1) вклад отзыва в сводку ресурса.
+-- Учитываются только опубликованные отзывы. При вычитании строка не создается:
+-- ее может не быть, если ресурс удаляется каскадом вместе с отзывами.
+CREATE OR REPLACE FUNCTION apply_review_rating(r reviews, delta INT)
+RETURNS void AS $$
+BEGIN
+    IF r.status <> 'published' THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO resource_rating_stats AS s (
+        resource_id, review_count, rating_sum,
+        rating_1, rating_2, rating_3, rating_4, rating_5,
+        verified_count, verified_sum,
+        cleanliness_count, cleanliness_sum, value_count, value_sum, location_count, location_sum
+    )
+    SELECT
+        r.resource_id, delta, delta * r.rating,
+        CASE WHEN r.rating = 1 THEN delta ELSE 0 END,
+        CASE WHEN r.rating = 2 THEN delta ELSE 0 END,
+        CASE WHEN r.rating = 3 THEN delta ELSE 0 END,
+        CASE WHEN r.rating = 4 THEN delta ELSE 0 END,
+        CASE WHEN r.rating = 5 THEN delta ELSE 0 END,
+        CASE WHEN COALESCE(r.is_verified, false) THEN delta ELSE 0 END,
+        CASE WHEN COALESCE(r.is_verified, false) THEN delta * r.rating ELSE 0 END,
+        CASE WHEN r.cleanliness_rating IS NOT NULL THEN delta ELSE 0 END, delta * COALESCE(r.cleanliness_rating, 0),
+        CASE WHEN r.value_rating IS NOT NULL THEN delta ELSE 0 END, delta * COALESCE(r.value_rating, 0),
+        CASE WHEN r.location_rating IS NOT NULL THEN delta ELSE 0 END, delta * COALESCE(r.location_rating, 0)
+    WHERE delta > 0 OR EXISTS (SELECT 1 FROM resource_rating_stats WHERE resource_id = r.resource_id)
+    ON CONFLICT (resource_id) DO UPDATE SET
+        review_count = s.review_count + EXCLUDED.review_count,
+        rating_sum = s.rating_sum + EXCLUDED.rating_sum,
+        rating_1 = s.rating_1 + EXCLUDED.rating_1,
+        rating_2 = s.rating_2 + EXCLUDED.rating_2,
+        rating_3 = s.rating_3 + EXCLUDED.rating_3,
+        rating_4 = s.rating_4 + EXCLUDED.rating_4,
+        rating_5 = s.rating_5 + EXCLUDED.rating_5,
+        verified_count = s.verified_count + EXCLUDED.verified_count,
+        verified_sum = s.verified_sum + EXCLUDED.verified_sum,
+        cleanliness_count = s.cleanliness_count + EXCLUDED.cleanliness_count,
+        cleanliness_sum = s.cleanliness_sum + EXCLUDED.cleanliness_sum,
+        value_count = s.value_count + EXCLUDED.value_count,
+        value_sum = s.value_sum + EXCLUDED.value_sum,
+        location_count = s.location_count + EXCLUDED.location_count,
+        location_sum = s.location_sum + EXCLUDED.location_sum,
+        updated_at = CURRENT_TIMESTAMP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION trigger_review_rating_stats()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM apply_review_rating(OLD, -1);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM apply_review_rating(NEW, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER review_rating_stats
+    AFTER INSERT OR UPDATE OR DELETE ON reviews
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_review_rating_stats();
+
+-- Сводка по уже существующим отзывам
+INSERT INTO resource_rating_stats (
+    resource_id, review_count, rating_sum,
+    rating_1, rating_2, rating_3, rating_4, rating_5,
+    verified_count, verified_sum,
+    cleanliness_count, cleanliness_sum, value_count, value_sum, location_count, location_sum
+)
+SELECT
+    resource_id, COUNT(*), SUM(rating),
+    COUNT(*) FILTER (WHERE rating = 1),
+    COUNT(*) FILTER (WHERE rating = 2),
+    COUNT(*) FILTER (WHERE rating = 3),
+    COUNT(*) FILTER (WHERE rating = 4),
+    COUNT(*) FILTER (WHERE rating = 5),
+    COUNT(*) FILTER (WHERE is_verified),
+    COALESCE(SUM(rating) FILTER (WHERE is_verified), 0),
+    COUNT(cleanliness_rating), COALESCE(SUM(cleanliness_rating), 0),
+    COUNT(value_rating), COALESCE(SUM(value_rating), 0),
+    COUNT(location_rating), COALESCE(SUM(location_rating), 0)
+FROM reviews
+WHERE status = 'published'
+GROUP BY resource_id
+ON CONFLICT (resource_id) DO NOTHING;
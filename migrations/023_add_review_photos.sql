@@ -0,0 +1,18 @@
+-- Фотографии, приложенные к отзывам. Файлы лежат в том же хранилище,
+-- что и фотографии ресурсов.
+
+CREATE TABLE IF NOT EXISTS review_photos (
+    id BIGSERIAL PRIMARY KEY,
+    review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL DEFAULT 0,
+    mime_type VARCHAR(100) NOT NULL,
+    display_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_photos_review ON review_photos(review_id, display_order);
+
+COMMENT ON TABLE review_photos IS 'Фотографии отзывов; файл удаляется из хранилища вместе с отзывом';
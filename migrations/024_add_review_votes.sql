@@ -0,0 +1,51 @@
+-- Отметки "полезно" на отзывах (одна от пользователя) и индексы для
+-- постраничной выдачи отзывов с сортировкой.
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS helpful_count INT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN reviews.helpful_count IS 'Число отметок "полезно", поддерживается триггером на review_votes';
+
+CREATE TABLE IF NOT EXISTS review_votes (
+    review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (review_id, user_id)
+);
+
+COMMENT ON TABLE review_votes IS 'Отметки "полезно" на отзывах';
+
+CREATE OR REPLACE FUNCTION trigger_review_helpful_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE reviews SET helpful_count = helpful_count + 1 WHERE id = NEW.review_id;
+    ELSE
+        UPDATE reviews SET helpful_count = helpful_count - 1 WHERE id = OLD.review_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER review_helpful_count
+    AFTER INSERT OR DELETE ON review_votes
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_review_helpful_count();
+
+-- Отметка не изменяет отзыв: updated_at и сводка оценок не трогаются
+DROP TRIGGER IF EXISTS set_timestamp_reviews ON reviews;
+CREATE TRIGGER set_timestamp_reviews
+    BEFORE UPDATE ON reviews
+    FOR EACH ROW
+    WHEN (OLD.helpful_count = NEW.helpful_count)
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+DROP TRIGGER IF EXISTS review_rating_stats ON reviews;
+CREATE TRIGGER review_rating_stats
+    AFTER INSERT OR DELETE OR UPDATE OF resource_id, rating, cleanliness_rating, value_rating,
+        location_rating, is_verified, status ON reviews
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_review_rating_stats();
+
+CREATE INDEX IF NOT EXISTS idx_reviews_resource_newest ON reviews(resource_id, created_at DESC, id DESC) WHERE status = 'published';
+CREATE INDEX IF NOT EXISTS idx_reviews_resource_rating ON reviews(resource_id, rating, created_at DESC, id DESC) WHERE status = 'published';
+CREATE INDEX IF NOT EXISTS idx_reviews_resource_helpful ON reviews(resource_id, helpful_count DESC, created_at DESC, id DESC) WHERE status = 'published';
@@ -0,0 +1,11 @@
+-- Уменьшенные копии фотографий ресурсов. Копии лежат в том же хранилище,
+-- что и оригинал; у фотографий, загруженных до обработки изображений,
+-- их нет.
+
+ALTER TABLE resource_photos ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
+ALTER TABLE resource_photos ADD COLUMN IF NOT EXISTS thumbnail_key VARCHAR(500);
+ALTER TABLE resource_photos ADD COLUMN IF NOT EXISTS medium_url TEXT;
+ALTER TABLE resource_photos ADD COLUMN IF NOT EXISTS medium_key VARCHAR(500);
+
+COMMENT ON COLUMN resource_photos.thumbnail_url IS 'Миниатюра до 320px по длинной стороне';
+COMMENT ON COLUMN resource_photos.medium_url IS 'Копия до 1280px по длинной стороне';
@@ -0,0 +1,20 @@
+-- Управление галереей: подписи к фотографиям и явный порядок. До этого
+-- display_order у всех фото был 0, поэтому порядок нумеруется заново так,
+-- как галерея показывалась раньше: главное фото первым, затем по времени загрузки.
+
+ALTER TABLE resource_photos ADD COLUMN IF NOT EXISTS caption VARCHAR(500);
+ALTER TABLE resource_photos ADD COLUMN IF NOT EXISTS alt_text VARCHAR(500);
+
+COMMENT ON COLUMN resource_photos.caption IS 'Подпись под фотографией';
+COMMENT ON COLUMN resource_photos.alt_text IS 'Альтернативный текст для скринридеров';
+
+UPDATE resource_photos p
+SET display_order = o.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY resource_id
+        ORDER BY is_primary DESC, display_order, created_at, id
+    ) - 1 AS position
+    FROM resource_photos
+) o
+WHERE p.id = o.id AND p.display_order IS DISTINCT FROM o.position;
@@ -0,0 +1,21 @@
+-- Расчет цены бронирования по тарифам ресурса. Сохраняется при создании
+-- бронирования, чтобы счет строился из того же расчета, даже если тарифы
+-- потом изменятся.
+
+CREATE TABLE IF NOT EXISTS booking_price_items (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    pricing_id BIGINT REFERENCES resource_pricing(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    unit_minutes INT NOT NULL CHECK (unit_minutes > 0),
+    quantity DECIMAL(10, 2) NOT NULL,
+    unit_price DECIMAL(10, 2) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+
+    UNIQUE(booking_id, position)
+);
+
+COMMENT ON TABLE booking_price_items IS 'Позиции расчета цены бронирования: тариф, количество единиц, цена';
+COMMENT ON COLUMN booking_price_items.pricing_id IS 'Тариф из resource_pricing; NULL - базовая цена за час';
+COMMENT ON COLUMN booking_price_items.unit_minutes IS 'Длительность единицы тарифа в минутах';
@@ -0,0 +1,16 @@
+-- Номера счетов берутся из строки-счетчика, которая блокируется в транзакции
+-- выставления. Значение последовательности расходовалось и при конфликте по
+-- booking_id, и при откате, поэтому в нумерации оставались пропуски.
+-- Счетчик продолжает нумерацию с последнего выданного значения.
+
+CREATE TABLE IF NOT EXISTS invoice_counters (
+    name VARCHAR(50) PRIMARY KEY,
+    last_number BIGINT NOT NULL DEFAULT 0 CHECK (last_number >= 0)
+);
+
+INSERT INTO invoice_counters (name, last_number)
+SELECT 'invoice', CASE WHEN is_called THEN last_value ELSE 0 END
+FROM invoice_number_seq
+ON CONFLICT (name) DO NOTHING;
+
+COMMENT ON TABLE invoice_counters IS 'Счетчики сквозной нумерации счетов без пропусков';
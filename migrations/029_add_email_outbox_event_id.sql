@@ -0,0 +1,11 @@
+-- Подписчик outbox получает событие at-least-once: при повторной доставке то же
+-- письмо ставилось в очередь второй раз. Письмо о событии теперь хранит его
+-- номер, и повтор с тем же шаблоном и адресатом игнорируется. У писем не по
+-- событиям (сброс пароля) event_id пустой и ограничение их не затрагивает.
+
+ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS event_id BIGINT;
+
+ALTER TABLE email_outbox ADD CONSTRAINT email_outbox_event_unique
+    UNIQUE (event_id, template, to_address);
+
+COMMENT ON COLUMN email_outbox.event_id IS 'Номер события outbox, по которому поставлено письмо';